@@ -13,6 +13,7 @@ type ManyToOneEnvelopeV2 struct {
 	buffer     []unsafe.Pointer
 	writeIndex uint64
 	readIndex  uint64
+	dropped    uint64
 	alerter    Alerter
 }
 
@@ -76,6 +77,31 @@ func (d *ManyToOneEnvelopeV2) Next() *v2.Envelope {
 	return result
 }
 
+// Len returns the number of items waiting to be read. It never exceeds Cap.
+func (d *ManyToOneEnvelopeV2) Len() int {
+	written := atomic.LoadUint64(&d.writeIndex) + 1
+	read := atomic.LoadUint64(&d.readIndex)
+	if written <= read {
+		return 0
+	}
+
+	if n := written - read; n < uint64(len(d.buffer)) {
+		return int(n)
+	}
+	return len(d.buffer)
+}
+
+// Cap returns the size of the diode.
+func (d *ManyToOneEnvelopeV2) Cap() int {
+	return len(d.buffer)
+}
+
+// Dropped returns the total number of items that were overwritten before
+// they could be read.
+func (d *ManyToOneEnvelopeV2) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
 func (d *ManyToOneEnvelopeV2) tryNext(idx uint64) (*v2.Envelope, bool) {
 	result := (*bucketEnvelopeV2)(atomic.SwapPointer(&d.buffer[idx], nil))
 
@@ -84,6 +110,7 @@ func (d *ManyToOneEnvelopeV2) tryNext(idx uint64) (*v2.Envelope, bool) {
 	}
 
 	if result.seq > d.readIndex {
+		atomic.AddUint64(&d.dropped, result.seq-d.readIndex)
 		d.alerter.Alert(int(result.seq - d.readIndex))
 		atomic.StoreUint64(&d.readIndex, result.seq)
 	}
@@ -20,6 +20,7 @@ type OneToOne struct {
 	buffer     []unsafe.Pointer
 	writeIndex uint64
 	readIndex  uint64
+	dropped    uint64
 	alerter    Alerter
 }
 
@@ -64,6 +65,31 @@ func (d *OneToOne) Next() []byte {
 	return result
 }
 
+// Len returns the number of items waiting to be read. It never exceeds Cap.
+func (d *OneToOne) Len() int {
+	written := atomic.LoadUint64(&d.writeIndex) + 1
+	read := atomic.LoadUint64(&d.readIndex)
+	if written <= read {
+		return 0
+	}
+
+	if n := written - read; n < uint64(len(d.buffer)) {
+		return int(n)
+	}
+	return len(d.buffer)
+}
+
+// Cap returns the size of the diode.
+func (d *OneToOne) Cap() int {
+	return len(d.buffer)
+}
+
+// Dropped returns the total number of items that were overwritten before
+// they could be read.
+func (d *OneToOne) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
 func (d *OneToOne) tryNext(idx uint64) ([]byte, bool) {
 	result := (*bucket)(atomic.SwapPointer(&d.buffer[idx], nil))
 
@@ -72,6 +98,7 @@ func (d *OneToOne) tryNext(idx uint64) ([]byte, bool) {
 	}
 
 	if result.seq > d.readIndex {
+		atomic.AddUint64(&d.dropped, result.seq-d.readIndex)
 		d.alerter.Alert(int(result.seq - d.readIndex))
 		atomic.StoreUint64(&d.readIndex, result.seq)
 	}
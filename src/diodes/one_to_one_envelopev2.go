@@ -0,0 +1,86 @@
+package diodes
+
+import (
+	v2 "plumbing/v2"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// OneToOneEnvelopeV2 diode is optimized for a single writer and a single
+// reader of v2 envelopes.
+type OneToOneEnvelopeV2 struct {
+	buffer     []unsafe.Pointer
+	writeIndex uint64
+	readIndex  uint64
+	alerter    Alerter
+}
+
+var NewOneToOneEnvelopeV2 = func(size int, alerter Alerter) *OneToOneEnvelopeV2 {
+	d := &OneToOneEnvelopeV2{
+		buffer:  make([]unsafe.Pointer, size),
+		alerter: alerter,
+	}
+	d.writeIndex = ^d.writeIndex
+	return d
+}
+
+func (d *OneToOneEnvelopeV2) Set(data *v2.Envelope) {
+	writeIndex := atomic.AddUint64(&d.writeIndex, 1)
+	idx := writeIndex % uint64(len(d.buffer))
+	newBucket := &bucketEnvelopeV2{
+		data: data,
+		seq:  writeIndex,
+	}
+
+	atomic.StorePointer(&d.buffer[idx], unsafe.Pointer(newBucket))
+}
+
+func (d *OneToOneEnvelopeV2) TryNext() (*v2.Envelope, bool) {
+	readIndex := atomic.LoadUint64(&d.readIndex)
+	idx := readIndex % uint64(len(d.buffer))
+
+	value, ok := d.tryNext(idx)
+	if ok {
+		atomic.AddUint64(&d.readIndex, 1)
+	}
+	return value, ok
+}
+
+func (d *OneToOneEnvelopeV2) Next() *v2.Envelope {
+	readIndex := atomic.LoadUint64(&d.readIndex)
+	idx := readIndex % uint64(len(d.buffer))
+
+	result := d.pollBuffer(idx)
+	atomic.AddUint64(&d.readIndex, 1)
+
+	return result
+}
+
+func (d *OneToOneEnvelopeV2) tryNext(idx uint64) (*v2.Envelope, bool) {
+	result := (*bucketEnvelopeV2)(atomic.SwapPointer(&d.buffer[idx], nil))
+
+	if result == nil {
+		return nil, false
+	}
+
+	if result.seq > d.readIndex {
+		d.alerter.Alert(int(result.seq - d.readIndex))
+		atomic.StoreUint64(&d.readIndex, result.seq)
+	}
+
+	return result.data, true
+}
+
+func (d *OneToOneEnvelopeV2) pollBuffer(idx uint64) *v2.Envelope {
+	for {
+		result, ok := d.tryNext(idx)
+
+		if !ok {
+			time.Sleep(time.Millisecond * 10)
+			continue
+		}
+
+		return result
+	}
+}
@@ -0,0 +1,55 @@
+package diodes_test
+
+import (
+	"diodes"
+	v2 "plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OneToOneEnvelopeV2", func() {
+	var (
+		d          *diodes.OneToOneEnvelopeV2
+		data       *v2.Envelope
+		secondData *v2.Envelope
+
+		mockAlerter *mockAlerter
+	)
+
+	BeforeEach(func() {
+		mockAlerter = newMockAlerter()
+		d = diodes.NewOneToOneEnvelopeV2(5, mockAlerter)
+
+		data = &v2.Envelope{SourceId: "some-id"}
+		secondData = &v2.Envelope{SourceId: "some-other-id"}
+		d.Set(data)
+		d.Set(secondData)
+	})
+
+	It("returns envelopes in order", func() {
+		Expect(d.Next()).To(Equal(data))
+		Expect(d.Next()).To(Equal(secondData))
+	})
+
+	It("returns false from TryNext() when reads exceed writes", func() {
+		d.TryNext()
+		d.TryNext()
+
+		_, ok := d.TryNext()
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("buffer size exceeded", func() {
+		BeforeEach(func() {
+			for i := 0; i < 4; i++ {
+				d.Set(secondData)
+			}
+		})
+
+		It("alerts for each dropped envelope", func() {
+			Expect(d.Next()).To(Equal(secondData))
+			Expect(mockAlerter.AlertInput.Missed).To(Receive(Equal(5)))
+		})
+	})
+})
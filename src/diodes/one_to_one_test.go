@@ -47,6 +47,13 @@ var _ = Describe("OneToOne", func() {
 				Expect(d.Next()).To(Equal(secondData))
 			})
 
+			It("reports the number of unread data slices", func() {
+				Expect(d.Len()).To(Equal(2))
+				d.Next()
+				Expect(d.Len()).To(Equal(1))
+				Expect(d.Dropped()).To(BeZero())
+			})
+
 			Describe("TryNext()", func() {
 				It("returns true", func() {
 					_, ok := d.TryNext()
@@ -110,6 +117,16 @@ var _ = Describe("OneToOne", func() {
 					Expect(d.Next()).To(Equal(secondData))
 				})
 
+				It("reports a full buffer", func() {
+					Expect(d.Len()).To(Equal(5))
+					Expect(d.Cap()).To(Equal(5))
+				})
+
+				It("counts dropped points", func() {
+					d.Next()
+					Expect(d.Dropped()).To(Equal(uint64(5)))
+				})
+
 				It("alerts for each dropped point", func() {
 					d.Next()
 					Expect(mockAlerter.AlertInput.Missed).To(Receive(Equal(5)))
@@ -23,6 +23,17 @@ type EtcdTLSClientConfig struct {
 	CAFile   string
 }
 
+// SinkTLSClientConfig holds the client certificate and key Doppler presents
+// to TLS syslog and HTTPS drains and a CA trusted along with the system roots
+// to verify them. Drain bindings may override any of them. The client
+// certificate is only presented to verified drains on the DrainHosts.
+type SinkTLSClientConfig struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	DrainHosts []string
+}
+
 type TLSListenerConfig struct {
 	Port     uint32
 	CertFile string
@@ -65,6 +76,7 @@ type Config struct {
 	SinkIOTimeoutSeconds            int
 	SinkInactivityTimeoutSeconds    int
 	SinkSkipCertVerify              bool
+	SinkTLSClientConfig             SinkTLSClientConfig
 	Syslog                          string
 	UnmarshallerCount               int
 	WebsocketWriteTimeoutSeconds    int
@@ -96,6 +108,10 @@ func (c *Config) validate() (err error) {
 		}
 	}
 
+	if (c.SinkTLSClientConfig.CertFile == "") != (c.SinkTLSClientConfig.KeyFile == "") {
+		return errors.New("invalid sink TLS client configuration, both CertFile and KeyFile are required")
+	}
+
 	if len(c.GRPC.CAFile) == 0 {
 		return errors.New("invalid doppler config, no GRPC.CAFile provided")
 	}
@@ -114,6 +114,26 @@ func (group *GroupedSinks) BroadcastError(appId string, errorMsg *events.Envelop
 	group.BroadcastMessageToFirehoses(errorMsg)
 }
 
+// BroadcastMetricToDrains sends the metric only to the syslog drains of the
+// app that receive metrics. It is not sent to firehoses or any other sinks.
+func (group *GroupedSinks) BroadcastMetricToDrains(appId string, msg *events.Envelope) {
+	group.RLock()
+	defer group.RUnlock()
+
+	for _, wrapper := range group.apps[appId] {
+		drain, ok := wrapper.Sink.(*syslog.SyslogSink)
+		if !ok || !drain.ShouldReceiveMetrics() {
+			continue
+		}
+
+		select {
+		case wrapper.InputChan <- msg:
+		default:
+			log.Printf("unable to write to app sink: %s", appId)
+		}
+	}
+}
+
 func (group *GroupedSinks) BroadcastMessageToFirehoses(msg *events.Envelope) {
 	for _, fgroup := range group.firehoses {
 		fgroup.BroadcastMessage(msg)
@@ -212,7 +232,7 @@ func (group *GroupedSinks) CloseAndDelete(sink sinks.Sink) bool {
 
 	appId := sink.AppID()
 	wrapper, ok := group.apps[appId][sink.Identifier()]
-	if ok {
+	if ok && wrapper.Sink == sink {
 		close(wrapper.InputChan)
 		delete(group.apps[appId], sink.Identifier())
 		return true
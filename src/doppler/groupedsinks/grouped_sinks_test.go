@@ -6,6 +6,7 @@ import (
 	"doppler/sinks/containermetric"
 	"doppler/sinks/dump"
 	"doppler/sinks/syslog"
+	"doppler/sinks/syslogwriter"
 	"doppler/sinks/websocket"
 	"net/url"
 	"time"
@@ -141,6 +142,36 @@ var _ = Describe("GroupedSink", func() {
 		})
 	})
 
+	Describe("BroadcastMetricToDrains", func() {
+		It("sends the metric only to the drains of the app that receive metrics", func() {
+			metricsDrain := syslog.NewSyslogSink("123", &url.URL{Host: "metrics", RawQuery: "drain-type=metrics"}, 100, DummySyslogWriter{}, dummyErrorHandler, "dropsonde-origin")
+			allDrain := syslog.NewSyslogSink("123", &url.URL{Host: "all", RawQuery: "drain-type=all"}, 100, DummySyslogWriter{}, dummyErrorHandler, "dropsonde-origin")
+			logsDrain := syslog.NewSyslogSink("123", &url.URL{Host: "logs"}, 100, DummySyslogWriter{}, dummyErrorHandler, "dropsonde-origin")
+			otherSink := &fakeSink{sinkId: "sink1", appId: "123"}
+			firehoseSink := &fakeSink{sinkId: "firehose", appId: "firehose-a"}
+
+			metricsChan := make(chan *events.Envelope, 1)
+			allChan := make(chan *events.Envelope, 1)
+			logsChan := make(chan *events.Envelope, 1)
+			otherChan := make(chan *events.Envelope, 1)
+			firehoseChan := make(chan *events.Envelope, 1)
+			groupedSinks.RegisterAppSink(metricsChan, metricsDrain)
+			groupedSinks.RegisterAppSink(allChan, allDrain)
+			groupedSinks.RegisterAppSink(logsChan, logsDrain)
+			groupedSinks.RegisterAppSink(otherChan, otherSink)
+			groupedSinks.RegisterFirehoseSink(firehoseChan, firehoseSink)
+
+			msg, _ := emitter.Wrap(factories.NewValueMetric("some-metric", 1, "ms"), "origin")
+			groupedSinks.BroadcastMetricToDrains("123", msg)
+
+			Expect(metricsChan).To(Receive(Equal(msg)))
+			Expect(allChan).To(Receive(Equal(msg)))
+			Expect(logsChan).ToNot(Receive())
+			Expect(otherChan).ToNot(Receive())
+			Expect(firehoseChan).ToNot(Receive())
+		})
+	})
+
 	Describe("BroadcastError", func() {
 		It("sends message to all registered sinks that match the appId", func(done Done) {
 			appId := "123"
@@ -286,6 +317,21 @@ var _ = Describe("GroupedSink", func() {
 			Expect(groupedSinks.CountFor(target)).To(Equal(1))
 		})
 
+		It("does not delete a sink that replaced it", func() {
+			target := "789"
+
+			oldSink := syslog.NewSyslogSink(target, &url.URL{Host: "url1"}, 100, DummySyslogWriter{}, dummyErrorHandler, "dropsonde-origin")
+			newSink := syslog.NewSyslogSink(target, &url.URL{Host: "url1"}, 100, DummySyslogWriter{}, dummyErrorHandler, "dropsonde-origin")
+
+			groupedSinks.RegisterAppSink(inputChan, oldSink)
+			groupedSinks.CloseAndDelete(oldSink)
+			groupedSinks.RegisterAppSink(make(chan *events.Envelope), newSink)
+
+			ok := groupedSinks.CloseAndDelete(oldSink)
+			Expect(ok).To(BeFalse())
+			Expect(groupedSinks.DrainFor(target, newSink.Identifier())).To(Equal(newSink))
+		})
+
 		It("closes the inputChan", func() {
 			target := "789"
 			sink := syslog.NewSyslogSink(target, &url.URL{Host: "url1"}, 100, DummySyslogWriter{}, dummyErrorHandler, "dropsonde-origin")
@@ -530,7 +576,7 @@ func dummyErrorHandler(_, _ string) {}
 type DummySyslogWriter struct{}
 
 func (d DummySyslogWriter) Connect() error { return nil }
-func (d DummySyslogWriter) Write(p int, b []byte, source, sourceId string, timestamp int64) (int, error) {
+func (d DummySyslogWriter) Write(p int, b []byte, source, sourceId string, timestamp int64, data ...syslogwriter.SDElement) (int, error) {
 	return 0, nil
 }
 func (d DummySyslogWriter) Close() error { return nil }
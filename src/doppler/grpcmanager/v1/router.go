@@ -9,13 +9,21 @@ import (
 )
 
 type Router struct {
-	lock          sync.RWMutex
-	subscriptions map[plumbing.Filter]map[string][]DataSetter
+	lock sync.RWMutex
+	// subscriptions are keyed by app ID and then by the filter's string
+	// representation so that requests with the same selectors share a
+	// subscription.
+	subscriptions map[string]map[string]*subscription
+}
+
+type subscription struct {
+	selectors []*plumbing.Selector
+	shards    map[string][]DataSetter
 }
 
 func NewRouter() *Router {
 	return &Router{
-		subscriptions: make(map[plumbing.Filter]map[string][]DataSetter),
+		subscriptions: make(map[string]map[string]*subscription),
 	}
 }
 
@@ -32,23 +40,26 @@ func (r *Router) SendTo(appID string, envelope *events.Envelope) {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	data := r.marshal(envelope)
+	var matched []*subscription
+	if appID != "" {
+		matched = appendMatches(matched, r.subscriptions[appID], envelope)
+	}
+	matched = appendMatches(matched, r.subscriptions[""], envelope)
 
-	if data == nil {
+	if len(matched) == 0 {
 		return
 	}
 
-	filter := plumbing.Filter{
-		AppID: appID,
-	}
+	data := r.marshal(envelope)
 
-	for shardID, setters := range r.subscriptions[filter] {
-		r.writeToShard(shardID, setters, data)
+	if data == nil {
+		return
 	}
 
-	var noFilter plumbing.Filter
-	for shardID, setters := range r.subscriptions[noFilter] {
-		r.writeToShard(shardID, setters, data)
+	for _, s := range matched {
+		for shardID, setters := range s.shards {
+			r.writeToShard(shardID, setters, data)
+		}
 	}
 }
 
@@ -64,18 +75,24 @@ func (r *Router) writeToShard(shardID string, setters []DataSetter, data []byte)
 }
 
 func (r *Router) registerSetter(req *plumbing.SubscriptionRequest, dataSetter DataSetter) {
-	var filter plumbing.Filter
-	if req.Filter != nil {
-		filter = *req.Filter
+	appID, key := filterKeys(req.Filter)
+
+	subs, ok := r.subscriptions[appID]
+	if !ok {
+		subs = make(map[string]*subscription)
+		r.subscriptions[appID] = subs
 	}
 
-	m, ok := r.subscriptions[filter]
+	s, ok := subs[key]
 	if !ok {
-		m = make(map[string][]DataSetter)
-		r.subscriptions[filter] = m
+		s = &subscription{
+			selectors: req.Filter.GetSelectors(),
+			shards:    make(map[string][]DataSetter),
+		}
+		subs[key] = s
 	}
 
-	m[req.ShardID] = append(m[req.ShardID], dataSetter)
+	s.shards[req.ShardID] = append(s.shards[req.ShardID], dataSetter)
 }
 
 func (r *Router) buildCleanup(req *plumbing.SubscriptionRequest, dataSetter DataSetter) func() {
@@ -83,27 +100,34 @@ func (r *Router) buildCleanup(req *plumbing.SubscriptionRequest, dataSetter Data
 		r.lock.Lock()
 		defer r.lock.Unlock()
 
-		var filter plumbing.Filter
-		if req.Filter != nil {
-			filter = *req.Filter
+		appID, key := filterKeys(req.Filter)
+		s, ok := r.subscriptions[appID][key]
+		if !ok {
+			return
 		}
 
 		var setters []DataSetter
-		for _, s := range r.subscriptions[filter][req.ShardID] {
-			if s != dataSetter {
-				setters = append(setters, s)
+		for _, ds := range s.shards[req.ShardID] {
+			if ds != dataSetter {
+				setters = append(setters, ds)
 			}
 		}
 
 		if len(setters) > 0 {
-			r.subscriptions[filter][req.ShardID] = setters
+			s.shards[req.ShardID] = setters
 			return
 		}
 
-		delete(r.subscriptions[filter], req.ShardID)
+		delete(s.shards, req.ShardID)
 
-		if len(r.subscriptions[filter]) == 0 {
-			delete(r.subscriptions, filter)
+		if len(s.shards) > 0 {
+			return
+		}
+
+		delete(r.subscriptions[appID], key)
+
+		if len(r.subscriptions[appID]) == 0 {
+			delete(r.subscriptions, appID)
 		}
 	}
 }
@@ -116,3 +140,20 @@ func (r *Router) marshal(envelope *events.Envelope) []byte {
 
 	return data
 }
+
+func appendMatches(matched []*subscription, subs map[string]*subscription, envelope *events.Envelope) []*subscription {
+	for _, s := range subs {
+		if matchesAny(s.selectors, envelope) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+func filterKeys(filter *plumbing.Filter) (appID, key string) {
+	if filter == nil {
+		return "", ""
+	}
+
+	return filter.AppID, filter.String()
+}
@@ -210,4 +210,114 @@ var _ = Describe("Router", func() {
 			})
 		})
 	})
+
+	Describe("selectors", func() {
+		var (
+			logEnvelope     *events.Envelope
+			counterEnvelope *events.Envelope
+			gaugeEnvelope   *events.Envelope
+		)
+
+		BeforeEach(func() {
+			logEnvelope = &events.Envelope{
+				Origin:    proto.String("some-origin"),
+				EventType: events.Envelope_LogMessage.Enum(),
+				LogMessage: &events.LogMessage{
+					Message:     []byte("some-log"),
+					MessageType: events.LogMessage_OUT.Enum(),
+					Timestamp:   proto.Int64(1),
+					SourceType:  proto.String("APP"),
+				},
+			}
+			counterEnvelope = &events.Envelope{
+				Origin:    proto.String("some-origin"),
+				EventType: events.Envelope_CounterEvent.Enum(),
+				CounterEvent: &events.CounterEvent{
+					Name:  proto.String("some-counter"),
+					Delta: proto.Uint64(1),
+				},
+				Tags: map[string]string{"some-tag": "some-value"},
+			}
+			gaugeEnvelope = &events.Envelope{
+				Origin:    proto.String("some-origin"),
+				EventType: events.Envelope_ValueMetric.Enum(),
+				ValueMetric: &events.ValueMetric{
+					Name:  proto.String("some-gauge"),
+					Value: proto.Float64(1),
+					Unit:  proto.String("some-unit"),
+				},
+			}
+		})
+
+		register := func(selectors ...*plumbing.Selector) *mockDataSetter {
+			setter := newMockDataSetter()
+			router.Register(&plumbing.SubscriptionRequest{
+				Filter: &plumbing.Filter{Selectors: selectors},
+			}, setter)
+			return setter
+		}
+
+		It("sends only envelopes of the selected type", func() {
+			setter := register(&plumbing.Selector{Type: plumbing.Selector_COUNTER})
+
+			router.SendTo("some-app-id", logEnvelope)
+			router.SendTo("some-app-id", gaugeEnvelope)
+			router.SendTo("some-app-id", counterEnvelope)
+
+			Eventually(setter.SetCalled).Should(HaveLen(1))
+			Consistently(setter.SetCalled).Should(HaveLen(1))
+		})
+
+		It("sends only counters and gauges with the selected name", func() {
+			setter := register(
+				&plumbing.Selector{Name: "some-gauge"},
+				&plumbing.Selector{Name: "other-counter"},
+			)
+
+			router.SendTo("some-app-id", logEnvelope)
+			router.SendTo("some-app-id", counterEnvelope)
+			router.SendTo("some-app-id", gaugeEnvelope)
+
+			expected, err := gaugeEnvelope.Marshal()
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(setter.SetInput.Data).Should(Receive(Equal(expected)))
+			Consistently(setter.SetCalled).Should(HaveLen(1))
+		})
+
+		It("sends only envelopes with matching tags", func() {
+			setter := register(&plumbing.Selector{
+				Type: plumbing.Selector_LOG,
+				Tags: map[string]string{"source_type": "APP", "origin": "some-origin"},
+			})
+			otherSetter := register(&plumbing.Selector{
+				Tags: map[string]string{"some-tag": "other-value"},
+			})
+
+			router.SendTo("some-app-id", counterEnvelope)
+			router.SendTo("some-app-id", logEnvelope)
+
+			expected, err := logEnvelope.Marshal()
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(setter.SetInput.Data).Should(Receive(Equal(expected)))
+			Consistently(setter.SetCalled).Should(HaveLen(1))
+			Consistently(otherSetter.SetCalled).Should(HaveLen(0))
+		})
+
+		It("keeps subscriptions with different selectors apart", func() {
+			counterSetter := register(&plumbing.Selector{Type: plumbing.Selector_COUNTER})
+			logSetter := register(&plumbing.Selector{Type: plumbing.Selector_LOG})
+			cleanup := router.Register(&plumbing.SubscriptionRequest{
+				Filter: &plumbing.Filter{
+					Selectors: []*plumbing.Selector{{Type: plumbing.Selector_LOG}},
+				},
+			}, mockDataSetterA)
+			cleanup()
+
+			router.SendTo("some-app-id", logEnvelope)
+
+			Eventually(logSetter.SetCalled).Should(HaveLen(1))
+			Consistently(counterSetter.SetCalled).Should(HaveLen(0))
+			Consistently(mockDataSetterA.SetCalled).Should(HaveLen(0))
+		})
+	})
 })
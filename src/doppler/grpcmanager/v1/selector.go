@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"plumbing"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+// containerMetricNames are the gauge names a container metric has once it is
+// converted to a v2 envelope.
+var containerMetricNames = map[string]bool{
+	"instance_index": true,
+	"cpu":            true,
+	"memory":         true,
+	"disk":           true,
+	"memory_quota":   true,
+	"disk_quota":     true,
+}
+
+// matchesAny reports whether the envelope matches at least one of the
+// selectors. An empty set of selectors matches every envelope.
+func matchesAny(selectors []*plumbing.Selector, e *events.Envelope) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+
+	for _, s := range selectors {
+		if matches(s, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(s *plumbing.Selector, e *events.Envelope) bool {
+	return matchesType(s.Type, e) &&
+		matchesName(s.Name, e) &&
+		matchesTags(s.Tags, e)
+}
+
+func matchesType(t plumbing.Selector_Type, e *events.Envelope) bool {
+	switch t {
+	case plumbing.Selector_ANY:
+		return true
+	case plumbing.Selector_LOG:
+		return e.GetEventType() == events.Envelope_LogMessage ||
+			e.GetEventType() == events.Envelope_Error
+	case plumbing.Selector_COUNTER:
+		return e.GetEventType() == events.Envelope_CounterEvent
+	case plumbing.Selector_GAUGE:
+		return e.GetEventType() == events.Envelope_ValueMetric ||
+			e.GetEventType() == events.Envelope_ContainerMetric
+	case plumbing.Selector_TIMER:
+		return e.GetEventType() == events.Envelope_HttpStartStop
+	default:
+		return false
+	}
+}
+
+func matchesName(name string, e *events.Envelope) bool {
+	if name == "" {
+		return true
+	}
+
+	switch e.GetEventType() {
+	case events.Envelope_CounterEvent:
+		return e.GetCounterEvent().GetName() == name
+	case events.Envelope_ValueMetric:
+		return e.GetValueMetric().GetName() == name
+	case events.Envelope_ContainerMetric:
+		return containerMetricNames[name]
+	default:
+		return false
+	}
+}
+
+func matchesTags(tags map[string]string, e *events.Envelope) bool {
+	for k, v := range tags {
+		actual, ok := tagValue(k, e)
+		if !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// tagValue looks up a tag the same way it would appear on the envelope once
+// converted to v2: well known envelope fields take precedence over the
+// envelope's own tags.
+func tagValue(key string, e *events.Envelope) (string, bool) {
+	switch key {
+	case "origin":
+		return e.GetOrigin(), true
+	case "deployment":
+		return e.GetDeployment(), true
+	case "job":
+		return e.GetJob(), true
+	case "index":
+		return e.GetIndex(), true
+	case "ip":
+		return e.GetIp(), true
+	}
+
+	if e.GetEventType() == events.Envelope_LogMessage {
+		switch key {
+		case "source_type":
+			return e.GetLogMessage().GetSourceType(), true
+		case "source_instance":
+			return e.GetLogMessage().GetSourceInstance(), true
+		}
+	}
+
+	v, ok := e.GetTags()[key]
+	return v, ok
+}
@@ -0,0 +1,89 @@
+package v2
+
+import (
+	"diodes"
+	"log"
+	"metric"
+	plumbing "plumbing/v2"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type Egress_ReceiverServer interface {
+	plumbing.Egress_ReceiverServer
+}
+
+// Registrar registers stream and firehose DataSetters to accept reads.
+type Registrar interface {
+	Register(req *plumbing.SelectiveEgressRequest, setter DataSetter) func()
+}
+
+// Egress is the v2 gRPC server component that streams envelopes to
+// subscribers without converting them to v1.
+type Egress struct {
+	registrar Registrar
+}
+
+func NewEgress(registrar Registrar) *Egress {
+	return &Egress{
+		registrar: registrar,
+	}
+}
+
+// Receiver is called by gRPC on stream requests.
+func (e *Egress) Receiver(req *plumbing.EgressRequest, sender plumbing.Egress_ReceiverServer) error {
+	return e.receive(&plumbing.SelectiveEgressRequest{Request: req}, sender)
+}
+
+// SelectiveReceiver is called by gRPC on stream requests with selectors.
+func (e *Egress) SelectiveReceiver(req *plumbing.SelectiveEgressRequest, sender plumbing.SelectiveEgress_SelectiveReceiverServer) error {
+	return e.receive(req, sender)
+}
+
+// envelopeSender is the part of the Egress and SelectiveEgress streams used
+// to send envelopes.
+type envelopeSender interface {
+	Send(*plumbing.Envelope) error
+	Context() context.Context
+}
+
+func (e *Egress) receive(req *plumbing.SelectiveEgressRequest, sender envelopeSender) error {
+	d := diodes.NewOneToOneEnvelopeV2(1000, diodes.AlertFunc(func(missed int) {
+		log.Printf("Dropped %d envelopes (v2)", missed)
+		metric.IncCounter("dropped",
+			metric.WithIncrement(uint64(missed)),
+			metric.WithVersion(2, 0),
+			metric.WithTag("direction", "egress"),
+		)
+	}))
+	cleanup := e.registrar.Register(req, d)
+	defer cleanup()
+
+	var done int64
+	go monitorContext(sender.Context(), &done)
+
+	for {
+		if atomic.LoadInt64(&done) > 0 {
+			break
+		}
+
+		envelope, ok := d.TryNext()
+		if !ok {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		if err := sender.Send(envelope); err != nil {
+			return err
+		}
+	}
+
+	return sender.Context().Err()
+}
+
+func monitorContext(ctx context.Context, done *int64) {
+	<-ctx.Done()
+	atomic.StoreInt64(done, 1)
+}
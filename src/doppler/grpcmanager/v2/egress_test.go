@@ -0,0 +1,94 @@
+package v2_test
+
+import (
+	"doppler/grpcmanager/v2"
+	"errors"
+	plumbing "plumbing/v2"
+
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Egress", func() {
+	var (
+		mockRegistrar *mockRegistrar
+		mockReceiver  *mockEgress_ReceiverServer
+		cleanupCalled chan bool
+		ctx           context.Context
+		cancel        func()
+
+		egress *v2.Egress
+	)
+
+	BeforeEach(func() {
+		mockRegistrar = newMockRegistrar()
+		mockReceiver = newMockEgress_ReceiverServer()
+
+		cleanupCalled = make(chan bool, 1)
+		mockRegistrar.RegisterOutput.Ret0 <- func() {
+			cleanupCalled <- true
+		}
+
+		ctx, cancel = context.WithCancel(context.Background())
+		for i := 0; i < 10; i++ {
+			mockReceiver.ContextOutput.Ret0 <- ctx
+		}
+
+		egress = v2.NewEgress(mockRegistrar)
+	})
+
+	It("registers the request", func() {
+		req := &plumbing.EgressRequest{ShardId: "some-shard"}
+		cancel()
+
+		egress.Receiver(req, mockReceiver)
+
+		Expect(mockRegistrar.RegisterInput.Req).To(Receive(Equal(
+			&plumbing.SelectiveEgressRequest{Request: req},
+		)))
+	})
+
+	It("registers the request with its selectors", func() {
+		req := &plumbing.SelectiveEgressRequest{
+			Request:   &plumbing.EgressRequest{ShardId: "some-shard"},
+			Selectors: []*plumbing.Selector{{Type: plumbing.Selector_LOG}},
+		}
+		cancel()
+
+		egress.SelectiveReceiver(req, mockReceiver)
+
+		Expect(mockRegistrar.RegisterInput.Req).To(Receive(Equal(req)))
+	})
+
+	It("sends envelopes written to the registered setter", func() {
+		close(mockReceiver.SendOutput.Ret0)
+		go egress.Receiver(&plumbing.EgressRequest{}, mockReceiver)
+
+		var setter v2.DataSetter
+		Eventually(mockRegistrar.RegisterInput.Setter).Should(Receive(&setter))
+
+		e := &plumbing.Envelope{SourceId: "some-id"}
+		setter.Set(e)
+
+		Eventually(mockReceiver.SendInput.Arg0).Should(Receive(Equal(e)))
+		cancel()
+		Eventually(cleanupCalled).Should(Receive())
+	})
+
+	It("returns an error when sending fails", func() {
+		mockReceiver.SendOutput.Ret0 <- errors.New("some-error")
+		errs := make(chan error, 1)
+		go func() {
+			errs <- egress.Receiver(&plumbing.EgressRequest{}, mockReceiver)
+		}()
+
+		var setter v2.DataSetter
+		Eventually(mockRegistrar.RegisterInput.Setter).Should(Receive(&setter))
+		setter.Set(&plumbing.Envelope{SourceId: "some-id"})
+
+		Eventually(errs).Should(Receive(HaveOccurred()))
+		Expect(cleanupCalled).To(Receive())
+	})
+})
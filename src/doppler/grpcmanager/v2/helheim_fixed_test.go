@@ -6,9 +6,9 @@
 package v2_test
 
 import (
-	"golang.org/x/net/context"
+	"doppler/grpcmanager/v2"
 
-	"github.com/cloudfoundry/sonde-go/events"
+	"golang.org/x/net/context"
 
 	plumbing "plumbing/v2"
 
@@ -115,20 +115,234 @@ func (m *mockDopplerIngress_SenderServer) RecvMsg(m_ interface{}) error {
 	return <-m.RecvMsgOutput.Ret0
 }
 
+type mockDopplerIngress_BatchSenderServer struct {
+	SendAndCloseCalled chan bool
+	SendAndCloseInput  struct {
+		Arg0 chan *plumbing.SenderResponse
+	}
+	SendAndCloseOutput struct {
+		Ret0 chan error
+	}
+	RecvCalled chan bool
+	RecvOutput struct {
+		Ret0 chan *plumbing.EnvelopeBatch
+		Ret1 chan error
+	}
+	SendHeaderCalled chan bool
+	SendHeaderInput  struct {
+		Arg0 chan metadata.MD
+	}
+	SendHeaderOutput struct {
+		Ret0 chan error
+	}
+	SetTrailerCalled chan bool
+	SetTrailerInput  struct {
+		Arg0 chan metadata.MD
+	}
+	ContextCalled chan bool
+	ContextOutput struct {
+		Ret0 chan context.Context
+	}
+	SendMsgCalled chan bool
+	SendMsgInput  struct {
+		M chan interface{}
+	}
+	SendMsgOutput struct {
+		Ret0 chan error
+	}
+	RecvMsgCalled chan bool
+	RecvMsgInput  struct {
+		M chan interface{}
+	}
+	RecvMsgOutput struct {
+		Ret0 chan error
+	}
+}
+
+func newMockDopplerIngress_BatchSenderServer() *mockDopplerIngress_BatchSenderServer {
+	m := &mockDopplerIngress_BatchSenderServer{}
+	m.SendAndCloseCalled = make(chan bool, 100)
+	m.SendAndCloseInput.Arg0 = make(chan *plumbing.SenderResponse, 100)
+	m.SendAndCloseOutput.Ret0 = make(chan error, 100)
+	m.RecvCalled = make(chan bool, 100)
+	m.RecvOutput.Ret0 = make(chan *plumbing.EnvelopeBatch, 100)
+	m.RecvOutput.Ret1 = make(chan error, 100)
+	m.SendHeaderCalled = make(chan bool, 100)
+	m.SendHeaderInput.Arg0 = make(chan metadata.MD, 100)
+	m.SendHeaderOutput.Ret0 = make(chan error, 100)
+	m.SetTrailerCalled = make(chan bool, 100)
+	m.SetTrailerInput.Arg0 = make(chan metadata.MD, 100)
+	m.ContextCalled = make(chan bool, 100)
+	m.ContextOutput.Ret0 = make(chan context.Context, 100)
+	m.SendMsgCalled = make(chan bool, 100)
+	m.SendMsgInput.M = make(chan interface{}, 100)
+	m.SendMsgOutput.Ret0 = make(chan error, 100)
+	m.RecvMsgCalled = make(chan bool, 100)
+	m.RecvMsgInput.M = make(chan interface{}, 100)
+	m.RecvMsgOutput.Ret0 = make(chan error, 100)
+	return m
+}
+func (m *mockDopplerIngress_BatchSenderServer) SendAndClose(arg0 *plumbing.SenderResponse) error {
+	m.SendAndCloseCalled <- true
+	m.SendAndCloseInput.Arg0 <- arg0
+	return <-m.SendAndCloseOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderServer) Recv() (*plumbing.EnvelopeBatch, error) {
+	m.RecvCalled <- true
+	return <-m.RecvOutput.Ret0, <-m.RecvOutput.Ret1
+}
+func (m *mockDopplerIngress_BatchSenderServer) SendHeader(arg0 metadata.MD) error {
+	m.SendHeaderCalled <- true
+	m.SendHeaderInput.Arg0 <- arg0
+	return <-m.SendHeaderOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderServer) SetTrailer(arg0 metadata.MD) {
+	m.SetTrailerCalled <- true
+	m.SetTrailerInput.Arg0 <- arg0
+}
+func (m *mockDopplerIngress_BatchSenderServer) Context() context.Context {
+	m.ContextCalled <- true
+	return <-m.ContextOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderServer) SendMsg(m_ interface{}) error {
+	m.SendMsgCalled <- true
+	m.SendMsgInput.M <- m_
+	return <-m.SendMsgOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderServer) RecvMsg(m_ interface{}) error {
+	m.RecvMsgCalled <- true
+	m.RecvMsgInput.M <- m_
+	return <-m.RecvMsgOutput.Ret0
+}
+
 type mockDataSetter struct {
 	SetCalled chan bool
 	SetInput  struct {
-		Data chan *events.Envelope
+		Data chan *plumbing.Envelope
 	}
 }
 
 func newMockDataSetter() *mockDataSetter {
 	m := &mockDataSetter{}
 	m.SetCalled = make(chan bool, 100)
-	m.SetInput.Data = make(chan *events.Envelope, 100)
+	m.SetInput.Data = make(chan *plumbing.Envelope, 100)
 	return m
 }
-func (m *mockDataSetter) Set(data *events.Envelope) {
+func (m *mockDataSetter) Set(data *plumbing.Envelope) {
 	m.SetCalled <- true
 	m.SetInput.Data <- data
 }
+
+type mockRegistrar struct {
+	RegisterCalled chan bool
+	RegisterInput  struct {
+		Req    chan *plumbing.SelectiveEgressRequest
+		Setter chan v2.DataSetter
+	}
+	RegisterOutput struct {
+		Ret0 chan func()
+	}
+}
+
+func newMockRegistrar() *mockRegistrar {
+	m := &mockRegistrar{}
+	m.RegisterCalled = make(chan bool, 100)
+	m.RegisterInput.Req = make(chan *plumbing.SelectiveEgressRequest, 100)
+	m.RegisterInput.Setter = make(chan v2.DataSetter, 100)
+	m.RegisterOutput.Ret0 = make(chan func(), 100)
+	return m
+}
+func (m *mockRegistrar) Register(req *plumbing.SelectiveEgressRequest, setter v2.DataSetter) func() {
+	m.RegisterCalled <- true
+	m.RegisterInput.Req <- req
+	m.RegisterInput.Setter <- setter
+	return <-m.RegisterOutput.Ret0
+}
+
+type mockEgress_ReceiverServer struct {
+	SendCalled chan bool
+	SendInput  struct {
+		Arg0 chan *plumbing.Envelope
+	}
+	SendOutput struct {
+		Ret0 chan error
+	}
+	SendHeaderCalled chan bool
+	SendHeaderInput  struct {
+		Arg0 chan metadata.MD
+	}
+	SendHeaderOutput struct {
+		Ret0 chan error
+	}
+	SetTrailerCalled chan bool
+	SetTrailerInput  struct {
+		Arg0 chan metadata.MD
+	}
+	ContextCalled chan bool
+	ContextOutput struct {
+		Ret0 chan context.Context
+	}
+	SendMsgCalled chan bool
+	SendMsgInput  struct {
+		M chan interface{}
+	}
+	SendMsgOutput struct {
+		Ret0 chan error
+	}
+	RecvMsgCalled chan bool
+	RecvMsgInput  struct {
+		M chan interface{}
+	}
+	RecvMsgOutput struct {
+		Ret0 chan error
+	}
+}
+
+func newMockEgress_ReceiverServer() *mockEgress_ReceiverServer {
+	m := &mockEgress_ReceiverServer{}
+	m.SendCalled = make(chan bool, 100)
+	m.SendInput.Arg0 = make(chan *plumbing.Envelope, 100)
+	m.SendOutput.Ret0 = make(chan error, 100)
+	m.SendHeaderCalled = make(chan bool, 100)
+	m.SendHeaderInput.Arg0 = make(chan metadata.MD, 100)
+	m.SendHeaderOutput.Ret0 = make(chan error, 100)
+	m.SetTrailerCalled = make(chan bool, 100)
+	m.SetTrailerInput.Arg0 = make(chan metadata.MD, 100)
+	m.ContextCalled = make(chan bool, 100)
+	m.ContextOutput.Ret0 = make(chan context.Context, 100)
+	m.SendMsgCalled = make(chan bool, 100)
+	m.SendMsgInput.M = make(chan interface{}, 100)
+	m.SendMsgOutput.Ret0 = make(chan error, 100)
+	m.RecvMsgCalled = make(chan bool, 100)
+	m.RecvMsgInput.M = make(chan interface{}, 100)
+	m.RecvMsgOutput.Ret0 = make(chan error, 100)
+	return m
+}
+func (m *mockEgress_ReceiverServer) Send(arg0 *plumbing.Envelope) error {
+	m.SendCalled <- true
+	m.SendInput.Arg0 <- arg0
+	return <-m.SendOutput.Ret0
+}
+func (m *mockEgress_ReceiverServer) SendHeader(arg0 metadata.MD) error {
+	m.SendHeaderCalled <- true
+	m.SendHeaderInput.Arg0 <- arg0
+	return <-m.SendHeaderOutput.Ret0
+}
+func (m *mockEgress_ReceiverServer) SetTrailer(arg0 metadata.MD) {
+	m.SetTrailerCalled <- true
+	m.SetTrailerInput.Arg0 <- arg0
+}
+func (m *mockEgress_ReceiverServer) Context() context.Context {
+	m.ContextCalled <- true
+	return <-m.ContextOutput.Ret0
+}
+func (m *mockEgress_ReceiverServer) SendMsg(m_ interface{}) error {
+	m.SendMsgCalled <- true
+	m.SendMsgInput.M <- m_
+	return <-m.SendMsgOutput.Ret0
+}
+func (m *mockEgress_ReceiverServer) RecvMsg(m_ interface{}) error {
+	m.RecvMsgCalled <- true
+	m.RecvMsgInput.M <- m_
+	return <-m.RecvMsgOutput.Ret0
+}
@@ -3,19 +3,20 @@ package v2
 import (
 	"log"
 	"metric"
-	"plumbing/conversion"
 	plumbing "plumbing/v2"
 	"time"
-
-	"github.com/cloudfoundry/sonde-go/events"
 )
 
 type DopplerIngress_SenderServer interface {
 	plumbing.DopplerIngress_SenderServer
 }
 
+type DopplerIngress_BatchSenderServer interface {
+	plumbing.DopplerIngress_BatchSenderServer
+}
+
 type DataSetter interface {
-	Set(data *events.Envelope)
+	Set(e *plumbing.Envelope)
 }
 
 type Ingestor struct {
@@ -29,29 +30,54 @@ func NewIngestor(envelopeBuffer DataSetter) *Ingestor {
 }
 
 func (i Ingestor) Sender(s plumbing.DopplerIngress_SenderServer) error {
-	var count uint64
-	lastEmitted := time.Now()
+	c := &ingressCounter{lastEmitted: time.Now()}
 	for {
-		v2e, err := s.Recv()
+		e, err := s.Recv()
 		if err != nil {
 			return err
 		}
 
-		v1e := conversion.ToV1(v2e)
-		if v1e == nil || v1e.EventType == nil {
-			continue
+		i.set(e, c)
+	}
+}
+
+func (i Ingestor) BatchSender(s plumbing.DopplerIngress_BatchSenderServer) error {
+	c := &ingressCounter{lastEmitted: time.Now()}
+	for {
+		batch, err := s.Recv()
+		if err != nil {
+			return err
 		}
 
-		count++
-		if count >= 1000 || time.Since(lastEmitted) > 5*time.Second {
-			metric.IncCounter("ingress",
-				metric.WithIncrement(count),
-				metric.WithVersion(2, 0),
-			)
-			log.Printf("Ingressed (v2) %d envelopes", count)
-			lastEmitted = time.Now()
-			count = 0
+		for _, e := range batch.Batch {
+			i.set(e, c)
 		}
-		i.envelopeBuffer.Set(v1e)
+	}
+}
+
+func (i Ingestor) set(e *plumbing.Envelope, c *ingressCounter) {
+	if e.Message == nil {
+		return
+	}
+
+	c.inc()
+	i.envelopeBuffer.Set(e)
+}
+
+type ingressCounter struct {
+	count       uint64
+	lastEmitted time.Time
+}
+
+func (c *ingressCounter) inc() {
+	c.count++
+	if c.count >= 1000 || time.Since(c.lastEmitted) > 5*time.Second {
+		metric.IncCounter("ingress",
+			metric.WithIncrement(c.count),
+			metric.WithVersion(2, 0),
+		)
+		log.Printf("Ingressed (v2) %d envelopes", c.count)
+		c.lastEmitted = time.Now()
+		c.count = 0
 	}
 }
@@ -11,8 +11,9 @@ import (
 
 var _ = Describe("Ingress", func() {
 	var (
-		mockDataSetter *mockDataSetter
-		mockSender     *mockDopplerIngress_SenderServer
+		mockDataSetter  *mockDataSetter
+		mockSender      *mockDopplerIngress_SenderServer
+		mockBatchSender *mockDopplerIngress_BatchSenderServer
 
 		ingestor *v2.Ingestor
 	)
@@ -20,24 +21,26 @@ var _ = Describe("Ingress", func() {
 	BeforeEach(func() {
 		mockDataSetter = newMockDataSetter()
 		mockSender = newMockDopplerIngress_SenderServer()
+		mockBatchSender = newMockDopplerIngress_BatchSenderServer()
 
 		ingestor = v2.NewIngestor(mockDataSetter)
 	})
 
-	It("writes the v2 envelope as a v1 envelope to data setter", func() {
-		mockSender.RecvOutput.Ret0 <- &plumbing.Envelope{
+	It("writes the v2 envelope to data setter", func() {
+		e := &plumbing.Envelope{
 			Message: &plumbing.Envelope_Log{
 				Log: &plumbing.Log{
 					Payload: []byte("hello"),
 				},
 			},
 		}
+		mockSender.RecvOutput.Ret0 <- e
 		mockSender.RecvOutput.Ret1 <- nil
 		mockSender.RecvOutput.Ret0 <- nil
 		mockSender.RecvOutput.Ret1 <- io.EOF
 
 		ingestor.Sender(mockSender)
-		Expect(mockDataSetter.SetCalled).To(HaveLen(1))
+		Expect(mockDataSetter.SetInput.Data).To(Receive(Equal(e)))
 	})
 
 	It("throws invalid envelopes on the ground", func() {
@@ -49,4 +52,34 @@ var _ = Describe("Ingress", func() {
 		ingestor.Sender(mockSender)
 		Expect(mockDataSetter.SetCalled).To(HaveLen(0))
 	})
+
+	Describe("BatchSender", func() {
+		It("writes each valid v2 envelope in the batch to data setter", func() {
+			mockBatchSender.RecvOutput.Ret0 <- &plumbing.EnvelopeBatch{
+				Batch: []*plumbing.Envelope{
+					{
+						Message: &plumbing.Envelope_Log{
+							Log: &plumbing.Log{
+								Payload: []byte("hello"),
+							},
+						},
+					},
+					{},
+					{
+						Message: &plumbing.Envelope_Log{
+							Log: &plumbing.Log{
+								Payload: []byte("world"),
+							},
+						},
+					},
+				},
+			}
+			mockBatchSender.RecvOutput.Ret1 <- nil
+			mockBatchSender.RecvOutput.Ret0 <- nil
+			mockBatchSender.RecvOutput.Ret1 <- io.EOF
+
+			ingestor.BatchSender(mockBatchSender)
+			Expect(mockDataSetter.SetCalled).To(HaveLen(2))
+		})
+	})
 })
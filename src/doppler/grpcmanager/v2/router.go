@@ -0,0 +1,142 @@
+package v2
+
+import (
+	"math/rand"
+	plumbing "plumbing/v2"
+	"sync"
+)
+
+// Router routes v2 envelopes to the DataSetters registered for their source
+// ID and to every firehose subscription whose selectors match.
+type Router struct {
+	lock sync.RWMutex
+	// subscriptions are keyed by source ID and then by the string
+	// representation of the selectors so that requests with the same
+	// selectors share a subscription.
+	subscriptions map[string]map[string]*subscription
+}
+
+type subscription struct {
+	selectors []*plumbing.Selector
+	shards    map[string][]DataSetter
+}
+
+func NewRouter() *Router {
+	return &Router{
+		subscriptions: make(map[string]map[string]*subscription),
+	}
+}
+
+func (r *Router) Register(req *plumbing.SelectiveEgressRequest, dataSetter DataSetter) (cleanup func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	sourceID, shardID, key := subscriptionKeys(req)
+	subs, ok := r.subscriptions[sourceID]
+	if !ok {
+		subs = make(map[string]*subscription)
+		r.subscriptions[sourceID] = subs
+	}
+
+	s, ok := subs[key]
+	if !ok {
+		s = &subscription{
+			selectors: req.GetSelectors(),
+			shards:    make(map[string][]DataSetter),
+		}
+		subs[key] = s
+	}
+	s.shards[shardID] = append(s.shards[shardID], dataSetter)
+
+	return r.buildCleanup(sourceID, key, shardID, dataSetter)
+}
+
+func (r *Router) SendTo(sourceID string, envelope *plumbing.Envelope) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if sourceID != "" {
+		r.sendToMatches(r.subscriptions[sourceID], envelope)
+	}
+
+	r.sendToMatches(r.subscriptions[""], envelope)
+}
+
+// HasSubscriptions reports whether any stream is registered with the router.
+func (r *Router) HasSubscriptions() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return len(r.subscriptions) > 0
+}
+
+func (r *Router) sendToMatches(subs map[string]*subscription, envelope *plumbing.Envelope) {
+	for _, s := range subs {
+		if !matchesAny(s.selectors, envelope) {
+			continue
+		}
+
+		for shardID, setters := range s.shards {
+			r.writeToShard(shardID, setters, envelope)
+		}
+	}
+}
+
+func (r *Router) writeToShard(shardID string, setters []DataSetter, envelope *plumbing.Envelope) {
+	if shardID == "" {
+		for _, setter := range setters {
+			setter.Set(envelope)
+		}
+		return
+	}
+
+	setters[rand.Intn(len(setters))].Set(envelope)
+}
+
+func (r *Router) buildCleanup(sourceID, key, shardID string, dataSetter DataSetter) func() {
+	return func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		s, ok := r.subscriptions[sourceID][key]
+		if !ok {
+			return
+		}
+
+		var setters []DataSetter
+		for _, ds := range s.shards[shardID] {
+			if ds != dataSetter {
+				setters = append(setters, ds)
+			}
+		}
+
+		if len(setters) > 0 {
+			s.shards[shardID] = setters
+			return
+		}
+
+		delete(s.shards, shardID)
+
+		if len(s.shards) > 0 {
+			return
+		}
+
+		delete(r.subscriptions[sourceID], key)
+
+		if len(r.subscriptions[sourceID]) == 0 {
+			delete(r.subscriptions, sourceID)
+		}
+	}
+}
+
+func subscriptionKeys(req *plumbing.SelectiveEgressRequest) (sourceID, shardID, key string) {
+	if egressReq := req.GetRequest(); egressReq != nil {
+		shardID = egressReq.ShardId
+		if egressReq.GetFilter() != nil {
+			sourceID = egressReq.GetFilter().SourceId
+		}
+	}
+
+	selectors := &plumbing.SelectiveEgressRequest{Selectors: req.GetSelectors()}
+	return sourceID, shardID, selectors.String()
+}
@@ -0,0 +1,199 @@
+package v2_test
+
+import (
+	"doppler/grpcmanager/v2"
+	plumbing "plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Router", func() {
+	var (
+		router   *v2.Router
+		envelope *plumbing.Envelope
+	)
+
+	BeforeEach(func() {
+		router = v2.NewRouter()
+		envelope = &plumbing.Envelope{SourceId: "some-id"}
+	})
+
+	It("sends envelopes to streams for the source ID", func() {
+		setter := newMockDataSetter()
+		router.Register(selectiveRequest(&plumbing.EgressRequest{
+			Filter: &plumbing.Filter{SourceId: "some-id"},
+		}), setter)
+
+		router.SendTo("some-id", envelope)
+
+		Expect(setter.SetInput.Data).To(Receive(Equal(envelope)))
+	})
+
+	It("does not send envelopes to streams for other source IDs", func() {
+		setter := newMockDataSetter()
+		router.Register(selectiveRequest(&plumbing.EgressRequest{
+			Filter: &plumbing.Filter{SourceId: "some-other-id"},
+		}), setter)
+
+		router.SendTo("some-id", envelope)
+
+		Expect(setter.SetCalled).To(BeEmpty())
+	})
+
+	It("reports whether it has subscriptions", func() {
+		Expect(router.HasSubscriptions()).To(BeFalse())
+
+		cleanup := router.Register(selectiveRequest(&plumbing.EgressRequest{}), newMockDataSetter())
+		Expect(router.HasSubscriptions()).To(BeTrue())
+
+		cleanup()
+		Expect(router.HasSubscriptions()).To(BeFalse())
+	})
+
+	It("sends envelopes to firehoses", func() {
+		setter := newMockDataSetter()
+		router.Register(selectiveRequest(&plumbing.EgressRequest{}), setter)
+
+		router.SendTo("some-id", envelope)
+
+		Expect(setter.SetInput.Data).To(Receive(Equal(envelope)))
+	})
+
+	It("sends envelopes without a source ID to firehoses once", func() {
+		setter := newMockDataSetter()
+		router.Register(selectiveRequest(&plumbing.EgressRequest{}), setter)
+
+		router.SendTo("", envelope)
+
+		Expect(setter.SetCalled).To(HaveLen(1))
+	})
+
+	It("sends each envelope to a single setter per shard", func() {
+		setterA := newMockDataSetter()
+		setterB := newMockDataSetter()
+		req := selectiveRequest(&plumbing.EgressRequest{ShardId: "some-shard"})
+		router.Register(req, setterA)
+		router.Register(req, setterB)
+
+		router.SendTo("some-id", envelope)
+
+		Expect(len(setterA.SetCalled) + len(setterB.SetCalled)).To(Equal(1))
+	})
+
+	It("stops sending envelopes after cleanup", func() {
+		setter := newMockDataSetter()
+		cleanup := router.Register(selectiveRequest(&plumbing.EgressRequest{
+			Filter: &plumbing.Filter{SourceId: "some-id"},
+		}), setter)
+		cleanup()
+
+		router.SendTo("some-id", envelope)
+
+		Expect(setter.SetCalled).To(BeEmpty())
+	})
+
+	Describe("selectors", func() {
+		var (
+			logEnvelope     *plumbing.Envelope
+			counterEnvelope *plumbing.Envelope
+			gaugeEnvelope   *plumbing.Envelope
+		)
+
+		BeforeEach(func() {
+			logEnvelope = &plumbing.Envelope{
+				SourceId: "some-id",
+				Message:  &plumbing.Envelope_Log{Log: &plumbing.Log{}},
+				Tags: map[string]*plumbing.Value{
+					"source_type": {Data: &plumbing.Value_Text{Text: "APP"}},
+				},
+			}
+			counterEnvelope = &plumbing.Envelope{
+				SourceId: "some-id",
+				Message: &plumbing.Envelope_Counter{
+					Counter: &plumbing.Counter{Name: "some-counter"},
+				},
+			}
+			gaugeEnvelope = &plumbing.Envelope{
+				SourceId: "some-id",
+				Message: &plumbing.Envelope_Gauge{
+					Gauge: &plumbing.Gauge{
+						Metrics: map[string]*plumbing.GaugeValue{
+							"some-gauge": {Value: 1},
+						},
+					},
+				},
+			}
+		})
+
+		register := func(selectors ...*plumbing.Selector) *mockDataSetter {
+			setter := newMockDataSetter()
+			router.Register(&plumbing.SelectiveEgressRequest{
+				Selectors: selectors,
+			}, setter)
+			return setter
+		}
+
+		It("sends only envelopes of the selected type", func() {
+			setter := register(&plumbing.Selector{Type: plumbing.Selector_COUNTER})
+
+			router.SendTo("some-id", logEnvelope)
+			router.SendTo("some-id", gaugeEnvelope)
+			router.SendTo("some-id", counterEnvelope)
+
+			Expect(setter.SetInput.Data).To(Receive(Equal(counterEnvelope)))
+			Expect(setter.SetCalled).To(HaveLen(1))
+		})
+
+		It("sends only counters and gauges with the selected name", func() {
+			setter := register(
+				&plumbing.Selector{Name: "some-gauge"},
+				&plumbing.Selector{Name: "other-counter"},
+			)
+
+			router.SendTo("some-id", logEnvelope)
+			router.SendTo("some-id", counterEnvelope)
+			router.SendTo("some-id", gaugeEnvelope)
+
+			Expect(setter.SetInput.Data).To(Receive(Equal(gaugeEnvelope)))
+			Expect(setter.SetCalled).To(HaveLen(1))
+		})
+
+		It("sends only envelopes with matching tags", func() {
+			setter := register(&plumbing.Selector{
+				Type: plumbing.Selector_LOG,
+				Tags: map[string]string{"source_type": "APP"},
+			})
+			otherSetter := register(&plumbing.Selector{
+				Tags: map[string]string{"source_type": "RTR"},
+			})
+
+			router.SendTo("some-id", counterEnvelope)
+			router.SendTo("some-id", logEnvelope)
+
+			Expect(setter.SetInput.Data).To(Receive(Equal(logEnvelope)))
+			Expect(setter.SetCalled).To(HaveLen(1))
+			Expect(otherSetter.SetCalled).To(BeEmpty())
+		})
+
+		It("keeps subscriptions with different selectors apart", func() {
+			counterSetter := register(&plumbing.Selector{Type: plumbing.Selector_COUNTER})
+			logSetter := register(&plumbing.Selector{Type: plumbing.Selector_LOG})
+			cleanupSetter := newMockDataSetter()
+			cleanup := router.Register(&plumbing.SelectiveEgressRequest{
+				Selectors: []*plumbing.Selector{{Type: plumbing.Selector_LOG}},
+			}, cleanupSetter)
+			cleanup()
+
+			router.SendTo("some-id", logEnvelope)
+
+			Expect(logSetter.SetCalled).To(HaveLen(1))
+			Expect(counterSetter.SetCalled).To(BeEmpty())
+			Expect(cleanupSetter.SetCalled).To(BeEmpty())
+		})
+	})
+})
+
+func selectiveRequest(req *plumbing.EgressRequest) *plumbing.SelectiveEgressRequest {
+	return &plumbing.SelectiveEgressRequest{Request: req}
+}
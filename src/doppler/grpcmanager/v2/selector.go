@@ -0,0 +1,67 @@
+package v2
+
+import plumbing "plumbing/v2"
+
+// matchesAny reports whether the envelope matches at least one of the
+// selectors. An empty set of selectors matches every envelope.
+func matchesAny(selectors []*plumbing.Selector, e *plumbing.Envelope) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+
+	for _, s := range selectors {
+		if matches(s, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(s *plumbing.Selector, e *plumbing.Envelope) bool {
+	return matchesType(s.Type, e) &&
+		matchesName(s.Name, e) &&
+		matchesTags(s.Tags, e)
+}
+
+func matchesType(t plumbing.Selector_Type, e *plumbing.Envelope) bool {
+	switch t {
+	case plumbing.Selector_ANY:
+		return true
+	case plumbing.Selector_LOG:
+		return e.GetLog() != nil
+	case plumbing.Selector_COUNTER:
+		return e.GetCounter() != nil
+	case plumbing.Selector_GAUGE:
+		return e.GetGauge() != nil
+	case plumbing.Selector_TIMER:
+		return e.GetTimer() != nil
+	default:
+		return false
+	}
+}
+
+func matchesName(name string, e *plumbing.Envelope) bool {
+	if name == "" {
+		return true
+	}
+
+	switch {
+	case e.GetCounter() != nil:
+		return e.GetCounter().Name == name
+	case e.GetGauge() != nil:
+		_, ok := e.GetGauge().GetMetrics()[name]
+		return ok
+	default:
+		return false
+	}
+}
+
+func matchesTags(tags map[string]string, e *plumbing.Envelope) bool {
+	for k, v := range tags {
+		actual, ok := e.GetTags()[k]
+		if !ok || actual.GetText() != v {
+			return false
+		}
+	}
+	return true
+}
@@ -25,9 +25,11 @@ type GRPCListener struct {
 
 func NewGRPCListener(
 	router *v1.Router,
+	v2Router *v2.Router,
 	sinkmanager *sinkmanager.SinkManager,
 	conf config.GRPC,
 	envelopeBuffer *diodes.ManyToOneEnvelope,
+	v2EnvelopeBuffer *diodes.ManyToOneEnvelopeV2,
 	batcher *metricbatcher.MetricBatcher,
 ) (*GRPCListener, error) {
 	tlsConfig, err := plumbingv1.NewMutualTLSConfig(
@@ -65,8 +67,12 @@ func NewGRPCListener(
 	plumbingv2.RegisterDopplerIngressServer(
 		grpcServer,
 		// TODO: add batcher to v2 ingestor
-		v2.NewIngestor(envelopeBuffer),
+		v2.NewIngestor(v2EnvelopeBuffer),
 	)
+	// v2 egress
+	v2Egress := v2.NewEgress(v2Router)
+	plumbingv2.RegisterEgressServer(grpcServer, v2Egress)
+	plumbingv2.RegisterSelectiveEgressServer(grpcServer, v2Egress)
 
 	return &GRPCListener{
 		listener: grpcListener,
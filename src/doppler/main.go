@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"math/rand"
 	"metric"
@@ -14,7 +15,9 @@ import (
 	"doppler/config"
 	"doppler/dopplerservice"
 	grpcv1 "doppler/grpcmanager/v1"
+	grpcv2 "doppler/grpcmanager/v2"
 	"doppler/listeners"
+	"doppler/sinks/syslogwriter"
 	"doppler/sinkserver"
 	"doppler/sinkserver/blacklist"
 	"doppler/sinkserver/sinkmanager"
@@ -93,6 +96,8 @@ func main() {
 		time.Duration(conf.SinkIOTimeoutSeconds)*time.Second,
 		time.Duration(conf.ContainerMetricTTLSeconds)*time.Second,
 		time.Duration(conf.SinkDialTimeoutSeconds)*time.Second,
+		sinkTLSCredentials(conf.SinkTLSClientConfig),
+		sinkmanager.WithClientCertDrainHosts(conf.SinkTLSClientConfig.DrainHosts...),
 	)
 
 	//------------------------------
@@ -113,6 +118,15 @@ func main() {
 			metric.WithTag("direction", "ingress"),
 		)
 	}))
+	v2EnvelopeBuffer := diodes.NewManyToOneEnvelopeV2(10000, diodes.AlertFunc(func(missed int) {
+		log.Printf("Shed %d envelopes (v2)", missed)
+		batcher.BatchCounter("doppler.shedEnvelopes").Add(uint64(missed))
+		metric.IncCounter("dropped",
+			metric.WithIncrement(uint64(missed)),
+			metric.WithVersion(2, 0),
+			metric.WithTag("direction", "ingress"),
+		)
+	}))
 
 	udpListener, dropsondeBytesChan := listeners.NewUDPListener(
 		fmt.Sprintf("%s:%d", localIp, conf.IncomingUDPPort),
@@ -121,7 +135,16 @@ func main() {
 	)
 
 	grpcRouter := grpcv1.NewRouter()
-	messageRouter := sinkserver.NewMessageRouter(sinkManager, grpcRouter)
+	grpcRouterV2 := grpcv2.NewRouter()
+	messageRouter := sinkserver.NewMessageRouter(
+		sinkManager,
+		grpcRouter,
+		sinkserver.NewV2Adapter(grpcRouterV2),
+	)
+	messageRouterV2 := sinkserver.NewMessageRouterV2(
+		grpcRouterV2,
+		sinkserver.NewV1Adapter(sinkManager, grpcRouter),
+	)
 	signatureVerifier := signature.NewVerifier(conf.SharedSecret)
 	var tlsListener *listeners.TCPListener
 	if conf.EnableTLSTransport {
@@ -151,9 +174,11 @@ func main() {
 	}
 	grpcListener, err := listeners.NewGRPCListener(
 		grpcRouter,
+		grpcRouterV2,
 		sinkManager,
 		conf.GRPC,
 		envelopeBuffer,
+		v2EnvelopeBuffer,
 		batcher,
 	)
 	if err != nil {
@@ -191,6 +216,7 @@ func main() {
 		openFileMonitor,
 		uptimeMonitor,
 		envelopeBuffer,
+		v2EnvelopeBuffer,
 		appStoreWatcher,
 		newAppServiceChan,
 		deletedAppServiceChan,
@@ -200,6 +226,7 @@ func main() {
 		sinkManager,
 		websocketServer,
 		messageRouter,
+		messageRouterV2,
 		signatureVerifier,
 		tlsListener,
 		tcpListener,
@@ -264,6 +291,7 @@ func start(
 	openFileMonitor *monitor.LinuxFileDescriptor,
 	uptimeMonitor *monitor.Uptime,
 	envelopeBuffer *diodes.ManyToOneEnvelope,
+	v2EnvelopeBuffer *diodes.ManyToOneEnvelopeV2,
 	appStoreWatcher *store.AppServiceStoreWatcher,
 	newAppServiceChan <-chan store.AppService,
 	deletedAppServiceChan <-chan store.AppService,
@@ -273,12 +301,13 @@ func start(
 	sinkManager *sinkmanager.SinkManager,
 	websocketServer *websocketserver.WebsocketServer,
 	messageRouter *sinkserver.MessageRouter,
+	messageRouterV2 *sinkserver.MessageRouterV2,
 	signatureVerifier *signature.Verifier,
 	tlsListener *listeners.TCPListener,
 	tcpListener *listeners.TCPListener,
 	grpcListener *listeners.GRPCListener,
 ) {
-	wg.Add(7 + dropsondeUnmarshallerCollection.Size())
+	wg.Add(8 + dropsondeUnmarshallerCollection.Size())
 
 	dropsondeVerifiedBytesChan := make(chan []byte)
 
@@ -341,6 +370,11 @@ func start(
 		messageRouter.Start(envelopeBuffer)
 	}()
 
+	go func() {
+		defer wg.Done()
+		messageRouterV2.Start(v2EnvelopeBuffer)
+	}()
+
 	go func() {
 		defer wg.Done()
 		websocketServer.Start()
@@ -438,3 +472,31 @@ func setupMetricsEmitter(conf *config.Config) {
 		metric.WithDeploymentMeta(conf.DeploymentName, conf.JobName, conf.Index),
 	)
 }
+
+func sinkTLSCredentials(conf config.SinkTLSClientConfig) *syslogwriter.TLSCredentials {
+	var (
+		creds syslogwriter.TLSCredentials
+		err   error
+	)
+
+	if conf.CertFile != "" {
+		creds.Cert, err = ioutil.ReadFile(conf.CertFile)
+		if err != nil {
+			log.Fatalf("Unable to read sink TLS client cert: %s", err)
+		}
+
+		creds.Key, err = ioutil.ReadFile(conf.KeyFile)
+		if err != nil {
+			log.Fatalf("Unable to read sink TLS client key: %s", err)
+		}
+	}
+
+	if conf.CAFile != "" {
+		creds.CA, err = ioutil.ReadFile(conf.CAFile)
+		if err != nil {
+			log.Fatalf("Unable to read sink TLS CA: %s", err)
+		}
+	}
+
+	return &creds
+}
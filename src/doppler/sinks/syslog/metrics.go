@@ -0,0 +1,170 @@
+package syslog
+
+import (
+	"doppler/sinks/syslogwriter"
+	"encoding/json"
+	"strconv"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+const (
+	// gaugeSDID and counterSDID are the SD-IDs of the elements that hold a
+	// metric sent to a syslog drain.
+	gaugeSDID   = "gauge@47450"
+	counterSDID = "counter@47450"
+
+	// metricPriority is the priority of metric messages, user.info.
+	metricPriority = 14
+)
+
+// message is a single message sent to the drain. Either raw is set, and
+// sent as it is, or the fields of a syslog message are.
+type message struct {
+	priority  int
+	body      []byte
+	source    string
+	sourceID  string
+	timestamp int64
+	data      []syslogwriter.SDElement
+
+	raw []byte
+}
+
+type gauge struct {
+	name  string
+	value float64
+	unit  string
+}
+
+// gauges returns the gauges of a ContainerMetric or ValueMetric envelope.
+func gauges(envelope *events.Envelope) []gauge {
+	switch envelope.GetEventType() {
+	case events.Envelope_ContainerMetric:
+		m := envelope.GetContainerMetric()
+		return []gauge{
+			{name: "cpu", value: m.GetCpuPercentage(), unit: "percentage"},
+			{name: "memory", value: float64(m.GetMemoryBytes()), unit: "bytes"},
+			{name: "disk", value: float64(m.GetDiskBytes()), unit: "bytes"},
+			{name: "memory_quota", value: float64(m.GetMemoryBytesQuota()), unit: "bytes"},
+			{name: "disk_quota", value: float64(m.GetDiskBytesQuota()), unit: "bytes"},
+		}
+	case events.Envelope_ValueMetric:
+		m := envelope.GetValueMetric()
+		return []gauge{
+			{name: m.GetName(), value: m.GetValue(), unit: m.GetUnit()},
+		}
+	default:
+		return nil
+	}
+}
+
+// metricSyslogMessages returns an RFC 5424 message for each gauge or counter
+// of the envelope. The metric and the envelope tags are given as structured
+// data. Each metric is sent in its own message since an SD-ID may only
+// appear once in a message.
+func metricSyslogMessages(envelope *events.Envelope) []message {
+	source := envelope.GetOrigin()
+	var sourceID string
+	if envelope.GetEventType() == events.Envelope_ContainerMetric {
+		source = "APP"
+		sourceID = strconv.Itoa(int(envelope.GetContainerMetric().GetInstanceIndex()))
+	}
+
+	var elements []syslogwriter.SDElement
+	for _, g := range gauges(envelope) {
+		elements = append(elements, syslogwriter.SDElement{
+			ID: gaugeSDID,
+			Params: []syslogwriter.SDParam{
+				{Name: "name", Value: g.name},
+				{Name: "value", Value: strconv.FormatFloat(g.value, 'g', -1, 64)},
+				{Name: "unit", Value: g.unit},
+			},
+		})
+	}
+
+	if envelope.GetEventType() == events.Envelope_CounterEvent {
+		c := envelope.GetCounterEvent()
+		elements = append(elements, syslogwriter.SDElement{
+			ID: counterSDID,
+			Params: []syslogwriter.SDParam{
+				{Name: "name", Value: c.GetName()},
+				{Name: "total", Value: strconv.FormatUint(c.GetTotal(), 10)},
+				{Name: "delta", Value: strconv.FormatUint(c.GetDelta(), 10)},
+			},
+		})
+	}
+
+	tags := tagsElement(envelope)
+	messages := make([]message, 0, len(elements))
+	for _, e := range elements {
+		messages = append(messages, message{
+			priority:  metricPriority,
+			source:    source,
+			sourceID:  sourceID,
+			timestamp: envelope.GetTimestamp(),
+			data:      []syslogwriter.SDElement{e, tags},
+		})
+	}
+	return messages
+}
+
+type jsonMetric struct {
+	Timestamp     int64                  `json:"timestamp"`
+	AppID         string                 `json:"app_id,omitempty"`
+	InstanceIndex *int32                 `json:"instance_index,omitempty"`
+	Origin        string                 `json:"origin,omitempty"`
+	Deployment    string                 `json:"deployment,omitempty"`
+	Job           string                 `json:"job,omitempty"`
+	Index         string                 `json:"index,omitempty"`
+	Tags          map[string]string      `json:"tags,omitempty"`
+	Gauges        map[string]jsonGauge   `json:"gauges,omitempty"`
+	Counters      map[string]jsonCounter `json:"counters,omitempty"`
+}
+
+type jsonGauge struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+type jsonCounter struct {
+	Total uint64 `json:"total"`
+	Delta uint64 `json:"delta"`
+}
+
+// metricJSONMessage returns a message holding the envelope as a JSON
+// document.
+func metricJSONMessage(envelope *events.Envelope) (message, error) {
+	m := jsonMetric{
+		Timestamp:  envelope.GetTimestamp(),
+		Origin:     envelope.GetOrigin(),
+		Deployment: envelope.GetDeployment(),
+		Job:        envelope.GetJob(),
+		Index:      envelope.GetIndex(),
+		Tags:       envelope.GetTags(),
+	}
+
+	if cm := envelope.GetContainerMetric(); cm != nil {
+		m.AppID = cm.GetApplicationId()
+		m.InstanceIndex = cm.InstanceIndex
+	}
+
+	for _, g := range gauges(envelope) {
+		if m.Gauges == nil {
+			m.Gauges = make(map[string]jsonGauge)
+		}
+		m.Gauges[g.name] = jsonGauge{Value: g.value, Unit: g.unit}
+	}
+
+	if c := envelope.GetCounterEvent(); c != nil {
+		m.Counters = map[string]jsonCounter{
+			c.GetName(): {Total: c.GetTotal(), Delta: c.GetDelta()},
+		}
+	}
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return message{}, err
+	}
+	return message{raw: raw}, nil
+}
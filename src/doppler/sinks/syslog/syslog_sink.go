@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"sort"
 	"sync"
 	"time"
 	"truncatingbuffer"
@@ -14,6 +15,18 @@ import (
 	"github.com/cloudfoundry/sonde-go/events"
 )
 
+// structuredDataParam is the drain URL parameter that adds the envelope tags
+// to each message as structured data.
+const structuredDataParam = "structured-data"
+
+// drainTypeParam is the drain URL parameter that selects whether logs,
+// metrics or both are sent to the drain. Logs are sent by default.
+const drainTypeParam = "drain-type"
+
+// tagsSDID is the SD-ID of the element that holds the envelope tags. 47450 is
+// the private enterprise number of the Cloud Foundry Foundation.
+const tagsSDID = "tags@47450"
+
 type SyslogSink struct {
 	appId                  string
 	drainURL               *url.URL
@@ -26,6 +39,9 @@ type SyslogSink struct {
 	disconnectChannel      chan struct{}
 	dropsondeOrigin        string
 	disconnectOnce         sync.Once
+	structuredData         bool
+	eventTypes             []events.Envelope_EventType
+	rawWriter              syslogwriter.RawWriter
 }
 
 func NewSyslogSink(appId string, drainURL *url.URL, messageDrainBufferSize uint, syslogWriter syslogwriter.Writer, errorHandler func(string, string), dropsondeOrigin string) *SyslogSink {
@@ -38,8 +54,13 @@ func NewSyslogSink(appId string, drainURL *url.URL, messageDrainBufferSize uint,
 		handleSendError:        errorHandler,
 		disconnectChannel:      make(chan struct{}),
 		dropsondeOrigin:        dropsondeOrigin,
+		structuredData:         drainURL.Query().Get(structuredDataParam) == "true",
+		eventTypes:             drainEventTypes(drainURL),
 	}
 
+	// Writers that can send raw lines are sent metrics as JSON.
+	syslogSink.rawWriter, _ = syslogWriter.(syslogwriter.RawWriter)
+
 	log.Printf("Syslog Sink %s: Created for appId [%s]", syslogSink.Identifier(), appId)
 	return syslogSink
 }
@@ -51,7 +72,7 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 
 	backoffStrategy := retrystrategy.Exponential()
 
-	context := truncatingbuffer.NewLogAllowedContext(s.dropsondeOrigin, syslogIdentifier)
+	context := truncatingbuffer.NewEventTypeAllowedContext(s.dropsondeOrigin, syslogIdentifier, s.eventTypes...)
 	buffer := sinks.RunTruncatingBuffer(inputChan, s.messageDrainBufferSize, context, s.disconnectChannel)
 	timer := time.NewTimer(backoffStrategy(0))
 	connected := false
@@ -69,8 +90,9 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 				return
 			}
 
+			messages := s.messages(messageEnvelope)
 			numberOfTries := 0
-			for {
+			for len(messages) > 0 {
 				for !connected {
 					err := s.syslogWriter.Connect()
 					if err == nil {
@@ -94,10 +116,11 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 					numberOfTries++
 				}
 
-				err := s.sendLogMessage(messageEnvelope.GetLogMessage())
+				err := s.send(messages[0])
 				if err == nil {
+					messages = messages[1:]
 					connected = true
-					break
+					continue
 				}
 
 				connected = false
@@ -126,11 +149,121 @@ func (s *SyslogSink) ShouldReceiveErrors() bool {
 	return false
 }
 
-func (s *SyslogSink) sendLogMessage(logMessage *events.LogMessage) error {
-	_, err := s.syslogWriter.Write(messagePriorityValue(logMessage), logMessage.GetMessage(), logMessage.GetSourceType(), logMessage.GetSourceInstance(), *logMessage.Timestamp)
+// ShouldReceiveMetrics returns true if the drain-type of the drain includes
+// metrics.
+func (s *SyslogSink) ShouldReceiveMetrics() bool {
+	for _, t := range s.eventTypes {
+		if t != events.Envelope_LogMessage {
+			return true
+		}
+	}
+	return false
+}
+
+// drainEventTypes returns the event types sent to the drain as selected by
+// its drain-type parameter.
+func drainEventTypes(drainURL *url.URL) []events.Envelope_EventType {
+	logTypes := []events.Envelope_EventType{events.Envelope_LogMessage}
+	metricTypes := []events.Envelope_EventType{
+		events.Envelope_ContainerMetric,
+		events.Envelope_ValueMetric,
+		events.Envelope_CounterEvent,
+	}
+
+	switch drainURL.Query().Get(drainTypeParam) {
+	case "metrics":
+		return metricTypes
+	case "all":
+		return append(logTypes, metricTypes...)
+	default:
+		return logTypes
+	}
+}
+
+// messages returns the messages to send to the drain for the envelope.
+// Metrics may be sent as several messages.
+func (s *SyslogSink) messages(envelope *events.Envelope) []message {
+	switch envelope.GetEventType() {
+	case events.Envelope_LogMessage:
+		return []message{s.logMessage(envelope)}
+	case events.Envelope_ContainerMetric, events.Envelope_ValueMetric, events.Envelope_CounterEvent:
+		if s.rawWriter == nil {
+			return metricSyslogMessages(envelope)
+		}
+
+		m, err := metricJSONMessage(envelope)
+		if err != nil {
+			log.Printf("Syslog Sink %s: failed to marshal metric: %s", s.Identifier(), err)
+			return nil
+		}
+		return []message{m}
+	default:
+		return nil
+	}
+}
+
+func (s *SyslogSink) logMessage(envelope *events.Envelope) message {
+	logMessage := envelope.GetLogMessage()
+
+	var data []syslogwriter.SDElement
+	if s.structuredData {
+		data = append(data, tagsElement(envelope))
+	}
+
+	return message{
+		priority:  messagePriorityValue(logMessage),
+		body:      logMessage.GetMessage(),
+		source:    logMessage.GetSourceType(),
+		sourceID:  logMessage.GetSourceInstance(),
+		timestamp: logMessage.GetTimestamp(),
+		data:      data,
+	}
+}
+
+func (s *SyslogSink) send(m message) error {
+	if m.raw != nil {
+		_, err := s.rawWriter.WriteRaw(m.raw)
+		return err
+	}
+
+	_, err := s.syslogWriter.Write(m.priority, m.body, m.source, m.sourceID, m.timestamp, m.data...)
 	return err
 }
 
+// tagsElement returns an SD-ELEMENT holding the source of the log message,
+// the fields identifying the emitter of the envelope and the envelope tags.
+// Empty fields are left out.
+func tagsElement(envelope *events.Envelope) syslogwriter.SDElement {
+	logMessage := envelope.GetLogMessage()
+	fields := []syslogwriter.SDParam{
+		{Name: "source_type", Value: logMessage.GetSourceType()},
+		{Name: "source_instance", Value: logMessage.GetSourceInstance()},
+		{Name: "origin", Value: envelope.GetOrigin()},
+		{Name: "deployment", Value: envelope.GetDeployment()},
+		{Name: "job", Value: envelope.GetJob()},
+		{Name: "index", Value: envelope.GetIndex()},
+	}
+
+	e := syslogwriter.SDElement{ID: tagsSDID}
+	for _, f := range fields {
+		if f.Value != "" {
+			e.Params = append(e.Params, f)
+		}
+	}
+
+	tags := envelope.GetTags()
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		e.Params = append(e.Params, syslogwriter.SDParam{Name: name, Value: tags[name]})
+	}
+	return e
+}
+
 func messagePriorityValue(msg *events.LogMessage) int {
 	switch msg.GetMessageType() {
 	case events.LogMessage_OUT:
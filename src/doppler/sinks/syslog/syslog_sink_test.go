@@ -2,10 +2,13 @@ package syslog_test
 
 import (
 	"doppler/sinks/syslog"
+	"doppler/sinks/syslogwriter"
 	"errors"
 	"fmt"
 	"net"
 	"net/url"
+	"regexp"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -117,6 +120,79 @@ var _ = Describe("SyslogSink", func() {
 		})
 	})
 
+	Context("when the writer can send raw lines", func() {
+		var rawLogger *RawWriterRecorder
+
+		JustBeforeEach(func() {
+			rawLogger = &RawWriterRecorder{SyslogWriterRecorder: sysLogger}
+			drainURL, _ := url.Parse("https://using-fake?drain-type=all")
+			syslogSink = syslog.NewSyslogSink("appId", drainURL, bufferSize, rawLogger, errorHandler, "dropsonde-origin")
+
+			go func() {
+				syslogSink.Run(inputChan)
+				close(syslogSinkRunFinished)
+			}()
+		})
+
+		AfterEach(func() {
+			syslogSink.Disconnect()
+			Eventually(syslogSinkRunFinished).Should(BeClosed())
+		})
+
+		It("sends metrics as JSON", func() {
+			envelope, _ := emitter.Wrap(factories.NewContainerMetric("appId", 3, 12.5, 1024, 2048), "origin")
+			envelope.Timestamp = proto.Int64(1234)
+			envelope.Tags = map[string]string{"zone": "z1"}
+
+			inputChan <- envelope
+
+			var data string
+			Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+			Expect(data).To(HavePrefix("raw: "))
+			Expect(strings.TrimPrefix(data, "raw: ")).To(MatchJSON(`{
+				"timestamp": 1234,
+				"app_id": "appId",
+				"instance_index": 3,
+				"origin": "origin",
+				"tags": {"zone": "z1"},
+				"gauges": {
+					"cpu": {"value": 12.5, "unit": "percentage"},
+					"memory": {"value": 1024, "unit": "bytes"},
+					"disk": {"value": 2048, "unit": "bytes"},
+					"memory_quota": {"value": 0, "unit": "bytes"},
+					"disk_quota": {"value": 0, "unit": "bytes"}
+				}
+			}`))
+		})
+
+		It("sends counter events as JSON", func() {
+			counterEvent := factories.NewCounterEvent("some-counter", 2)
+			counterEvent.Total = proto.Uint64(10)
+			envelope, _ := emitter.Wrap(counterEvent, "origin")
+			envelope.Timestamp = proto.Int64(1234)
+
+			inputChan <- envelope
+
+			var data string
+			Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+			Expect(strings.TrimPrefix(data, "raw: ")).To(MatchJSON(`{
+				"timestamp": 1234,
+				"origin": "origin",
+				"counters": {"some-counter": {"total": 10, "delta": 2}}
+			}`))
+		})
+
+		It("sends logs as syslog messages", func() {
+			envelope, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "test message", "appId", "App"), "origin")
+
+			inputChan <- envelope
+
+			var data string
+			Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+			Expect(data).To(MatchRegexp(`^<14>1 test message`))
+		})
+	})
+
 	Context("when remote syslog server is up", func() {
 		JustBeforeEach(func() {
 			go func(*syslog.SyslogSink, chan bool) {
@@ -143,6 +219,123 @@ var _ = Describe("SyslogSink", func() {
 			close(done)
 		})
 
+		It("does not send structured data by default", func() {
+			envelope, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "test message", "appId", "App"), "origin")
+
+			inputChan <- envelope
+			var data string
+			Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+			Expect(data).ToNot(ContainSubstring("sd:"))
+		})
+
+		Context("when the drain URL enables structured data", func() {
+			BeforeEach(func() {
+				drainURL = "syslog://using-fake?structured-data=true"
+			})
+
+			It("sends the envelope fields and tags as structured data", func() {
+				logMessage := factories.NewLogMessage(events.LogMessage_OUT, "test message", "appId", "APP/PROC/WEB")
+				logMessage.SourceInstance = proto.String("2")
+				envelope, _ := emitter.Wrap(logMessage, "origin")
+				envelope.Deployment = proto.String("cf")
+				envelope.Job = proto.String("diego_cell")
+				envelope.Index = proto.String("some-guid")
+				envelope.Tags = map[string]string{
+					"zone":   "z1",
+					"custom": `a "quoted" value`,
+				}
+
+				inputChan <- envelope
+				var data string
+				Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+				Expect(data).To(HaveSuffix(
+					` sd: [tags@47450 source_type="APP/PROC/WEB" source_instance="2" origin="origin" deployment="cf" job="diego_cell" index="some-guid" custom="a \"quoted\" value" zone="z1"]`,
+				))
+			})
+		})
+
+		Context("when the drain URL selects metrics", func() {
+			BeforeEach(func() {
+				drainURL = "syslog://using-fake?drain-type=metrics"
+			})
+
+			It("sends each container metric as structured data", func() {
+				containerMetric := factories.NewContainerMetric("appId", 3, 12.5, 1024, 2048)
+				containerMetric.MemoryBytesQuota = proto.Uint64(4096)
+				containerMetric.DiskBytesQuota = proto.Uint64(8192)
+				envelope, _ := emitter.Wrap(containerMetric, "origin")
+				envelope.Deployment = proto.String("cf")
+
+				inputChan <- envelope
+
+				var data []string
+				for i := 0; i < 5; i++ {
+					var msg string
+					Eventually(sysLogger.receivedChannel).Should(Receive(&msg))
+					data = append(data, msg)
+				}
+
+				tags := ` sd: [tags@47450 origin="origin" deployment="cf"]`
+				Expect(data).To(ConsistOf(
+					MatchRegexp(`^<14>1  ts: \d+ src: APP srcId: 3 sd: \[gauge@47450 name="cpu" value="12.5" unit="percentage"\]`+regexp.QuoteMeta(tags)+`$`),
+					HaveSuffix(`sd: [gauge@47450 name="memory" value="1024" unit="bytes"]`+tags),
+					HaveSuffix(`sd: [gauge@47450 name="disk" value="2048" unit="bytes"]`+tags),
+					HaveSuffix(`sd: [gauge@47450 name="memory_quota" value="4096" unit="bytes"]`+tags),
+					HaveSuffix(`sd: [gauge@47450 name="disk_quota" value="8192" unit="bytes"]`+tags),
+				))
+			})
+
+			It("sends value metrics as structured data", func() {
+				envelope, _ := emitter.Wrap(factories.NewValueMetric("some-value", 2.5, "ms"), "origin")
+
+				inputChan <- envelope
+
+				var data string
+				Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+				Expect(data).To(ContainSubstring(`src: origin srcId:  sd: [gauge@47450 name="some-value" value="2.5" unit="ms"]`))
+			})
+
+			It("sends counter events as structured data", func() {
+				counterEvent := factories.NewCounterEvent("some-counter", 2)
+				counterEvent.Total = proto.Uint64(10)
+				envelope, _ := emitter.Wrap(counterEvent, "origin")
+
+				inputChan <- envelope
+
+				var data string
+				Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+				Expect(data).To(ContainSubstring(`sd: [counter@47450 name="some-counter" total="10" delta="2"]`))
+			})
+
+			It("does not send log messages", func() {
+				envelope, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "test message", "appId", "App"), "origin")
+
+				inputChan <- envelope
+
+				Consistently(sysLogger.receivedChannel).ShouldNot(Receive())
+			})
+		})
+
+		Context("when the drain URL selects all", func() {
+			BeforeEach(func() {
+				drainURL = "syslog://using-fake?drain-type=all"
+			})
+
+			It("sends logs and metrics", func() {
+				logEnvelope, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "test message", "appId", "App"), "origin")
+				metricEnvelope, _ := emitter.Wrap(factories.NewValueMetric("some-value", 2.5, "ms"), "origin")
+
+				inputChan <- logEnvelope
+				inputChan <- metricEnvelope
+
+				var data string
+				Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+				Expect(data).To(ContainSubstring("test message"))
+				Eventually(sysLogger.receivedChannel).Should(Receive(&data))
+				Expect(data).To(ContainSubstring(`[gauge@47450 name="some-value"`))
+			})
+		})
+
 		It("does not send non-log messages to the syslog writer", func(done Done) {
 			nonLogMessage := factories.NewValueMetric("value-name", 2.0, "value-unit")
 			envelope, _ := emitter.Wrap(nonLogMessage, "origin")
@@ -249,6 +442,25 @@ var _ = Describe("SyslogSink", func() {
 		})
 	})
 
+	Describe("ShouldReceiveMetrics", func() {
+		It("returns whether the drain-type includes metrics", func() {
+			drains := map[string]bool{
+				"syslog://using-fake":                    false,
+				"syslog://using-fake?drain-type=logs":    false,
+				"syslog://using-fake?drain-type=metrics": true,
+				"syslog://using-fake?drain-type=all":     true,
+			}
+
+			for rawURL, expected := range drains {
+				u, err := url.Parse(rawURL)
+				Expect(err).ToNot(HaveOccurred())
+
+				sink := syslog.NewSyslogSink("appId", u, bufferSize, sysLogger, errorHandler, "dropsonde-origin")
+				Expect(sink.ShouldReceiveMetrics()).To(Equal(expected), rawURL)
+			}
+		})
+	})
+
 	Describe("Disconnect", func() {
 		It("is idempotent", func() {
 			syslogSink.Disconnect()
@@ -331,7 +543,7 @@ func (r *SyslogWriterRecorder) Connect() error {
 	}
 }
 
-func (r *SyslogWriterRecorder) Write(p int, b []byte, source, sourceId string, timestamp int64) (int, error) {
+func (r *SyslogWriterRecorder) Write(p int, b []byte, source, sourceId string, timestamp int64, data ...syslogwriter.SDElement) (int, error) {
 	r.Lock()
 	defer r.Unlock()
 
@@ -340,11 +552,32 @@ func (r *SyslogWriterRecorder) Write(p int, b []byte, source, sourceId string, t
 	}
 
 	messageString := fmt.Sprintf("<%d>1 %s ts: %d src: %s srcId: %s", p, string(b), timestamp, source, sourceId)
+	for _, e := range data {
+		messageString += " sd: " + e.String()
+	}
 	r.receivedMessages = append(r.receivedMessages, messageString)
 	r.receivedChannel <- messageString
 	return len(b), nil
 }
 
+type RawWriterRecorder struct {
+	*SyslogWriterRecorder
+}
+
+func (r *RawWriterRecorder) WriteRaw(line []byte) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.down {
+		return 0, errors.New("Error writing to stdout.")
+	}
+
+	messageString := "raw: " + string(line)
+	r.receivedMessages = append(r.receivedMessages, messageString)
+	r.receivedChannel <- messageString
+	return len(line), nil
+}
+
 func (r *SyslogWriterRecorder) SetDown(newState bool) {
 	r.Lock()
 	defer r.Unlock()
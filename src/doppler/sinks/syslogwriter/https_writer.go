@@ -6,33 +6,94 @@
 package syslogwriter
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"metric"
 	"net"
 	"net/http"
 	"net/url"
 	"plumbing"
-	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	defaultBatchSize     = 100
+	defaultBatchBytes    = 256 * 1024
+	defaultFlushInterval = time.Second
+)
+
 type httpsWriter struct {
 	appId     string
 	hostname  string
 	outputUrl *url.URL
 
+	// drain is the drain URL without its path, query or credentials and
+	// drainID is a hash of the full URL. Both are safe to log and tag
+	// metrics with.
+	drain   string
+	drainID string
+
+	batchSize     int
+	batchBytes    int
+	flushInterval time.Duration
+
 	mu sync.Mutex // guards lastError
 
 	TlsConfig *tls.Config
 	client    *http.Client
 	lastError error
+
+	// sendMu serializes sends so that batches are posted in order. It is
+	// acquired before batchMu.
+	sendMu sync.Mutex
+
+	batchMu sync.Mutex // guards batch, count and timer
+	batch   []byte
+	count   int
+	timer   *time.Timer
+}
+
+// HTTPSOption configures the batching of an httpsWriter.
+type HTTPSOption func(*httpsWriter)
+
+// WithBatchSize sets the number of messages that are sent in a single
+// request. It defaults to 100.
+func WithBatchSize(messages int) HTTPSOption {
+	return func(w *httpsWriter) {
+		w.batchSize = messages
+	}
+}
+
+// WithBatchBytes sets the body size at which a batch is sent before it is
+// full. It defaults to 256KiB.
+func WithBatchBytes(n int) HTTPSOption {
+	return func(w *httpsWriter) {
+		w.batchBytes = n
+	}
+}
+
+// WithFlushInterval sets how long a partial batch is held before it is sent.
+// It defaults to one second.
+func WithFlushInterval(d time.Duration) HTTPSOption {
+	return func(w *httpsWriter) {
+		w.flushInterval = d
+	}
 }
 
-func NewHttpsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify bool, dialer *net.Dialer, timeout time.Duration) (w *httpsWriter, err error) {
+// NewHttpsWriter returns a writer that POSTs newline delimited batches of
+// RFC 5424 messages. A batch is sent once it holds the configured number of
+// messages or bytes, or when the flush interval has elapsed since its first
+// message was written.
+//
+// The writer does not retry. A batch that fails to send is kept and sent
+// again with the next batch, leaving the retries and back off to the sink.
+func NewHttpsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify bool, dialer *net.Dialer, timeout time.Duration, opts ...HTTPSOption) (w *httpsWriter, err error) {
 	if dialer == nil {
 		return nil, errors.New("cannot construct a writer with a nil dialer")
 	}
@@ -52,13 +113,24 @@ func NewHttpsWriter(outputUrl *url.URL, appId, hostname string, skipCertVerify b
 		},
 	}
 	client := &http.Client{Transport: tr, Timeout: timeout}
-	return &httpsWriter{
-		appId:     appId,
-		hostname:  hostname,
-		outputUrl: outputUrl,
-		TlsConfig: tlsConfig,
-		client:    client,
-	}, nil
+	w = &httpsWriter{
+		appId:         appId,
+		hostname:      hostname,
+		outputUrl:     outputUrl,
+		drain:         fmt.Sprintf("%s://%s", outputUrl.Scheme, outputUrl.Host),
+		drainID:       drainID(outputUrl),
+		batchSize:     defaultBatchSize,
+		batchBytes:    defaultBatchBytes,
+		flushInterval: defaultFlushInterval,
+		TlsConfig:     tlsConfig,
+		client:        client,
+	}
+
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w, nil
 }
 
 func (w *httpsWriter) Connect() error {
@@ -72,24 +144,138 @@ func (w *httpsWriter) Connect() error {
 	return nil
 }
 
-func (w *httpsWriter) Write(p int, b []byte, source string, sourceId string, timestamp int64) (int, error) {
-	syslogMsg := createMessage(p, w.appId, w.hostname, source, sourceId, b, timestamp)
-	bytesWritten, err := w.writeHttp(syslogMsg)
-	w.mu.Lock()
-	w.lastError = err
-	w.mu.Unlock()
-	return bytesWritten, err
+// Write adds the message to the current batch. When the batch is full it is
+// sent with the message before Write returns. If sending fails the message is
+// not added and the error is returned so that the message can be written
+// again. The last error, including one from a batch sent on the flush
+// interval, is returned by every Write until it is cleared by Connect.
+func (w *httpsWriter) Write(p int, b []byte, source string, sourceId string, timestamp int64, data ...SDElement) (int, error) {
+	syslogMsg := createMessage(p, w.appId, w.hostname, source, sourceId, b, timestamp, data)
+	return w.add(syslogMsg)
+}
+
+// WriteRaw adds the line to the current batch as it is. It is batched and
+// returns errors in the same way as Write.
+func (w *httpsWriter) WriteRaw(line []byte) (int, error) {
+	msg := string(line)
+	if !bytes.HasSuffix(line, newLine) {
+		msg += "\n"
+	}
+	return w.add(msg)
+}
+
+func (w *httpsWriter) add(msg string) (int, error) {
+	if err := w.err(); err != nil {
+		return 0, err
+	}
+
+	w.batchMu.Lock()
+	if w.count+1 < w.batchSize && len(w.batch)+len(msg) < w.batchBytes {
+		w.batch = append(w.batch, msg...)
+		w.count++
+		if w.timer == nil {
+			w.timer = time.AfterFunc(w.flushInterval, w.flushOnInterval)
+		}
+		w.batchMu.Unlock()
+		return len(msg), nil
+	}
+	w.batchMu.Unlock()
+
+	if err := w.flush(msg); err != nil {
+		w.setError(err)
+		return 0, err
+	}
+	return len(msg), nil
 }
 
+// Close sends any partial batch.
 func (w *httpsWriter) Close() error {
+	return w.flush("")
+}
+
+func (w *httpsWriter) flushOnInterval() {
+	if err := w.flush(""); err != nil {
+		w.setError(err)
+	}
+}
+
+// flush takes the current batch, appends msg to it and sends it. The batch
+// lock is not held while sending so that messages can still be added. If the
+// batch fails to send it is put back in front of the next batch, without
+// msg.
+func (w *httpsWriter) flush(msg string) error {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	w.batchMu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	batch, count := w.batch, w.count
+	w.batch, w.count = nil, 0
+	w.batchMu.Unlock()
+
+	body, n := batch, count
+	if msg != "" {
+		body = append(batch[:len(batch):len(batch)], msg...)
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	err := w.writeHttp(body)
+	if err != nil {
+		w.batchMu.Lock()
+		w.batch = append(batch, w.batch...)
+		w.count += count
+		w.batchMu.Unlock()
+		return err
+	}
+
+	w.emitMetrics(n, time.Since(start))
 	return nil
 }
 
-func (w *httpsWriter) writeHttp(finalMsg string) (byteCount int, err error) {
-	resp, err := w.client.Post(w.outputUrl.String(), "text/plain", strings.NewReader(finalMsg))
-	byteCount = len(finalMsg)
+func (w *httpsWriter) emitMetrics(count int, latency time.Duration) {
+	metric.SetGauge("syslog_batch_size", float64(count), "messages",
+		metric.WithVersion(2, 0),
+		metric.WithTag("app_id", w.appId),
+		metric.WithTag("drain_id", w.drainID),
+	)
+	metric.SetGauge("syslog_batch_latency", float64(latency)/float64(time.Millisecond), "ms",
+		metric.WithVersion(2, 0),
+		metric.WithTag("app_id", w.appId),
+		metric.WithTag("drain_id", w.drainID),
+	)
+}
+
+// drainID returns a short hash that identifies the drain without exposing
+// any credentials in its URL.
+func drainID(u *url.URL) string {
+	hash := sha256.Sum256([]byte(u.String()))
+	return fmt.Sprintf("%x", hash[:8])
+}
+
+// err returns the last error. It is cleared by Connect.
+func (w *httpsWriter) err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastError
+}
+
+func (w *httpsWriter) setError(err error) {
+	w.mu.Lock()
+	w.lastError = err
+	w.mu.Unlock()
+}
+
+func (w *httpsWriter) writeHttp(body []byte) error {
+	resp, err := w.client.Post(w.outputUrl.String(), "text/plain", bytes.NewReader(body))
 	if err != nil {
-		return byteCount, errors.New("syslog https writer: failed to connect")
+		return errors.New("syslog https writer: failed to connect")
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
@@ -97,5 +283,5 @@ func (w *httpsWriter) writeHttp(finalMsg string) (byteCount int, err error) {
 	}
 	io.Copy(ioutil.Discard, resp.Body)
 	resp.Body.Close()
-	return byteCount, err
+	return err
 }
@@ -3,11 +3,14 @@ package syslogwriter_test
 import (
 	"crypto/tls"
 	"doppler/sinks/syslogwriter"
+	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -16,6 +19,12 @@ import (
 
 const standardErrorPriority = 14
 
+// unbatched sends each message as it is written so that errors are returned
+// by the Write that caused them.
+var unbatched = []syslogwriter.HTTPSOption{
+	syslogwriter.WithBatchSize(1),
+}
+
 var _ = Describe("HttpsWriter", func() {
 
 	Context("With an HTTPS Sink", func() {
@@ -53,14 +62,14 @@ var _ = Describe("HttpsWriter", func() {
 
 		It("requires TLS Version 1.2", func() {
 			outputUrl, _ := url.Parse(server.URL + "/234-bxg-234/")
-			w, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+			w, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(w.TlsConfig.MinVersion).To(BeEquivalentTo(tls.VersionTLS12))
 		})
 
 		It("requires certain cipher suites", func() {
 			outputUrl, _ := url.Parse(server.URL + "/234-bxg-234/")
-			w, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+			w, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(w.TlsConfig.CipherSuites).To(ConsistOf(
 				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
@@ -71,7 +80,7 @@ var _ = Describe("HttpsWriter", func() {
 		It("HTTP POSTs each log message to the HTTPS syslog endpoint", func() {
 			outputUrl, _ := url.Parse(server.URL + "/234-bxg-234/")
 
-			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 			err := w.Connect()
 			Expect(err).ToNot(HaveOccurred())
 
@@ -81,10 +90,173 @@ var _ = Describe("HttpsWriter", func() {
 			Eventually(requestChan).Should(Receive(ContainSubstring("org-name.space-name.app-name.1 appId [TEST] - - Message")))
 		})
 
+		It("includes structured data in the message", func() {
+			outputUrl, _ := url.Parse(server.URL + "/234-bxg-234/")
+
+			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
+			parsedTime, _ := time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
+			_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", parsedTime.UnixNano(),
+				syslogwriter.SDElement{
+					ID:     "tags@47450",
+					Params: []syslogwriter.SDParam{{Name: "deployment", Value: "cf"}},
+				},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(requestChan).Should(Receive(ContainSubstring(`org-name.space-name.app-name.1 appId [TEST] - [tags@47450 deployment="cf"] Message`)))
+		})
+
+		It("POSTs raw lines as they are", func() {
+			outputUrl, _ := url.Parse(server.URL + "/234-bxg-234/")
+
+			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
+			_, err := w.WriteRaw([]byte(`{"some":"json"}`))
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(requestChan).Should(Receive(Equal([]byte("{\"some\":\"json\"}\n"))))
+		})
+
+		Describe("batching", func() {
+			var (
+				outputUrl  *url.URL
+				parsedTime time.Time
+			)
+
+			BeforeEach(func() {
+				parsedTime, _ = time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
+			})
+
+			JustBeforeEach(func() {
+				outputUrl, _ = url.Parse(server.URL + "/234-bxg-234/")
+			})
+
+			It("POSTs a newline delimited batch once it is full", func() {
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout,
+					syslogwriter.WithBatchSize(3),
+					syslogwriter.WithFlushInterval(time.Hour),
+				)
+
+				for i := 0; i < 2; i++ {
+					_, err := w.Write(standardErrorPriority, []byte(fmt.Sprintf("Message %d", i)), "test", "TEST", parsedTime.UnixNano())
+					Expect(err).ToNot(HaveOccurred())
+				}
+				Consistently(requestChan, 100*time.Millisecond).ShouldNot(Receive())
+
+				_, err := w.Write(standardErrorPriority, []byte("Message 2"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+
+				var body []byte
+				Expect(requestChan).To(Receive(&body))
+				lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+				Expect(lines).To(HaveLen(3))
+				for i, line := range lines {
+					Expect(line).To(HaveSuffix(fmt.Sprintf("appId [TEST] - - Message %d", i)))
+				}
+			})
+
+			It("POSTs a batch once it reaches the byte limit", func() {
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout,
+					syslogwriter.WithBatchBytes(1),
+					syslogwriter.WithFlushInterval(time.Hour),
+				)
+
+				_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestChan).To(Receive(ContainSubstring("appId [TEST] - - Message")))
+			})
+
+			It("POSTs a partial batch after the flush interval", func() {
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout,
+					syslogwriter.WithFlushInterval(10*time.Millisecond),
+				)
+
+				_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(requestChan).Should(Receive(ContainSubstring("appId [TEST] - - Message")))
+			})
+
+			It("POSTs a partial batch when closed", func() {
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout,
+					syslogwriter.WithFlushInterval(time.Hour),
+				)
+
+				_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(w.Close()).To(Succeed())
+				Expect(requestChan).To(Receive(ContainSubstring("appId [TEST] - - Message")))
+			})
+
+			It("returns the error from a failed interval flush until the next Connect", func() {
+				outputUrl, _ = url.Parse(server.URL + "/doesnotexist")
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout,
+					syslogwriter.WithFlushInterval(10*time.Millisecond),
+				)
+
+				_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(func() error {
+					_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", parsedTime.UnixNano())
+					return err
+				}).Should(HaveOccurred())
+
+				Expect(w.Connect()).To(HaveOccurred())
+				Expect(w.Connect()).To(Succeed())
+			})
+
+			It("keeps a batch that failed to send and sends it with the next batch", func() {
+				var attempts int32
+				serveMux.HandleFunc("/flaky/", func(rw http.ResponseWriter, r *http.Request) {
+					if atomic.AddInt32(&attempts, 1) == 1 {
+						rw.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					syslogHandler(requestChan, http.StatusOK)(rw, r)
+				})
+				outputUrl, _ = url.Parse(server.URL + "/flaky/")
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout,
+					syslogwriter.WithBatchSize(2),
+					syslogwriter.WithFlushInterval(time.Hour),
+				)
+
+				_, err := w.Write(standardErrorPriority, []byte("Message 0"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+				_, err = w.Write(standardErrorPriority, []byte("Message 1"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).To(HaveOccurred())
+				Expect(w.Connect()).To(HaveOccurred())
+
+				_, err = w.Write(standardErrorPriority, []byte("Message 1"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(2)))
+
+				var body []byte
+				Expect(requestChan).To(Receive(&body))
+				lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+				Expect(lines).To(HaveLen(2))
+				for i, line := range lines {
+					Expect(line).To(HaveSuffix(fmt.Sprintf("appId [TEST] - - Message %d", i)))
+				}
+			})
+
+			It("does not retry a batch that failed to send", func() {
+				var attempts int32
+				serveMux.HandleFunc("/failing/", func(rw http.ResponseWriter, r *http.Request) {
+					atomic.AddInt32(&attempts, 1)
+					rw.WriteHeader(http.StatusInternalServerError)
+				})
+				outputUrl, _ = url.Parse(server.URL + "/failing/")
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout,
+					syslogwriter.WithFlushInterval(10*time.Millisecond),
+				)
+
+				_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", parsedTime.UnixNano())
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(func() int32 { return atomic.LoadInt32(&attempts) }).Should(Equal(int32(1)))
+				Consistently(func() int32 { return atomic.LoadInt32(&attempts) }, 100*time.Millisecond).Should(Equal(int32(1)))
+			})
+		})
+
 		It("returns an error when unable to HTTP POST the log message", func() {
 			outputUrl, _ := url.Parse("https://")
 
-			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 			_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", time.Now().UnixNano())
 			Expect(err).To(HaveOccurred())
 		})
@@ -92,7 +264,7 @@ var _ = Describe("HttpsWriter", func() {
 		It("holds onto the last error when unable to POST a log message", func() {
 			outputUrl, _ := url.Parse("https://")
 
-			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 			_, err := w.Write(standardErrorPriority, []byte("Message"), "test", "TEST", time.Now().UnixNano())
 
 			conErr := w.Connect()
@@ -103,7 +275,7 @@ var _ = Describe("HttpsWriter", func() {
 		It("should close connections and return an error if status code returned is not 2XX", func() {
 			outputUrl, _ := url.Parse(server.URL + "/doesnotexist")
 
-			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+			w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 			err := w.Connect()
 			Expect(err).ToNot(HaveOccurred())
 
@@ -122,7 +294,7 @@ var _ = Describe("HttpsWriter", func() {
 			It("should not return error for response 2XX status codes", func() {
 				outputUrl, _ := url.Parse(server.URL + "/234-bxg-234/")
 
-				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 				err := w.Connect()
 				Expect(err).ToNot(HaveOccurred())
 
@@ -147,7 +319,7 @@ var _ = Describe("HttpsWriter", func() {
 
 			It("times out", func() {
 				outputUrl, _ := url.Parse("https://" + listener.Addr().String() + "/")
-				w, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+				w, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 				Expect(err).NotTo(HaveOccurred())
 
 				err = w.Connect()
@@ -176,7 +348,7 @@ var _ = Describe("HttpsWriter", func() {
 
 			It("returns a timeout error", func() {
 				outputUrl, _ := url.Parse(server.URL + "/234-bxg-234/")
-				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 				err := w.Connect()
 				Expect(err).ToNot(HaveOccurred())
 
@@ -194,37 +366,36 @@ var _ = Describe("HttpsWriter", func() {
 				serveMux.Handle("/pause/", requester)
 			})
 
-			It("only keeps one idle connection", func() {
+			It("reuses its connection for concurrent writes", func() {
 				outputUrl, _ := url.Parse(server.URL + "/pause/")
-				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout)
+				w, _ := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", true, dialer, timeout, unbatched...)
 				err := w.Connect()
 				Expect(err).ToNot(HaveOccurred())
 
-				// one connection per request
+				// batches are sent one at a time
 				requester.concurrentWriteRequests(2, w)
-				Expect(listener.GetHistoryLength()).To(Equal(2))
+				Expect(listener.GetHistoryLength()).To(Equal(1))
 
-				// one pooled connection, one new connection
 				requester.concurrentWriteRequests(2, w)
-				Expect(listener.GetHistoryLength()).To(BeNumerically("<=", 3))
+				Expect(listener.GetHistoryLength()).To(Equal(1))
 			})
 		})
 
 		It("returns an error for syslog-tls scheme", func() {
 			outputUrl, _ := url.Parse("syslog-tls://localhost")
-			_, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", false, dialer, timeout)
+			_, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", false, dialer, timeout, unbatched...)
 			Expect(err).To(HaveOccurred())
 		})
 
 		It("returns an error for syslog scheme", func() {
 			outputUrl, _ := url.Parse("syslog://localhost")
-			_, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", false, dialer, timeout)
+			_, err := syslogwriter.NewHttpsWriter(outputUrl, "appId", "org-name.space-name.app-name.1", false, dialer, timeout, unbatched...)
 			Expect(err).To(HaveOccurred())
 		})
 
 		It("returns an error when the provided dialer is nil", func() {
 			outputURL, _ := url.Parse("https://localhost")
-			_, err := syslogwriter.NewHttpsWriter(outputURL, "appId", "org-name.space-name.app-name.1", false, nil, timeout)
+			_, err := syslogwriter.NewHttpsWriter(outputURL, "appId", "org-name.space-name.app-name.1", false, nil, timeout, unbatched...)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("cannot construct a writer with a nil dialer"))
 		})
@@ -0,0 +1,74 @@
+package syslogwriter
+
+import (
+	"bytes"
+	"strings"
+)
+
+// maxSDNameLength is the longest SD-ID or PARAM-NAME allowed by RFC 5424.
+const maxSDNameLength = 32
+
+// SDElement is an RFC 5424 SD-ELEMENT. Its ID should be registered with IANA
+// or be of the form name@<private enterprise number>.
+type SDElement struct {
+	ID     string
+	Params []SDParam
+}
+
+// SDParam is a single SD-PARAM of an SD-ELEMENT.
+type SDParam struct {
+	Name  string
+	Value string
+}
+
+// String formats the element as it appears in the STRUCTURED-DATA field of a
+// message. Characters that are not allowed in names are replaced with an
+// underscore and names are truncated to 32 characters. '"', '\' and ']' are
+// escaped in values.
+func (e SDElement) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	buf.WriteString(sdName(e.ID))
+	for _, p := range e.Params {
+		buf.WriteByte(' ')
+		buf.WriteString(sdName(p.Name))
+		buf.WriteString(`="`)
+		buf.WriteString(sdValueEscaper.Replace(p.Value))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
+var sdValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// sdName replaces characters that are not PRINTUSASCII or are one of '=',
+// ' ', ']' and '"'. The ID of an element may contain '@' so it is kept.
+func sdName(name string) string {
+	if name == "" {
+		return "_"
+	}
+
+	b := []byte(name)
+	if len(b) > maxSDNameLength {
+		b = b[:maxSDNameLength]
+	}
+	for i, c := range b {
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func formatStructuredData(data []SDElement) string {
+	if len(data) == 0 {
+		return "-"
+	}
+
+	elements := make([]string, 0, len(data))
+	for _, e := range data {
+		elements = append(elements, e.String())
+	}
+	return strings.Join(elements, "")
+}
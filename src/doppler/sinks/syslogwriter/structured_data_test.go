@@ -0,0 +1,62 @@
+package syslogwriter_test
+
+import (
+	"doppler/sinks/syslogwriter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SDElement", func() {
+	It("formats the element with its params", func() {
+		e := syslogwriter.SDElement{
+			ID: "tags@47450",
+			Params: []syslogwriter.SDParam{
+				{Name: "source_type", Value: "APP/PROC/WEB"},
+				{Name: "source_instance", Value: "0"},
+			},
+		}
+
+		Expect(e.String()).To(Equal(`[tags@47450 source_type="APP/PROC/WEB" source_instance="0"]`))
+	})
+
+	It("formats an element without params", func() {
+		e := syslogwriter.SDElement{ID: "tags@47450"}
+
+		Expect(e.String()).To(Equal(`[tags@47450]`))
+	})
+
+	It("escapes quotes, backslashes and closing brackets in values", func() {
+		e := syslogwriter.SDElement{
+			ID: "tags@47450",
+			Params: []syslogwriter.SDParam{
+				{Name: "value", Value: `a "b" \c] d`},
+			},
+		}
+
+		Expect(e.String()).To(Equal(`[tags@47450 value="a \"b\" \\c\] d"]`))
+	})
+
+	It("replaces characters that are not allowed in names", func() {
+		e := syslogwriter.SDElement{
+			ID: "tags@47450",
+			Params: []syslogwriter.SDParam{
+				{Name: `a b=c]d"é`, Value: "v"},
+				{Name: "", Value: "v"},
+			},
+		}
+
+		Expect(e.String()).To(Equal(`[tags@47450 a_b_c_d___="v" _="v"]`))
+	})
+
+	It("truncates long names", func() {
+		e := syslogwriter.SDElement{
+			ID: "tags@47450",
+			Params: []syslogwriter.SDParam{
+				{Name: "abcdefghijklmnopqrstuvwxyz0123456789", Value: "v"},
+			},
+		}
+
+		Expect(e.String()).To(Equal(`[tags@47450 abcdefghijklmnopqrstuvwxyz012345="v"]`))
+	})
+})
@@ -65,6 +65,17 @@ var _ = Describe("SyslogWriter", func() {
 			Eventually(syslogServerSession, 5).Should(gbytes.Say(`\d <\d+>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{1,6}([-+]\d{2}:\d{2}) org-name.space-name.app-name.1 appId \[APP/PROC/BLAH/2\] - - just a test\n`))
 		}, 10)
 
+		It("sends structured data in the message", func() {
+			sysLogWriter.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano(),
+				syslogwriter.SDElement{
+					ID:     "tags@47450",
+					Params: []syslogwriter.SDParam{{Name: "job", Value: "diego_cell"}},
+				},
+			)
+
+			Eventually(syslogServerSession, 5).Should(gbytes.Say(`org-name.space-name.app-name.1 appId \[APP/2\] - \[tags@47450 job="diego_cell"\] just a test\n`))
+		}, 10)
+
 		It("strips null termination char from message", func() {
 			sysLogWriter.Write(standardOutPriority, []byte(string(0)+" hi"), "appId", "", time.Now().UnixNano())
 
@@ -0,0 +1,59 @@
+package syslogwriter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// TLSCredentials hold the PEM encoded client certificate and key used to
+// authenticate with a drain and the CA bundles used to verify it. Any of them
+// may be empty.
+type TLSCredentials struct {
+	Cert []byte
+	Key  []byte
+	// CA is trusted along with the system roots.
+	CA []byte
+	// DrainCA is the CA bundle given with the drain binding. When it is set
+	// the drain is always verified against it and nothing else.
+	DrainCA []byte
+}
+
+// apply adds the credentials to the TLS config. It is safe to call on nil
+// credentials.
+func (c *TLSCredentials) apply(tlsConfig *tls.Config) error {
+	if c == nil {
+		return nil
+	}
+
+	if len(c.Cert) > 0 || len(c.Key) > 0 {
+		cert, err := tls.X509KeyPair(c.Cert, c.Key)
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(c.DrainCA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(c.DrainCA) {
+			return errors.New("unable to load drain CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+		tlsConfig.InsecureSkipVerify = false
+		return nil
+	}
+
+	if len(c.CA) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(c.CA) {
+			return errors.New("unable to load CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package syslogwriter_test
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"doppler/sinks/syslogwriter"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TLSCredentials", func() {
+	var (
+		ca          *certAuthority
+		serverCert  tls.Certificate
+		clientCreds *syslogwriter.TLSCredentials
+		serverTLS   *tls.Config
+	)
+
+	BeforeEach(func() {
+		ca = newCertAuthority()
+		serverCert = ca.tlsCert(ca.issue("server", net.ParseIP("127.0.0.1")))
+
+		clientCertPEM, clientKeyPEM := ca.issue("client")
+		clientCreds = &syslogwriter.TLSCredentials{
+			Cert: clientCertPEM,
+			Key:  clientKeyPEM,
+			CA:   ca.certPEM,
+		}
+
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(ca.certPEM)
+		serverTLS = &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    pool,
+			MaxVersion:   tls.VersionTLS12,
+		}
+	})
+
+	Context("with an https drain", func() {
+		var (
+			server    *httptest.Server
+			requests  chan []byte
+			outputURL *url.URL
+		)
+
+		BeforeEach(func() {
+			requests = make(chan []byte, 10)
+			server = httptest.NewUnstartedServer(syslogHandler(requests, http.StatusOK))
+			server.TLS = serverTLS
+			server.StartTLS()
+			outputURL, _ = url.Parse(server.URL + "/")
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("authenticates with the client certificate", func() {
+			w, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+			Expect(requests).To(Receive(ContainSubstring("just a test")))
+		})
+
+		It("fails without a client certificate", func() {
+			clientCreds.Cert = nil
+			clientCreds.Key = nil
+			w, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).ToNot(Succeed())
+		})
+
+		It("fails when the drain is not signed by the CA", func() {
+			clientCreds.CA = newCertAuthority().certPEM
+			w, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).ToNot(Succeed())
+		})
+
+		It("verifies the drain against the CA of the drain binding", func() {
+			clientCreds.CA = nil
+			clientCreds.DrainCA = ca.certPEM
+			w, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", true, time.Second, time.Second, clientCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+			Expect(requests).To(Receive(ContainSubstring("just a test")))
+		})
+
+		It("fails when the drain is not signed by the CA of the drain binding even when skipping verification", func() {
+			clientCreds.DrainCA = newCertAuthority().certPEM
+			w, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", true, time.Second, time.Second, clientCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).ToNot(Succeed())
+		})
+	})
+
+	Context("with a syslog-tls drain", func() {
+		var (
+			listener  net.Listener
+			lines     chan string
+			outputURL *url.URL
+		)
+
+		BeforeEach(func() {
+			var err error
+			listener, err = tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+			Expect(err).ToNot(HaveOccurred())
+			outputURL = &url.URL{Scheme: "syslog-tls", Host: listener.Addr().String()}
+
+			lines = make(chan string, 10)
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+
+					go func() {
+						defer conn.Close()
+						line, err := bufio.NewReader(conn).ReadString('\n')
+						if err == nil {
+							lines <- line
+						}
+					}()
+				}
+			}()
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		It("authenticates with the client certificate", func() {
+			w, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+			Expect(err).ToNot(HaveOccurred())
+			defer w.Close()
+
+			Expect(w.Connect()).To(Succeed())
+			_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(lines).Should(Receive(ContainSubstring("just a test")))
+		})
+
+		It("fails to connect without a client certificate", func() {
+			clientCreds.Cert = nil
+			clientCreds.Key = nil
+			w, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(w.Connect()).ToNot(Succeed())
+		})
+	})
+
+	It("returns an error for an invalid key pair", func() {
+		outputURL, _ := url.Parse("syslog-tls://127.0.0.1:1234")
+		clientCreds.Key = []byte("invalid")
+
+		_, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error for an invalid CA bundle", func() {
+		outputURL, _ := url.Parse("https://127.0.0.1:1234")
+		clientCreds.CA = []byte("invalid")
+
+		_, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error for an invalid drain CA bundle", func() {
+		outputURL, _ := url.Parse("https://127.0.0.1:1234")
+		clientCreds.DrainCA = []byte("invalid")
+
+		_, err := syslogwriter.NewWriter(outputURL, "appId", "hostname", false, time.Second, time.Second, clientCreds)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+type certAuthority struct {
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+	certPEM []byte
+	serial  int64
+}
+
+func newCertAuthority() *certAuthority {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).ToNot(HaveOccurred())
+
+	return &certAuthority{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		serial:  1,
+	}
+}
+
+// issue returns a PEM encoded certificate and key signed by the CA.
+func (ca *certAuthority) issue(commonName string, ips ...net.IP) ([]byte, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	ca.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	Expect(err).ToNot(HaveOccurred())
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM
+}
+
+func (ca *certAuthority) tlsCert(certPEM, keyPEM []byte) tls.Certificate {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	Expect(err).ToNot(HaveOccurred())
+	return cert
+}
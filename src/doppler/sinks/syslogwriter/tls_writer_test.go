@@ -101,6 +101,20 @@ var _ = Describe("TLSWriter", func() {
 			Eventually(syslogServerSession, 3).Should(gbytes.Say("just a test"))
 		}, 10)
 
+		It("writes structured data", func() {
+			Eventually(syslogWriter.Connect, 5, 1).ShouldNot(HaveOccurred())
+
+			_, err := syslogWriter.Write(standardOutPriority, []byte("just a test"), "test", "", time.Now().UnixNano(),
+				syslogwriter.SDElement{
+					ID:     "tags@47450",
+					Params: []syslogwriter.SDParam{{Name: "index", Value: "0"}},
+				},
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(syslogServerSession, 3).Should(gbytes.Say(`\[TEST\] - \[tags@47450 index="0"\] just a test`))
+		}, 10)
+
 		Context("when an i/o timeout is set", func() {
 			BeforeEach(func() {
 				// cause an immediate write timeout
@@ -0,0 +1,98 @@
+package syslogwriter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// maxUDPMessageSize is the largest message sent in a datagram. RFC 5426
+// recommends that receivers accept messages of at least 2048 octets.
+const maxUDPMessageSize = 2048
+
+type udpWriter struct {
+	appId    string
+	host     string
+	hostname string
+	dialer   *net.Dialer
+
+	mu           sync.Mutex // guards conn
+	conn         net.Conn
+	writeTimeout time.Duration
+}
+
+// NewUdpWriter returns a writer that sends each message in its own datagram
+// as described in RFC 5426. Messages longer than 2048 bytes are truncated.
+func NewUdpWriter(outputUrl *url.URL, appId, hostname string, dialer *net.Dialer, writeTimeout time.Duration) (w *udpWriter, err error) {
+	if dialer == nil {
+		return nil, errors.New("cannot construct a writer with a nil dialer")
+	}
+
+	if outputUrl.Scheme != "syslog-udp" {
+		return nil, errors.New(fmt.Sprintf("Invalid scheme %s, udpWriter only supports syslog-udp", outputUrl.Scheme))
+	}
+	return &udpWriter{
+		appId:        appId,
+		hostname:     hostname,
+		host:         outputUrl.Host,
+		dialer:       dialer,
+		writeTimeout: writeTimeout,
+	}, nil
+}
+
+// Connect resolves the drain address and opens a socket to it. As UDP is
+// connectionless it only fails if the address can not be resolved.
+func (w *udpWriter) Connect() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		// ignore err from close, it makes sense to continue anyway
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	c, err := w.dialer.Dial("udp", w.host)
+	if err != nil {
+		return err
+	}
+	w.conn = c
+
+	return nil
+}
+
+func (w *udpWriter) Write(p int, b []byte, source string, sourceId string, timestamp int64, data ...SDElement) (byteCount int, err error) {
+	syslogMsg := createMessage(p, w.appId, w.hostname, source, sourceId, b, timestamp, data)
+	// Each datagram holds a single message without framing:
+	// https://tools.ietf.org/html/rfc5426#section-3.1
+	finalMsg := []byte(syslogMsg)
+	if len(finalMsg) > maxUDPMessageSize {
+		finalMsg = finalMsg[:maxUDPMessageSize]
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return 0, errors.New("Connection to syslog-udp sink lost")
+	}
+	if w.writeTimeout != 0 {
+		w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout))
+	}
+	return w.conn.Write(finalMsg)
+}
+
+func (w *udpWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		err := w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package syslogwriter_test
+
+import (
+	"doppler/sinks/syslogwriter"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UdpWriter", func() {
+	var (
+		listener  net.PacketConn
+		dialer    *net.Dialer
+		outputURL *url.URL
+	)
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+
+		dialer = &net.Dialer{Timeout: 500 * time.Millisecond}
+		outputURL = &url.URL{Scheme: "syslog-udp", Host: listener.LocalAddr().String()}
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	readDatagram := func() string {
+		buffer := make([]byte, 65536)
+		listener.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := listener.ReadFrom(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		return string(buffer[:n])
+	}
+
+	It("sends each message in its own datagram without framing", func() {
+		w, err := syslogwriter.NewUdpWriter(outputURL, "appId", "org-name.space-name.app-name.1", dialer, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Connect()).To(Succeed())
+
+		_, err = w.Write(standardOutPriority, []byte("first"), "App", "2", time.Now().UnixNano())
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Write(standardOutPriority, []byte("second"), "App", "2", time.Now().UnixNano())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readDatagram()).To(MatchRegexp(`^<14>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{1,6}([-+]\d{2}:\d{2}) org-name.space-name.app-name.1 appId \[APP/2\] - - first\n$`))
+		Expect(readDatagram()).To(HaveSuffix("- - second\n"))
+	})
+
+	It("truncates long messages", func() {
+		w, err := syslogwriter.NewUdpWriter(outputURL, "appId", "org-name.space-name.app-name.1", dialer, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Connect()).To(Succeed())
+
+		_, err = w.Write(standardOutPriority, []byte(strings.Repeat("a", 4096)), "App", "2", time.Now().UnixNano())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readDatagram()).To(HaveLen(2048))
+	})
+
+	It("connects without a listener", func() {
+		listener.Close()
+
+		w, err := syslogwriter.NewUdpWriter(outputURL, "appId", "org-name.space-name.app-name.1", dialer, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Connect()).To(Succeed())
+	})
+
+	It("returns an error if not connected", func() {
+		w, err := syslogwriter.NewUdpWriter(outputURL, "appId", "org-name.space-name.app-name.1", dialer, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error after it is closed", func() {
+		w, err := syslogwriter.NewUdpWriter(outputURL, "appId", "org-name.space-name.app-name.1", dialer, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Connect()).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		_, err = w.Write(standardOutPriority, []byte("just a test"), "App", "2", time.Now().UnixNano())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error for syslog scheme", func() {
+		outputURL, _ := url.Parse("syslog://localhost")
+		_, err := syslogwriter.NewUdpWriter(outputURL, "appId", "org-name.space-name.app-name.1", dialer, 0)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when the provided dialer is nil", func() {
+		_, err := syslogwriter.NewUdpWriter(outputURL, "appId", "org-name.space-name.app-name.1", nil, 0)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cannot construct a writer with a nil dialer"))
+	})
+})
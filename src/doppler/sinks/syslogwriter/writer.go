@@ -20,10 +20,20 @@ var newLine = []byte("\n")
 
 type Writer interface {
 	Connect() error
-	Write(p int, b []byte, source, sourceId string, timestamp int64) (int, error)
+	// Write sends a message. Any structured data is written in the
+	// STRUCTURED-DATA field of the message.
+	Write(p int, b []byte, source, sourceId string, timestamp int64, data ...SDElement) (int, error)
 	Close() error
 }
 
+// RawWriter is implemented by writers that can send lines that are not
+// syslog messages, such as the JSON documents sent to HTTPS drains.
+type RawWriter interface {
+	WriteRaw(line []byte) (int, error)
+}
+
+// NewWriter returns a writer for the scheme of the URL. The TLS credentials
+// are used by the https and syslog-tls writers and may be nil.
 func NewWriter(
 	outputUrl *url.URL,
 	appId string,
@@ -31,18 +41,35 @@ func NewWriter(
 	skipCertVerify bool,
 	dialTimeout time.Duration,
 	ioTimeout time.Duration,
+	creds *TLSCredentials,
 ) (Writer, error) {
 	dialer := &net.Dialer{Timeout: dialTimeout}
 	switch outputUrl.Scheme {
 	case "https":
-		return NewHttpsWriter(outputUrl, appId, hostname, skipCertVerify, dialer, ioTimeout)
+		w, err := NewHttpsWriter(outputUrl, appId, hostname, skipCertVerify, dialer, ioTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if err := creds.apply(w.TlsConfig); err != nil {
+			return nil, err
+		}
+		return w, nil
 	case "syslog":
 		return NewSyslogWriter(outputUrl, appId, hostname, dialer, ioTimeout)
 	case "syslog-tls":
-		return NewTlsWriter(outputUrl, appId, hostname, skipCertVerify, dialer, ioTimeout)
+		w, err := NewTlsWriter(outputUrl, appId, hostname, skipCertVerify, dialer, ioTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if err := creds.apply(w.TlsConfig); err != nil {
+			return nil, err
+		}
+		return w, nil
+	case "syslog-udp":
+		return NewUdpWriter(outputUrl, appId, hostname, dialer, ioTimeout)
 	default:
 		return nil, errors.New(fmt.Sprintf(
-			"Invalid scheme type %s, must be https, syslog-tls or syslog",
+			"Invalid scheme type %s, must be https, syslog-tls, syslog-udp or syslog",
 			outputUrl.Scheme,
 		))
 	}
@@ -60,6 +87,7 @@ func createMessage(
 	sourceId string,
 	msg []byte,
 	timestamp int64,
+	data []SDElement,
 ) string {
 	// ensure it ends in a \n
 	nl := ""
@@ -81,12 +109,13 @@ func createMessage(
 
 	// syslog format https://tools.ietf.org/html/rfc5424#section-6
 	return fmt.Sprintf(
-		"<%d>1 %s %s %s %s - - %s%s",
+		"<%d>1 %s %s %s %s - %s %s%s",
 		priority,
 		timeString,
 		hostname,
 		appId,
 		formattedSource,
+		formatStructuredData(data),
 		msg,
 		nl,
 	)
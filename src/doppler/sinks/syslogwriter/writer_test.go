@@ -15,7 +15,7 @@ var _ = Describe("Writer", func() {
 
 	It("returns an syslogWriter for syslog scheme", func() {
 		outputUrl, _ := url.Parse("syslog://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0, nil)
 		Expect(err).ToNot(HaveOccurred())
 		writerType := reflect.TypeOf(w).String()
 		Expect(writerType).To(Equal("*syslogwriter.syslogWriter"))
@@ -23,15 +23,23 @@ var _ = Describe("Writer", func() {
 
 	It("returns an tlsWriter for syslog-tls scheme", func() {
 		outputUrl, _ := url.Parse("syslog-tls://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0, nil)
 		Expect(err).ToNot(HaveOccurred())
 		writerType := reflect.TypeOf(w).String()
 		Expect(writerType).To(Equal("*syslogwriter.tlsWriter"))
 	})
 
+	It("returns an udpWriter for syslog-udp scheme", func() {
+		outputUrl, _ := url.Parse("syslog-udp://localhost:9999")
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0, nil)
+		Expect(err).ToNot(HaveOccurred())
+		writerType := reflect.TypeOf(w).String()
+		Expect(writerType).To(Equal("*syslogwriter.udpWriter"))
+	})
+
 	It("returns an httpsWriter for https scheme", func() {
 		outputUrl, _ := url.Parse("https://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0, nil)
 		Expect(err).ToNot(HaveOccurred())
 		writerType := reflect.TypeOf(w).String()
 		Expect(writerType).To(Equal("*syslogwriter.httpsWriter"))
@@ -39,7 +47,7 @@ var _ = Describe("Writer", func() {
 
 	It("returns an error for invalid scheme", func() {
 		outputUrl, _ := url.Parse("notValid://localhost:9999")
-		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0)
+		w, err := syslogwriter.NewWriter(outputUrl, "appId", "hostname", false, 1*time.Second, 0, nil)
 		Expect(err).To(HaveOccurred())
 		Expect(w).To(BeNil())
 	})
@@ -21,6 +21,12 @@ type sinkManager interface {
 	SendTo(string, *events.Envelope)
 }
 
+// metricDrainSender is implemented by sink managers that can send metrics to
+// the syslog drains of the app the metric was emitted by.
+type metricDrainSender interface {
+	SendMetricToDrains(string, *events.Envelope)
+}
+
 func NewMessageRouter(sinkManagers ...sinkManager) *MessageRouter {
 	return &MessageRouter{
 		sinkManagers: sinkManagers,
@@ -55,4 +61,37 @@ func (r *MessageRouter) send(envelope *events.Envelope) {
 	for _, sm := range r.sinkManagers {
 		sm.SendTo(appId, envelope)
 	}
+
+	sendMetricToDrains(r.sinkManagers, appId, metricSourceID(envelope), envelope)
+}
+
+// metricSourceID returns the ID of the app that emitted a ValueMetric or
+// CounterEvent envelope from its source_id or app_id tag. It returns an empty
+// string for other envelopes.
+func metricSourceID(envelope *events.Envelope) string {
+	switch envelope.GetEventType() {
+	case events.Envelope_ValueMetric, events.Envelope_CounterEvent:
+		tags := envelope.GetTags()
+		if id := tags["source_id"]; id != "" {
+			return id
+		}
+		return tags["app_id"]
+	default:
+		return ""
+	}
+}
+
+// sendMetricToDrains sends the metric to the syslog drains of the app that
+// emitted it. Nothing is sent if the metric has no source ID or the source
+// ID is the app ID it was already sent to.
+func sendMetricToDrains(sinkManagers []sinkManager, appID, sourceID string, envelope *events.Envelope) {
+	if sourceID == "" || sourceID == appID {
+		return
+	}
+
+	for _, sm := range sinkManagers {
+		if s, ok := sm.(metricDrainSender); ok {
+			s.SendMetricToDrains(sourceID, envelope)
+		}
+	}
 }
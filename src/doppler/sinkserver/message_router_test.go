@@ -2,12 +2,20 @@ package sinkserver_test
 
 import (
 	"diodes"
+	"doppler/sinks/syslog"
+	"doppler/sinks/syslogwriter"
 	"doppler/sinkserver"
+	"doppler/sinkserver/blacklist"
+	"doppler/sinkserver/sinkmanager"
+	"doppler/store"
+	"net/url"
 	"sync"
+	"time"
 
 	"github.com/cloudfoundry/dropsonde/emitter"
 	"github.com/cloudfoundry/dropsonde/factories"
 	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,16 +23,28 @@ import (
 
 type fakeSinkManager struct {
 	sync.RWMutex
+	receivedAppIds   []string
 	receivedMessages []*events.Envelope
 	receivedDrains   [][]string
+
+	metricDrainAppIds  []string
+	metricDrainMetrics []*events.Envelope
 }
 
 func (f *fakeSinkManager) SendTo(appId string, receivedMessage *events.Envelope) {
 	f.Lock()
 	defer f.Unlock()
+	f.receivedAppIds = append(f.receivedAppIds, appId)
 	f.receivedMessages = append(f.receivedMessages, receivedMessage)
 }
 
+func (f *fakeSinkManager) SendMetricToDrains(appId string, receivedMessage *events.Envelope) {
+	f.Lock()
+	defer f.Unlock()
+	f.metricDrainAppIds = append(f.metricDrainAppIds, appId)
+	f.metricDrainMetrics = append(f.metricDrainMetrics, receivedMessage)
+}
+
 func (f *fakeSinkManager) ManageSyslogSinks(appId string, syslogSinkUrls []string) {
 	f.Lock()
 	defer f.Unlock()
@@ -37,6 +57,18 @@ func (f *fakeSinkManager) received() []*events.Envelope {
 	return f.receivedMessages
 }
 
+func (f *fakeSinkManager) appIds() []string {
+	f.RLock()
+	defer f.RUnlock()
+	return f.receivedAppIds
+}
+
+func (f *fakeSinkManager) metricDrains() []string {
+	f.RLock()
+	defer f.RUnlock()
+	return f.metricDrainAppIds
+}
+
 func (f *fakeSinkManager) drains() [][]string {
 	f.RLock()
 	defer f.RUnlock()
@@ -81,6 +113,147 @@ var _ = Describe("Message Router", func() {
 				Expect(fakeManagerA.received()[0].GetLogMessage()).To(Equal(message.GetLogMessage()))
 				Expect(fakeManagerB.received()[0].GetLogMessage()).To(Equal(message.GetLogMessage()))
 			})
+
+			It("sends value metrics and counters to the metric drains of the app in their tags", func() {
+				incoming.Set(&events.Envelope{
+					Origin:    proto.String("origin"),
+					EventType: events.Envelope_ValueMetric.Enum(),
+					ValueMetric: &events.ValueMetric{
+						Name:  proto.String("some-gauge"),
+						Value: proto.Float64(1),
+						Unit:  proto.String("ms"),
+					},
+					Tags: map[string]string{"source_id": "app-a"},
+				})
+				incoming.Set(&events.Envelope{
+					Origin:    proto.String("origin"),
+					EventType: events.Envelope_CounterEvent.Enum(),
+					CounterEvent: &events.CounterEvent{
+						Name:  proto.String("some-counter"),
+						Delta: proto.Uint64(1),
+					},
+					Tags: map[string]string{"app_id": "app-b"},
+				})
+
+				Eventually(fakeManagerA.metricDrains).Should(Equal([]string{"app-a", "app-b"}))
+				Expect(fakeManagerA.appIds()).To(Equal([]string{"system", "system"}))
+				Expect(fakeManagerB.metricDrains()).To(Equal([]string{"app-a", "app-b"}))
+			})
+
+			It("does not send log messages to the metric drains", func() {
+				message, _ := emitter.Wrap(factories.NewLogMessage(events.LogMessage_OUT, "testMessage", "app", "App"), "origin")
+				incoming.Set(message)
+
+				Eventually(fakeManagerA.received).Should(HaveLen(1))
+				Expect(fakeManagerA.metricDrains()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("with a SinkManager", func() {
+		var (
+			sinkManager     *sinkmanager.SinkManager
+			sinkManagerDone chan struct{}
+			incoming        *diodes.ManyToOneEnvelope
+		)
+
+		BeforeEach(func() {
+			sinkManager = sinkmanager.New(1, true, blacklist.New(nil), 100, "dropsonde-origin",
+				time.Second, 0, time.Second, time.Second, nil)
+			sinkManagerDone = make(chan struct{})
+			go func() {
+				defer close(sinkManagerDone)
+				sinkManager.Start(make(chan store.AppService), make(chan store.AppService))
+			}()
+
+			incoming = diodes.NewManyToOneEnvelope(5, nil)
+			go sinkserver.NewMessageRouter(sinkManager).Start(incoming)
+		})
+
+		AfterEach(func() {
+			sinkManager.Stop()
+			<-sinkManagerDone
+		})
+
+		It("sends value metrics only to the metric drains of the app in their tags", func() {
+			metricsDrain := newFakeSyslogSink("app-a", "https://drain.example.com/?drain-type=metrics")
+			logsDrain := newFakeSyslogSink("app-a", "https://drain.example.com/logs")
+			appSink := newFakeAppSink("app-a")
+			sinkManager.RegisterSink(metricsDrain.sink)
+			sinkManager.RegisterSink(logsDrain.sink)
+			sinkManager.RegisterSink(appSink)
+
+			incoming.Set(&events.Envelope{
+				Origin:    proto.String("origin"),
+				EventType: events.Envelope_ValueMetric.Enum(),
+				ValueMetric: &events.ValueMetric{
+					Name:  proto.String("some-gauge"),
+					Value: proto.Float64(1),
+					Unit:  proto.String("ms"),
+				},
+				Tags: map[string]string{"source_id": "app-a"},
+			})
+
+			Eventually(metricsDrain.writer.lines).Should(HaveLen(1))
+			Consistently(logsDrain.writer.lines).Should(BeEmpty())
+			Expect(appSink.received).ToNot(Receive())
 		})
 	})
 })
+
+type fakeAppSink struct {
+	appID    string
+	received chan *events.Envelope
+}
+
+func newFakeAppSink(appID string) *fakeAppSink {
+	return &fakeAppSink{
+		appID:    appID,
+		received: make(chan *events.Envelope, 100),
+	}
+}
+
+func (s *fakeAppSink) AppID() string             { return s.appID }
+func (s *fakeAppSink) Identifier() string        { return "fake-" + s.appID }
+func (s *fakeAppSink) ShouldReceiveErrors() bool { return false }
+func (s *fakeAppSink) Run(msgs <-chan *events.Envelope) {
+	for msg := range msgs {
+		s.received <- msg
+	}
+}
+
+type fakeSyslogSink struct {
+	sink   *syslog.SyslogSink
+	writer *fakeSyslogWriter
+}
+
+func newFakeSyslogSink(appID, drainURL string) fakeSyslogSink {
+	u, err := url.Parse(drainURL)
+	Expect(err).ToNot(HaveOccurred())
+
+	w := &fakeSyslogWriter{}
+	return fakeSyslogSink{
+		sink:   syslog.NewSyslogSink(appID, u, 100, w, func(string, string) {}, "dropsonde-origin"),
+		writer: w,
+	}
+}
+
+type fakeSyslogWriter struct {
+	sync.Mutex
+	written []string
+}
+
+func (w *fakeSyslogWriter) Connect() error { return nil }
+func (w *fakeSyslogWriter) Close() error   { return nil }
+func (w *fakeSyslogWriter) Write(p int, b []byte, source, sourceID string, timestamp int64, data ...syslogwriter.SDElement) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	w.written = append(w.written, string(b))
+	return len(b), nil
+}
+
+func (w *fakeSyslogWriter) lines() []string {
+	w.Lock()
+	defer w.Unlock()
+	return w.written
+}
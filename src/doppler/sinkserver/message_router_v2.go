@@ -0,0 +1,111 @@
+package sinkserver
+
+import (
+	"diodes"
+	"log"
+	"metric"
+	"plumbing/conversion"
+	v2 "plumbing/v2"
+
+	"github.com/cloudfoundry/dropsonde/envelope_extensions"
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+// MessageRouterV2 reads v2 envelopes and sends them to every v2 sink
+// manager without converting them.
+type MessageRouterV2 struct {
+	sinkManagers []sinkManagerV2
+}
+
+type sinkManagerV2 interface {
+	SendTo(string, *v2.Envelope)
+}
+
+func NewMessageRouterV2(sinkManagers ...sinkManagerV2) *MessageRouterV2 {
+	return &MessageRouterV2{
+		sinkManagers: sinkManagers,
+	}
+}
+
+func (r *MessageRouterV2) Start(incomingLog *diodes.ManyToOneEnvelopeV2) {
+	log.Print("MessageRouterV2:Starting")
+	var count int
+
+	for {
+		envelope := incomingLog.Next()
+		count++
+		if count%1000 == 0 {
+			metric.IncCounter("egress",
+				metric.WithIncrement(1000),
+				metric.WithVersion(2, 0),
+			)
+		}
+
+		for _, sm := range r.sinkManagers {
+			sm.SendTo(envelope.SourceId, envelope)
+		}
+	}
+}
+
+// V1Adapter converts v2 envelopes to v1 for sink managers that only
+// understand v1. Each envelope is converted once regardless of the number of
+// sink managers. Counters and gauges are also sent to the syslog drains of the
+// app of their source ID as it is lost in the conversion.
+type V1Adapter struct {
+	sinkManagers []sinkManager
+}
+
+func NewV1Adapter(sinkManagers ...sinkManager) *V1Adapter {
+	return &V1Adapter{
+		sinkManagers: sinkManagers,
+	}
+}
+
+func (a *V1Adapter) SendTo(sourceID string, envelope *v2.Envelope) {
+	v1e := conversion.ToV1(envelope)
+	if v1e == nil || v1e.EventType == nil {
+		return
+	}
+
+	appID := envelope_extensions.GetAppId(v1e)
+	for _, sm := range a.sinkManagers {
+		sm.SendTo(appID, v1e)
+	}
+
+	switch v1e.GetEventType() {
+	case events.Envelope_ValueMetric, events.Envelope_CounterEvent:
+		sendMetricToDrains(a.sinkManagers, appID, sourceID, v1e)
+	}
+}
+
+// V2Adapter converts v1 envelopes to v2 for v2 sink managers. Envelopes are
+// only converted when a sink manager has subscriptions.
+type V2Adapter struct {
+	sinkManagers []sinkManagerV2
+}
+
+// subscriber is implemented by sink managers that can report whether anyone
+// is subscribed to them.
+type subscriber interface {
+	HasSubscriptions() bool
+}
+
+func NewV2Adapter(sinkManagers ...sinkManagerV2) *V2Adapter {
+	return &V2Adapter{
+		sinkManagers: sinkManagers,
+	}
+}
+
+func (a *V2Adapter) SendTo(appID string, envelope *events.Envelope) {
+	var v2e *v2.Envelope
+	for _, sm := range a.sinkManagers {
+		if s, ok := sm.(subscriber); ok && !s.HasSubscriptions() {
+			continue
+		}
+
+		if v2e == nil {
+			v2e = conversion.ToV2(envelope)
+		}
+		sm.SendTo(v2e.SourceId, v2e)
+	}
+}
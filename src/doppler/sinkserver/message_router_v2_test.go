@@ -0,0 +1,183 @@
+package sinkserver_test
+
+import (
+	"diodes"
+	"doppler/sinkserver"
+	v2 "plumbing/v2"
+	"sync"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeSinkManagerV2 struct {
+	sync.RWMutex
+	receivedSourceIDs []string
+	receivedMessages  []*v2.Envelope
+}
+
+func (f *fakeSinkManagerV2) SendTo(sourceID string, receivedMessage *v2.Envelope) {
+	f.Lock()
+	defer f.Unlock()
+	f.receivedSourceIDs = append(f.receivedSourceIDs, sourceID)
+	f.receivedMessages = append(f.receivedMessages, receivedMessage)
+}
+
+// fakeSubscriberSinkManagerV2 is a fakeSinkManagerV2 that reports whether it
+// has subscriptions.
+type fakeSubscriberSinkManagerV2 struct {
+	fakeSinkManagerV2
+	subscribed bool
+}
+
+func (f *fakeSubscriberSinkManagerV2) HasSubscriptions() bool {
+	return f.subscribed
+}
+
+func (f *fakeSinkManagerV2) received() []*v2.Envelope {
+	f.RLock()
+	defer f.RUnlock()
+	return f.receivedMessages
+}
+
+func (f *fakeSinkManagerV2) sourceIDs() []string {
+	f.RLock()
+	defer f.RUnlock()
+	return f.receivedSourceIDs
+}
+
+var _ = Describe("MessageRouterV2", func() {
+	It("sends the envelope to each sink manager", func() {
+		fakeManagerA := &fakeSinkManagerV2{}
+		fakeManagerB := &fakeSinkManagerV2{}
+		messageRouter := sinkserver.NewMessageRouterV2(fakeManagerA, fakeManagerB)
+
+		incoming := diodes.NewManyToOneEnvelopeV2(5, nil)
+		go messageRouter.Start(incoming)
+
+		envelope := &v2.Envelope{SourceId: "some-id"}
+		incoming.Set(envelope)
+
+		Eventually(fakeManagerA.received).Should(ConsistOf(envelope))
+		Eventually(fakeManagerB.received).Should(ConsistOf(envelope))
+		Expect(fakeManagerA.sourceIDs()).To(ConsistOf("some-id"))
+	})
+})
+
+var _ = Describe("V1Adapter", func() {
+	It("converts the envelope to v1 for each sink manager", func() {
+		fakeManagerA := &fakeSinkManager{}
+		fakeManagerB := &fakeSinkManager{}
+		adapter := sinkserver.NewV1Adapter(fakeManagerA, fakeManagerB)
+
+		adapter.SendTo("some-id", &v2.Envelope{
+			SourceId: "some-id",
+			Message: &v2.Envelope_Log{
+				Log: &v2.Log{
+					Payload: []byte("some-message"),
+				},
+			},
+		})
+
+		Expect(fakeManagerA.received()).To(HaveLen(1))
+		Expect(fakeManagerA.received()[0].GetLogMessage().GetMessage()).To(Equal([]byte("some-message")))
+		Expect(fakeManagerA.received()[0].GetLogMessage().GetAppId()).To(Equal("some-id"))
+		Expect(fakeManagerB.received()).To(HaveLen(1))
+	})
+
+	It("sends counters and gauges to the metric drains of the app of their source ID", func() {
+		fakeManager := &fakeSinkManager{}
+		adapter := sinkserver.NewV1Adapter(fakeManager)
+
+		adapter.SendTo("some-id", &v2.Envelope{
+			SourceId: "some-id",
+			Message: &v2.Envelope_Counter{
+				Counter: &v2.Counter{Name: "some-counter"},
+			},
+		})
+		adapter.SendTo("some-id", &v2.Envelope{
+			SourceId: "some-id",
+			Message: &v2.Envelope_Gauge{
+				Gauge: &v2.Gauge{
+					Metrics: map[string]*v2.GaugeValue{
+						"some-gauge": {Unit: "ms", Value: 1},
+					},
+				},
+			},
+		})
+
+		Expect(fakeManager.appIds()).To(Equal([]string{"system", "system"}))
+		Expect(fakeManager.metricDrains()).To(Equal([]string{"some-id", "some-id"}))
+	})
+
+	It("does not send logs to the metric drains", func() {
+		fakeManager := &fakeSinkManager{}
+		adapter := sinkserver.NewV1Adapter(fakeManager)
+
+		adapter.SendTo("some-id", &v2.Envelope{
+			SourceId: "some-id",
+			Message: &v2.Envelope_Log{
+				Log: &v2.Log{Payload: []byte("some-message")},
+			},
+		})
+
+		Expect(fakeManager.appIds()).To(Equal([]string{"some-id"}))
+		Expect(fakeManager.metricDrains()).To(BeEmpty())
+	})
+
+	It("drops envelopes that can not be converted", func() {
+		fakeManager := &fakeSinkManager{}
+		adapter := sinkserver.NewV1Adapter(fakeManager)
+
+		adapter.SendTo("some-id", &v2.Envelope{SourceId: "some-id"})
+
+		Expect(fakeManager.received()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("V2Adapter", func() {
+	It("converts the envelope to v2 for each sink manager", func() {
+		fakeManager := &fakeSinkManagerV2{}
+		adapter := sinkserver.NewV2Adapter(fakeManager)
+
+		adapter.SendTo("some-app-id", &events.Envelope{
+			Origin:     proto.String("some-origin"),
+			Deployment: proto.String("some-deployment"),
+			Job:        proto.String("some-job"),
+			EventType:  events.Envelope_LogMessage.Enum(),
+			LogMessage: &events.LogMessage{
+				Message:     []byte("some-message"),
+				MessageType: events.LogMessage_OUT.Enum(),
+				Timestamp:   proto.Int64(0),
+				AppId:       proto.String("some-app-id"),
+			},
+		})
+
+		Expect(fakeManager.received()).To(HaveLen(1))
+		Expect(fakeManager.received()[0].GetLog().Payload).To(Equal([]byte("some-message")))
+		Expect(fakeManager.sourceIDs()).To(ConsistOf("some-app-id"))
+	})
+
+	It("does not send envelopes to sink managers without subscriptions", func() {
+		unsubscribed := &fakeSubscriberSinkManagerV2{}
+		subscribed := &fakeSubscriberSinkManagerV2{subscribed: true}
+		adapter := sinkserver.NewV2Adapter(unsubscribed, subscribed)
+
+		adapter.SendTo("some-app-id", &events.Envelope{
+			Origin:    proto.String("some-origin"),
+			EventType: events.Envelope_LogMessage.Enum(),
+			LogMessage: &events.LogMessage{
+				Message:     []byte("some-message"),
+				MessageType: events.LogMessage_OUT.Enum(),
+				Timestamp:   proto.Int64(0),
+				AppId:       proto.String("some-app-id"),
+			},
+		})
+
+		Expect(unsubscribed.received()).To(BeEmpty())
+		Expect(subscribed.received()).To(HaveLen(1))
+	})
+})
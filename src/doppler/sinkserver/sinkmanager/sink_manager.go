@@ -11,6 +11,8 @@ import (
 	"doppler/sinkserver/metrics"
 	"fmt"
 	"log"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,10 +40,23 @@ type SinkManager struct {
 	sinkIOTimeout       time.Duration
 	metricTTL           time.Duration
 	dialTimeout         time.Duration
+	drainTLS            *syslogwriter.TLSCredentials
+	clientCertHosts     []string
 
 	stopOnce sync.Once
 }
 
+// Option configures a SinkManager.
+type Option func(*SinkManager)
+
+// WithClientCertDrainHosts sets the hosts of the drains that are presented
+// the Doppler client certificate. No drain is presented it by default.
+func WithClientCertDrainHosts(hosts ...string) Option {
+	return func(sm *SinkManager) {
+		sm.clientCertHosts = hosts
+	}
+}
+
 func New(
 	maxRetainedLogMessages uint32,
 	skipCertVerify bool,
@@ -52,8 +67,10 @@ func New(
 	sinkIOTimeout,
 	metricTTL,
 	dialTimeout time.Duration,
+	drainTLS *syslogwriter.TLSCredentials,
+	opts ...Option,
 ) *SinkManager {
-	return &SinkManager{
+	sm := &SinkManager{
 		doneChannel:            make(chan struct{}),
 		errorChannel:           make(chan *events.Envelope, 100),
 		urlBlacklistManager:    blackListManager,
@@ -67,7 +84,14 @@ func New(
 		sinkIOTimeout:          sinkIOTimeout,
 		metricTTL:              metricTTL,
 		dialTimeout:            dialTimeout,
+		drainTLS:               drainTLS,
 	}
+
+	for _, o := range opts {
+		o(sm)
+	}
+
+	return sm
 }
 
 func (sm *SinkManager) Start(newAppServiceChan, deletedAppServiceChan <-chan store.AppService) {
@@ -91,6 +115,13 @@ func (sm *SinkManager) SendTo(appID string, msg *events.Envelope) {
 	sm.sinks.Broadcast(appID, msg)
 }
 
+// SendMetricToDrains sends a value metric or counter to the syslog drains of
+// the app that receive metrics. Unlike SendTo it does not create any sinks
+// for the app.
+func (sm *SinkManager) SendMetricToDrains(appID string, msg *events.Envelope) {
+	sm.sinks.BroadcastMetricToDrains(appID, msg)
+}
+
 func (sm *SinkManager) RegisterSink(sink sinks.Sink) bool {
 	inputChan := make(chan *events.Envelope, 128)
 	ok := sm.sinks.RegisterAppSink(inputChan, sink)
@@ -186,7 +217,12 @@ func (sm *SinkManager) listenForNewAppServices(newAppServiceChan <-chan store.Ap
 		case <-sm.doneChannel:
 			return
 		case appService := <-newAppServiceChan:
-			sm.registerNewSyslogSink(appService.AppId(), appService.Url(), appService.Hostname())
+			// A drain that is already registered has had its TLS
+			// credentials rotated and is replaced.
+			if syslogSink := sm.sinks.DrainFor(appService.AppId(), appService.Url()); syslogSink != nil {
+				sm.UnregisterSink(syslogSink)
+			}
+			sm.registerNewSyslogSink(appService.AppId(), appService.Url(), appService.Hostname(), appService.TLS())
 		}
 	}
 }
@@ -220,7 +256,7 @@ func (sm *SinkManager) listenForErrorMessages() {
 	}
 }
 
-func (sm *SinkManager) registerNewSyslogSink(appId, syslogSinkURL, hostname string) {
+func (sm *SinkManager) registerNewSyslogSink(appId, syslogSinkURL, hostname string, drainTLS store.DrainTLS) {
 	parsedSyslogDrainURL, err := sm.urlBlacklistManager.CheckUrl(syslogSinkURL)
 	if err != nil {
 		sm.SendSyslogErrorToLoggregator(invalidSyslogURLErrorMsg(appId, syslogSinkURL, err), appId)
@@ -234,6 +270,7 @@ func (sm *SinkManager) registerNewSyslogSink(appId, syslogSinkURL, hostname stri
 		sm.skipCertVerify,
 		sm.dialTimeout,
 		sm.sinkIOTimeout,
+		sm.tlsCredentials(parsedSyslogDrainURL, drainTLS),
 	)
 	if err != nil {
 		logURL := fmt.Sprintf("%s://%s%s", parsedSyslogDrainURL.Scheme, parsedSyslogDrainURL.Host, parsedSyslogDrainURL.Path)
@@ -253,6 +290,48 @@ func (sm *SinkManager) registerNewSyslogSink(appId, syslogSinkURL, hostname stri
 	sm.RegisterSink(syslogSink)
 }
 
+// tlsCredentials returns the credentials for a drain. A client certificate
+// and key given with the drain binding take precedence over the Doppler
+// defaults. A CA given with the drain binding is the only CA the drain is
+// verified against.
+func (sm *SinkManager) tlsCredentials(drainURL *url.URL, drainTLS store.DrainTLS) *syslogwriter.TLSCredentials {
+	var creds syslogwriter.TLSCredentials
+	if sm.drainTLS != nil {
+		creds.CA = sm.drainTLS.CA
+		if sm.presentClientCert(drainURL, drainTLS) {
+			creds.Cert = sm.drainTLS.Cert
+			creds.Key = sm.drainTLS.Key
+		}
+	}
+
+	if drainTLS.Cert != "" || drainTLS.Key != "" {
+		creds.Cert = []byte(drainTLS.Cert)
+		creds.Key = []byte(drainTLS.Key)
+	}
+	if drainTLS.CA != "" {
+		creds.DrainCA = []byte(drainTLS.CA)
+	}
+
+	return &creds
+}
+
+// presentClientCert returns true if the Doppler client certificate may be
+// presented to the drain. It is only presented to the drains the operator
+// configured and never to a drain that is not verified, as the drain could
+// be anyone.
+func (sm *SinkManager) presentClientCert(drainURL *url.URL, drainTLS store.DrainTLS) bool {
+	if sm.skipCertVerify && drainTLS.CA == "" {
+		return false
+	}
+
+	for _, h := range sm.clientCertHosts {
+		if strings.EqualFold(h, drainURL.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
 func invalidSyslogURLErrorMsg(appId string, syslogSinkURL string, err error) string {
 	return fmt.Sprintf("SinkManager: Invalid syslog drain URL (%s) for application %s. Err: %v", syslogSinkURL, appId, err)
 }
@@ -9,6 +9,7 @@ import (
 	"doppler/sinkserver/blacklist"
 	"doppler/sinkserver/sinkmanager"
 	"doppler/store"
+	"io/ioutil"
 	"net"
 	"net/url"
 	"sync"
@@ -32,7 +33,7 @@ var _ = Describe("SinkManager", func() {
 	BeforeEach(func() {
 		fakeMetricSender.Reset()
 
-		sinkManager = sinkmanager.New(1, true, blackListManager, 100, "dropsonde-origin", 1*time.Second, 0, 1*time.Second, 1*time.Second)
+		sinkManager = sinkmanager.New(1, true, blackListManager, 100, "dropsonde-origin", 1*time.Second, 0, 1*time.Second, 1*time.Second, nil)
 
 		newAppServiceChan = make(chan store.AppService)
 		deletedAppServiceChan = make(chan store.AppService)
@@ -188,6 +189,24 @@ var _ = Describe("SinkManager", func() {
 					Eventually(func() float64 { return fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value }, 2).Should(Equal(initialNumSinks + 1))
 				})
 
+				It("replaces the syslog sink when the drain TLS credentials are rotated", func() {
+					initialNumSinks := fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value
+					numSinks := func() float64 { return fakeMetricSender.GetValue("messageRouter.numberOfSyslogSinks").Value }
+
+					newAppServiceChan <- store.NewServiceInfo("aptastic", "syslog-tls://127.0.1.1:888", "org.space.app.1")
+					Eventually(numSinks, 2).Should(Equal(initialNumSinks + 1))
+
+					ca, err := ioutil.ReadFile("../../sinks/syslogwriter/fixtures/key.crt")
+					Expect(err).ToNot(HaveOccurred())
+					tls := store.DrainTLS{CA: string(ca)}
+					rotated := store.NewServiceInfoWithTLS("aptastic", "syslog-tls://127.0.1.1:888", "org.space.app.1", tls)
+					newAppServiceChan <- rotated
+					Consistently(numSinks, 2).Should(Equal(initialNumSinks + 1))
+
+					deletedAppServiceChan <- rotated
+					Eventually(numSinks, 2).Should(Equal(initialNumSinks))
+				})
+
 				Context("with an invalid drain Url", func() {
 					var errorSink *channelSink
 
@@ -206,6 +225,14 @@ var _ = Describe("SinkManager", func() {
 						Expect(string(errorMsg.GetLogMessage().GetMessage())).To(MatchRegexp("Invalid syslog drain URL"))
 					})
 
+					It("sends an error message if the drain TLS credentials are invalid", func() {
+						tls := store.DrainTLS{Cert: "bad-cert", Key: "bad-key"}
+						newAppServiceChan <- store.NewServiceInfoWithTLS("aptastic", "syslog-tls://127.0.1.1:887", "org.space.app.1", tls)
+						Eventually(errorSink.Received).Should(HaveLen(1))
+						errorMsg := errorSink.Received()[0]
+						Expect(string(errorMsg.GetLogMessage().GetMessage())).To(MatchRegexp("Invalid syslog drain URL"))
+					})
+
 					It("sends an error message if the drain URL is invalid", func() {
 						newAppServiceChan <- store.NewServiceInfo("aptastic", "syslog//invalid", "org.space.app.1")
 						Eventually(errorSink.Received).Should(HaveLen(1))
@@ -242,6 +269,58 @@ var _ = Describe("SinkManager", func() {
 		})
 	})
 
+	Describe("Doppler client certificate", func() {
+		var (
+			errorSink *channelSink
+			manager   *sinkmanager.SinkManager
+			done      chan struct{}
+			newApps   chan store.AppService
+		)
+
+		// start runs a sink manager whose Doppler client certificate is
+		// invalid so that a drain it is presented to fails to be created.
+		start := func(skipCertVerify bool, opts ...sinkmanager.Option) {
+			creds := &syslogwriter.TLSCredentials{Cert: []byte("bad-cert"), Key: []byte("bad-key")}
+			manager = sinkmanager.New(1, skipCertVerify, blackListManager, 100, "dropsonde-origin", time.Second, 0, time.Second, time.Second, creds, opts...)
+
+			newApps = make(chan store.AppService)
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				manager.Start(newApps, make(chan store.AppService))
+			}()
+
+			errorSink = &channelSink{appId: "aptastic", identifier: "myAppChan1", done: make(chan struct{})}
+			manager.RegisterSink(errorSink)
+		}
+
+		AfterEach(func() {
+			manager.Stop()
+			<-done
+		})
+
+		It("is not presented to drains that are not configured", func() {
+			start(false)
+
+			newApps <- store.NewServiceInfo("aptastic", "syslog-tls://127.0.1.1:889", "org.space.app.1")
+			Consistently(errorSink.Received).Should(BeEmpty())
+		})
+
+		It("is presented to the configured drains", func() {
+			start(false, sinkmanager.WithClientCertDrainHosts("127.0.1.1"))
+
+			newApps <- store.NewServiceInfo("aptastic", "https://127.0.1.1:889", "org.space.app.1")
+			Eventually(errorSink.Received).Should(HaveLen(1))
+		})
+
+		It("is not presented to configured drains that are not verified", func() {
+			start(true, sinkmanager.WithClientCertDrainHosts("127.0.1.1"))
+
+			newApps <- store.NewServiceInfo("aptastic", "syslog-tls://127.0.1.1:889", "org.space.app.1")
+			Consistently(errorSink.Received).Should(BeEmpty())
+		})
+	})
+
 	Describe("Stop", func() {
 
 		It("stops", func() {
@@ -26,7 +26,7 @@ import (
 var _ = Describe("WebsocketServer", func() {
 	var (
 		server         *websocketserver.WebsocketServer
-		sinkManager    = sinkmanager.New(1024, false, blacklist.New(nil), 100, "dropsonde-origin", 1*time.Second, 0, 1*time.Second, 500*time.Millisecond)
+		sinkManager    = sinkmanager.New(1024, false, blacklist.New(nil), 100, "dropsonde-origin", 1*time.Second, 0, 1*time.Second, 500*time.Millisecond, nil)
 		appId          = "my-app"
 		wsReceivedChan chan []byte
 		apiEndpoint    string
@@ -5,4 +5,13 @@ type AppService interface {
 	Url() string
 	Hostname() string
 	Id() string
+	TLS() DrainTLS
+}
+
+// DrainTLS holds the PEM encoded client certificate, key and CA bundle to use
+// when connecting to a drain. Empty fields fall back to the Doppler defaults.
+type DrainTLS struct {
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+	CA   string `json:"ca,omitempty"`
 }
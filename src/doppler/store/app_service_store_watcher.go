@@ -11,8 +11,9 @@ import (
 const adapterWatchDir = "/loggregator/v2/services"
 
 type appServiceMetadata struct {
-	Hostname string `json:"hostname"`
-	DrainURL string `json:"drainURL"`
+	Hostname string   `json:"hostname"`
+	DrainURL string   `json:"drainURL"`
+	TLS      DrainTLS `json:"tls"`
 }
 
 type AppServiceStoreWatcher struct {
@@ -38,18 +39,40 @@ func NewAppServiceStoreWatcher(
 	}, outAddChan, outRemoveChan
 }
 
+// Add sends the app service on the add channel unless it is already known.
+// An app service that is known with different TLS credentials is sent again
+// so its sink is replaced with one that uses the new credentials.
 func (w *AppServiceStoreWatcher) Add(appService AppService) {
-	if !w.cache.Exists(appService) {
-		w.cache.Add(appService)
-		w.outAddChan <- appService
+	cached, ok := w.cached(appService)
+	if ok && cached.TLS() == appService.TLS() {
+		return
 	}
+
+	w.cache.Add(appService)
+	w.outAddChan <- appService
 }
 
+// Remove sends the app service on the remove channel if it is known. An app
+// service whose TLS credentials have since been rotated is kept, as it is
+// the key of the old credentials being removed.
 func (w *AppServiceStoreWatcher) Remove(appService AppService) {
-	if w.cache.Exists(appService) {
-		w.cache.Remove(appService)
-		w.outRemoveChan <- appService
+	cached, ok := w.cached(appService)
+	if !ok || cached.TLS() != appService.TLS() {
+		return
+	}
+
+	w.cache.Remove(appService)
+	w.outRemoveChan <- appService
+}
+
+// cached returns the app service in the cache with the same ID.
+func (w *AppServiceStoreWatcher) cached(appService AppService) (AppService, bool) {
+	for _, s := range w.cache.Get(appService.AppId()) {
+		if s.Id() == appService.Id() {
+			return s, true
+		}
 	}
+	return nil, false
 }
 
 func (w *AppServiceStoreWatcher) RemoveApp(appId string) []AppService {
@@ -138,7 +161,7 @@ func appServiceFromStoreNode(node *storeadapter.StoreNode) (AppService, error) {
 		return nil, err
 	}
 
-	return NewServiceInfo(appId, metadata.DrainURL, metadata.Hostname), nil
+	return NewServiceInfoWithTLS(appId, metadata.DrainURL, metadata.Hostname, metadata.TLS), nil
 }
 
 func (w *AppServiceStoreWatcher) deleteEvent(node *storeadapter.StoreNode) {
@@ -204,6 +204,20 @@ var _ = Describe("AppServiceStoreWatcher", func() {
 				})
 			})
 
+			Context("when a new service has TLS credentials", func() {
+				It("adds the service with its credentials", func() {
+					tls := store.DrainTLS{Cert: "some-cert", Key: "some-key", CA: "some-ca"}
+					app2Service2 := store.NewServiceInfoWithTLS(APP2_ID, "syslog-tls://tls.example.com:12345", "org.space.app-two.1", tls)
+
+					adapter.Create(buildNode(app2Service2))
+
+					var appService store.AppService
+					Eventually(outAddChan).Should(Receive(&appService))
+					Expect(appService).To(Equal(app2Service2))
+					Expect(appService.TLS()).To(Equal(tls))
+				})
+			})
+
 			Context("when a new app appears", func() {
 				It("adds that app and its services to the outgoing add channel", func() {
 					app3Service1 := store.NewServiceInfo(APP3_ID, "syslog://app3.example.com:12345", "org.space.app-three.1")
@@ -229,6 +243,32 @@ var _ = Describe("AppServiceStoreWatcher", func() {
 			})
 		})
 
+		Context("when the TLS credentials of a service are rotated", func() {
+			It("adds the service again and keeps it when the old credentials expire", func() {
+				oldTLS := store.DrainTLS{Cert: "old-cert", Key: "old-key"}
+				newTLS := store.DrainTLS{Cert: "new-cert", Key: "new-key"}
+				oldService := store.NewServiceInfoWithTLS(APP2_ID, "syslog-tls://tls.example.com:12345", "org.space.app-two.1", oldTLS)
+				newService := store.NewServiceInfoWithTLS(APP2_ID, "syslog-tls://tls.example.com:12345", "org.space.app-two.1", newTLS)
+
+				Expect(adapter.Create(buildNodeWithKey(oldService, "old-tls"))).To(Succeed())
+				var appService store.AppService
+				Eventually(outAddChan).Should(Receive(&appService))
+				Expect(appService.TLS()).To(Equal(oldTLS))
+
+				Expect(adapter.Create(buildNodeWithKey(newService, "new-tls"))).To(Succeed())
+				Eventually(outAddChan).Should(Receive(&appService))
+				Expect(appService.TLS()).To(Equal(newTLS))
+				Expect(outRemoveChan).To(BeEmpty())
+
+				Expect(adapter.Delete(path.Join(watchDir, APP2_ID, "old-tls"))).To(Succeed())
+				Consistently(outRemoveChan).ShouldNot(Receive())
+				Expect(watcher.Get(APP2_ID)).To(ContainElement(newService))
+
+				Expect(adapter.Delete(path.Join(watchDir, APP2_ID, "new-tls"))).To(Succeed())
+				Eventually(outRemoveChan).Should(Receive(Equal(newService)))
+			})
+		})
+
 		Context("when a service or app should be removed", func() {
 			Context("when an existing app loses one of its services", func() {
 				It("sends that service on the output remove channel", func() {
@@ -332,7 +372,7 @@ func drainOutgoingChannel(c <-chan store.AppService, count int) []store.AppServi
 }
 
 func buildNode(appService store.AppService) storeadapter.StoreNode {
-	m := metaData{Hostname: appService.Hostname(), DrainURL: appService.Url()}
+	m := metaData{Hostname: appService.Hostname(), DrainURL: appService.Url(), TLS: appService.TLS()}
 
 	data, err := json.Marshal(&m)
 	Expect(err).ToNot(HaveOccurred())
@@ -343,9 +383,18 @@ func buildNode(appService store.AppService) storeadapter.StoreNode {
 	}
 }
 
+// buildNodeWithKey returns a node for the app service stored under the given
+// key, as the binder keys drains by a hash of all of their data.
+func buildNodeWithKey(appService store.AppService, id string) storeadapter.StoreNode {
+	node := buildNode(appService)
+	node.Key = path.Join(watchDir, appService.AppId(), id)
+	return node
+}
+
 type metaData struct {
-	Hostname string `json:"hostname"`
-	DrainURL string `json:"drainURL"`
+	Hostname string         `json:"hostname"`
+	DrainURL string         `json:"drainURL"`
+	TLS      store.DrainTLS `json:"tls"`
 }
 
 type fakeStoreAdapter struct {
@@ -9,6 +9,7 @@ type ServiceInfo struct {
 	appId    string
 	url      string
 	hostname string
+	tls      DrainTLS
 }
 
 func NewServiceInfo(appId, url, hostname string) ServiceInfo {
@@ -19,6 +20,14 @@ func NewServiceInfo(appId, url, hostname string) ServiceInfo {
 	}
 }
 
+// NewServiceInfoWithTLS returns a ServiceInfo for a drain that has its own
+// TLS credentials.
+func NewServiceInfoWithTLS(appId, url, hostname string, tls DrainTLS) ServiceInfo {
+	s := NewServiceInfo(appId, url, hostname)
+	s.tls = tls
+	return s
+}
+
 func (s ServiceInfo) Id() string {
 	hash := sha1.Sum([]byte(s.url))
 	return fmt.Sprintf("%x", hash)
@@ -35,3 +44,7 @@ func (s ServiceInfo) Url() string {
 func (s ServiceInfo) Hostname() string {
 	return s.hostname
 }
+
+func (s ServiceInfo) TLS() DrainTLS {
+	return s.tls
+}
@@ -2,6 +2,7 @@ package metric
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	v2 "plumbing/v2"
@@ -46,8 +47,61 @@ func IncCounter(name string, options ...IncrementOpt) {
 		opt(incConf)
 	}
 
+	e := &v2.Envelope{
+		SourceId:  conf.sourceUUID,
+		Timestamp: time.Now().UnixNano(),
+		Message: &v2.Envelope_Counter{
+			Counter: &v2.Counter{
+				Name: name,
+				Value: &v2.Counter_Delta{
+					Delta: incConf.delta,
+				},
+			},
+		},
+		Tags: buildTags(incConf.tags),
+	}
+
+	batchBuffer.Set(e)
+}
+
+// SetGauge records the current value of a gauge. Only the latest value set
+// within a batch interval is sent. It accepts the same options as
+// IncCounter, though WithIncrement has no effect.
+func SetGauge(name string, value float64, unit string, options ...IncrementOpt) {
+	if batchBuffer == nil {
+		return
+	}
+
+	gaugeConf := &incrementOption{
+		tags: make(map[string]string),
+	}
+
+	for _, opt := range options {
+		opt(gaugeConf)
+	}
+
+	e := &v2.Envelope{
+		SourceId:  conf.sourceUUID,
+		Timestamp: time.Now().UnixNano(),
+		Message: &v2.Envelope_Gauge{
+			Gauge: &v2.Gauge{
+				Metrics: map[string]*v2.GaugeValue{
+					name: {
+						Unit:  unit,
+						Value: value,
+					},
+				},
+			},
+		},
+		Tags: buildTags(gaugeConf.tags),
+	}
+
+	batchBuffer.Set(e)
+}
+
+func buildTags(metricTags map[string]string) map[string]*v2.Value {
 	tags := make(map[string]*v2.Value)
-	for k, v := range incConf.tags {
+	for k, v := range metricTags {
 		tags[k] = &v2.Value{
 			Data: &v2.Value_Text{
 				Text: v,
@@ -63,21 +117,7 @@ func IncCounter(name string, options ...IncrementOpt) {
 		}
 	}
 
-	e := &v2.Envelope{
-		SourceId:  conf.sourceUUID,
-		Timestamp: time.Now().UnixNano(),
-		Message: &v2.Envelope_Counter{
-			Counter: &v2.Counter{
-				Name: name,
-				Value: &v2.Counter_Delta{
-					Delta: incConf.delta,
-				},
-			},
-		},
-		Tags: tags,
-	}
-
-	batchBuffer.Set(e)
+	return tags
 }
 
 func runBatcher() {
@@ -93,13 +133,15 @@ func runBatcher() {
 			continue
 		}
 
-		for _, e := range aggregateCounters() {
+		for _, e := range aggregate() {
 			s.Send(e)
 		}
 	}
 }
 
-func aggregateCounters() map[string]*v2.Envelope {
+// aggregate sums counters and keeps the latest value of gauges that share a
+// name and tags.
+func aggregate() map[string]*v2.Envelope {
 	m := make(map[string]*v2.Envelope)
 	for {
 		envelope, ok := batchBuffer.TryNext()
@@ -107,10 +149,10 @@ func aggregateCounters() map[string]*v2.Envelope {
 			break
 		}
 
-		existingEnvelope, ok := m[envelope.GetCounter().Name]
-		if !ok {
-			existingEnvelope = envelope
-			m[envelope.GetCounter().Name] = existingEnvelope
+		key := aggregationKey(envelope)
+		existingEnvelope, ok := m[key]
+		if !ok || envelope.GetGauge() != nil {
+			m[key] = envelope
 			continue
 		}
 
@@ -119,3 +161,26 @@ func aggregateCounters() map[string]*v2.Envelope {
 
 	return m
 }
+
+func aggregationKey(e *v2.Envelope) string {
+	var key string
+	switch m := e.Message.(type) {
+	case *v2.Envelope_Counter:
+		key = "counter:" + m.Counter.Name
+	case *v2.Envelope_Gauge:
+		for name := range m.Gauge.Metrics {
+			key = "gauge:" + name
+		}
+	}
+
+	tagKeys := make([]string, 0, len(e.Tags))
+	for k := range e.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+
+	for _, k := range tagKeys {
+		key += "," + k + "=" + e.Tags[k].GetText()
+	}
+	return key
+}
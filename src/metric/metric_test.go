@@ -161,6 +161,54 @@ var _ = Describe("Metric", func() {
 				Expect(e.Tags["job"].GetText()).To(Equal("some-job"))
 				Expect(e.Tags["index"].GetText()).To(Equal("some-index"))
 			})
+
+			It("does not combine counters with different tags", func() {
+				metric.IncCounter(randName, metric.WithTag("name", "a"))
+				metric.IncCounter(randName, metric.WithTag("name", "b"), metric.WithIncrement(2))
+
+				deltas := make(map[string]uint64)
+				f := func() int {
+					var e *v2.Envelope
+					Eventually(receiver).Should(Receive(&e))
+
+					counter := e.GetCounter()
+					if counter != nil && counter.Name == randName {
+						deltas[e.GetTags()["name"].GetText()] += counter.GetDelta()
+					}
+					return len(deltas)
+				}
+
+				Eventually(f).Should(Equal(2))
+				Expect(deltas).To(Equal(map[string]uint64{"a": 1, "b": 2}))
+			})
+		})
+
+		Describe("SetGauge()", func() {
+			It("writes the latest gauge value periodically to the consumer", func() {
+				metric.SetGauge(randName, 1, "some-unit")
+				metric.SetGauge(randName, 2, "some-unit", metric.WithTag("name", "value"))
+				metric.SetGauge(randName, 3, "some-unit", metric.WithTag("name", "value"))
+
+				var e *v2.Envelope
+				f := func() bool {
+					Eventually(receiver).Should(Receive(&e))
+
+					gauge := e.GetGauge()
+					if gauge == nil {
+						return false
+					}
+
+					_, ok := gauge.GetMetrics()[randName]
+					return ok && e.GetTags()["name"] != nil
+				}
+
+				Eventually(f).Should(BeTrue())
+				Expect(e.SourceId).To(Equal("some-uuid"))
+				Expect(e.GetGauge().GetMetrics()[randName].Value).To(Equal(3.0))
+				Expect(e.GetGauge().GetMetrics()[randName].Unit).To(Equal("some-unit"))
+				Expect(e.GetTags()["name"].GetText()).To(Equal("value"))
+				Expect(e.GetTags()["origin"].GetText()).To(Equal("loggregator.metron"))
+			})
 		})
 	})
 })
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/cloudfoundry/dropsonde/metric_sender"
@@ -16,17 +17,31 @@ import (
 	"metron/clientpool/legacy"
 	clientpool "metron/clientpool/v1"
 	egress "metron/egress/v1"
+	"metron/filter"
+	"metron/health"
 	ingress "metron/ingress/v1"
 	"plumbing"
 )
 
 type AppV1 struct {
-	config *Config
-	creds  credentials.TransportCredentials
+	config   *Config
+	engine   *filter.Engine
+	creds    credentials.TransportCredentials
+	registry *health.Registry
+
+	mu           sync.Mutex
+	connManagers []*clientpool.ConnManager
+	legacyPool   *legacy.ClientPool
 }
 
-func NewV1App(c *Config, creds credentials.TransportCredentials) *AppV1 {
-	return &AppV1{config: c, creds: creds}
+// NewV1App returns an AppV1. The filter engine may be nil.
+func NewV1App(
+	c *Config,
+	e *filter.Engine,
+	creds credentials.TransportCredentials,
+	r *health.Registry,
+) *AppV1 {
+	return &AppV1{config: c, engine: e, creds: creds, registry: r}
 }
 
 func (a *AppV1) Start() {
@@ -40,7 +55,11 @@ func (a *AppV1) Start() {
 	log.Print("Startup: Setting up the Metron agent")
 	marshaller := a.initializeV1DopplerPool(batcher)
 
-	messageTagger := egress.NewTagger(a.config.Deployment, a.config.Job, a.config.Index, marshaller)
+	var w egress.EnvelopeWriter = marshaller
+	if a.engine != nil {
+		w = egress.NewFilter(a.engine, marshaller)
+	}
+	messageTagger := egress.NewTagger(a.config.Deployment, a.config.Job, a.config.Index, w)
 	aggregator := egress.NewAggregator(messageTagger)
 	eventWriter.SetWriter(aggregator)
 
@@ -50,6 +69,7 @@ func (a *AppV1) Start() {
 	if err != nil {
 		log.Panic(fmt.Errorf("Failed to listen on %s: %s", metronAddress, err))
 	}
+	a.registry.RegisterDiode("v1_ingress", networkReader.Buffer())
 
 	log.Printf("metron v1 API started on addr %s", metronAddress)
 	go networkReader.StartReading()
@@ -72,6 +92,10 @@ func (a *AppV1) initializeV1DopplerPool(batcher *metricbatcher.MetricBatcher) *e
 
 	// TODO: delete this legacy pool stuff when UDP goes away
 	legacyPool := legacy.New(a.config.DopplerAddrUDP, 100, 5*time.Second)
+	a.registry.RegisterConns("udp", false, legacyPool)
+	a.mu.Lock()
+	a.legacyPool = legacyPool
+	a.mu.Unlock()
 	udpWrapper := egress.NewUDPWrapper(legacyPool, []byte(a.config.SharedSecret))
 	pools = append(pools, udpWrapper)
 
@@ -88,24 +112,58 @@ func (a *AppV1) setupGRPC() []legacy.Pool {
 		return nil
 	}
 
-	connector := clientpool.MakeGRPCConnector(
-		a.config.DopplerAddr,
-		a.config.Zone,
-		grpc.Dial,
-		plumbing.NewDopplerIngestorClient,
-		grpc.WithTransportCredentials(a.creds),
-	)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	connector := makeV1Connector(a.config, a.creds)
 
-	var connManagers []clientpool.Conn
+	var (
+		connManagers []clientpool.Conn
+		reporters    []health.ConnReporter
+	)
 	for i := 0; i < 5; i++ {
-		connManagers = append(connManagers, clientpool.NewConnManager(
+		m := clientpool.NewConnManager(
 			connector,
 			10000+rand.Int63n(1000),
 			time.Second,
-		))
+		)
+		connManagers = append(connManagers, m)
+		reporters = append(reporters, m)
+		a.connManagers = append(a.connManagers, m)
 	}
+	a.registry.RegisterConns("v1", true, reporters...)
 
 	pool := clientpool.New(connManagers...)
 	grpcWrapper := egress.NewGRPCWrapper(pool)
 	return []legacy.Pool{grpcWrapper}
 }
+
+// Reload reconnects to doppler using the doppler addresses, zone and client
+// credentials from the given config. The UDP listener and anything buffered
+// are left alone.
+func (a *AppV1) Reload(c *Config, creds credentials.TransportCredentials) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.legacyPool != nil {
+		a.legacyPool.SetDopplerAddr(c.DopplerAddrUDP)
+	}
+
+	if creds == nil {
+		return
+	}
+
+	connector := makeV1Connector(c, creds)
+	for _, m := range a.connManagers {
+		m.SetConnector(connector)
+	}
+}
+
+func makeV1Connector(c *Config, creds credentials.TransportCredentials) clientpool.GRPCConnector {
+	return clientpool.MakeGRPCConnector(
+		c.DopplerAddr,
+		c.Zone,
+		grpc.Dial,
+		plumbing.NewDopplerIngestorClient,
+		grpc.WithTransportCredentials(creds),
+	)
+}
@@ -6,10 +6,16 @@ import (
 	"log"
 	"math/rand"
 	"metric"
+	"sync"
 	"time"
 
 	clientpool "metron/clientpool/v2"
 	egress "metron/egress/v2"
+	"metron/filter"
+	"metron/health"
+	"metron/ingress/prometheus"
+	"metron/ingress/statsd"
+	"metron/ingress/syslog"
 	ingress "metron/ingress/v2"
 	v2 "plumbing/v2"
 
@@ -19,19 +25,29 @@ import (
 
 type AppV2 struct {
 	config      *Config
+	engine      *filter.Engine
 	clientCreds credentials.TransportCredentials
 	serverCreds credentials.TransportCredentials
+	registry    *health.Registry
+
+	mu           sync.Mutex
+	connManagers []*clientpool.ConnManager
 }
 
+// NewV2App returns an AppV2. The filter engine may be nil.
 func NewV2App(
 	c *Config,
+	e *filter.Engine,
 	clientCreds credentials.TransportCredentials,
 	serverCreds credentials.TransportCredentials,
+	r *health.Registry,
 ) *AppV2 {
 	return &AppV2{
 		config:      c,
+		engine:      e,
 		clientCreds: clientCreds,
 		serverCreds: serverCreds,
+		registry:    r,
 	}
 }
 
@@ -49,40 +65,211 @@ func (a *AppV2) Start() {
 		)
 		log.Printf("Dropped %d v2 envelopes", missed)
 	}))
+	a.registry.RegisterDiode("v2_ingress", envelopeBuffer)
 
 	pool := a.initializePool()
-	counterAggr := egress.New(pool)
-	tx := egress.NewTransponder(envelopeBuffer, counterAggr)
+	counterAggr := egress.New(
+		a.initializeSpillWriter(pool),
+		egress.WithMaxCounters(a.config.CounterAggregator.MaxCounters),
+		egress.WithCounterTTL(time.Duration(a.config.CounterAggregator.TTLSeconds)*time.Second),
+	)
+	var w egress.Writer = a.initializeTimerAggregator(counterAggr)
+	if a.engine != nil {
+		w = egress.NewFilter(a.engine, w)
+	}
+	tagger := egress.NewTagger(
+		a.config.Deployment,
+		a.config.Job,
+		a.config.Index,
+		a.config.Tags,
+		w,
+	)
+	tx := egress.NewTransponder(envelopeBuffer, tagger)
 	go tx.Start()
 
+	a.startSyslogIngress(envelopeBuffer)
+	a.startPrometheusScraper(envelopeBuffer)
+	a.startStatsDListener(envelopeBuffer)
+
 	metronAddress := fmt.Sprintf("127.0.0.1:%d", a.config.GRPC.Port)
 	log.Printf("metron v2 API started on addr %s", metronAddress)
-	rx := ingress.NewReceiver(envelopeBuffer)
+	rx := ingress.NewReceiver(envelopeBuffer, a.receiverOpts(envelopeBuffer)...)
 	ingressServer := ingress.NewServer(metronAddress, rx, grpc.Creds(a.serverCreds))
 	ingressServer.Start()
 }
 
+func (a *AppV2) initializeTimerAggregator(w egress.Writer) egress.Writer {
+	conf := a.config.TimerAggregation
+	if len(conf.SourceIDs) == 0 {
+		return w
+	}
+
+	aggregator := egress.NewTimerAggregator(
+		w,
+		conf.SourceIDs,
+		egress.WithMaxTimerSeries(conf.MaxSeries),
+	)
+	go aggregator.Start(time.Duration(conf.FlushIntervalSeconds) * time.Second)
+
+	log.Printf("aggregating timers from %d sources", len(conf.SourceIDs))
+	return aggregator
+}
+
+func (a *AppV2) receiverOpts(s ingress.DataSetter) []ingress.ReceiverOption {
+	conf := a.config.RateLimits
+	if conf.Default.Rate <= 0 && len(conf.Sources) == 0 {
+		return nil
+	}
+
+	limits := make(map[string]ingress.RateLimit)
+	for sourceID, l := range conf.Sources {
+		limits[sourceID] = ingress.RateLimit{Rate: l.Rate, Burst: l.Burst}
+	}
+
+	limiter := ingress.NewRateLimiter(
+		s,
+		ingress.RateLimit{Rate: conf.Default.Rate, Burst: conf.Default.Burst},
+		limits,
+	)
+	go limiter.Start(time.Duration(conf.ReportIntervalSeconds) * time.Second)
+
+	return []ingress.ReceiverOption{ingress.WithLimiter(limiter)}
+}
+
+func (a *AppV2) startSyslogIngress(s syslog.DataSetter) {
+	conf := a.config.SyslogIngress
+
+	if conf.UDPPort != 0 {
+		l, err := syslog.NewUDPListener(fmt.Sprintf("127.0.0.1:%d", conf.UDPPort), s)
+		if err != nil {
+			log.Panicf("Failed to listen for syslog (UDP): %s", err)
+		}
+		go l.Start()
+	}
+
+	if conf.TCPPort != 0 {
+		l, err := syslog.NewTCPListener(fmt.Sprintf("127.0.0.1:%d", conf.TCPPort), s)
+		if err != nil {
+			log.Panicf("Failed to listen for syslog (TCP): %s", err)
+		}
+		go l.Start()
+	}
+}
+
+func (a *AppV2) startPrometheusScraper(s prometheus.DataSetter) {
+	conf := a.config.Prometheus
+	if len(conf.Targets) == 0 {
+		return
+	}
+
+	var targets []prometheus.Target
+	for _, t := range conf.Targets {
+		targets = append(targets, prometheus.Target{
+			URL:      t.URL,
+			SourceID: t.SourceID,
+		})
+	}
+
+	log.Printf("scraping %d prometheus targets", len(targets))
+	scraper := prometheus.NewScraper(
+		targets,
+		time.Duration(conf.ScrapeIntervalSeconds)*time.Second,
+		s,
+	)
+	go scraper.Start()
+}
+
+func (a *AppV2) startStatsDListener(s statsd.DataSetter) {
+	conf := a.config.StatsD
+	if conf.UDPPort == 0 {
+		return
+	}
+
+	aggregator := statsd.NewAggregator(conf.SourceID, s)
+	l, err := statsd.NewUDPListener(fmt.Sprintf("127.0.0.1:%d", conf.UDPPort), aggregator)
+	if err != nil {
+		log.Panicf("Failed to listen for statsd: %s", err)
+	}
+	go aggregator.Start(time.Duration(conf.FlushIntervalMilliseconds) * time.Millisecond)
+	go l.Start()
+}
+
 func (a *AppV2) initializePool() *clientpool.ClientPool {
 	if a.clientCreds == nil {
 		log.Panic("Failed to load TLS client config")
 	}
 
-	connector := clientpool.MakeGRPCConnector(
-		a.config.DopplerAddr,
-		a.config.Zone,
-		grpc.Dial,
-		v2.NewDopplerIngressClient,
-		grpc.WithTransportCredentials(a.clientCreds),
-	)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	connector := makeV2Connector(a.config, a.clientCreds)
 
-	var connManagers []clientpool.Conn
+	var (
+		connManagers []clientpool.Conn
+		reporters    []health.ConnReporter
+	)
 	for i := 0; i < 5; i++ {
-		connManagers = append(connManagers, clientpool.NewConnManager(
+		m := clientpool.NewConnManager(
 			connector,
 			10000+rand.Int63n(1000),
 			time.Second,
-		))
+			100,
+			100*time.Millisecond,
+		)
+		connManagers = append(connManagers, m)
+		reporters = append(reporters, m)
+		a.connManagers = append(a.connManagers, m)
 	}
+	a.registry.RegisterConns("v2", true, reporters...)
 
 	return clientpool.New(connManagers...)
 }
+
+// Reload reconnects to doppler using the doppler address, zone and client
+// credentials from the given config. The ingress listeners and anything
+// buffered are left alone.
+func (a *AppV2) Reload(c *Config, clientCreds credentials.TransportCredentials) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	connector := makeV2Connector(c, clientCreds)
+	for _, m := range a.connManagers {
+		m.SetConnector(connector)
+	}
+}
+
+func makeV2Connector(c *Config, creds credentials.TransportCredentials) clientpool.GRPCConnector {
+	return clientpool.MakeGRPCConnector(
+		c.DopplerAddr,
+		c.Zone,
+		grpc.Dial,
+		v2.NewDopplerIngressClient,
+		grpc.WithTransportCredentials(creds),
+	)
+}
+
+func (a *AppV2) initializeSpillWriter(pool *clientpool.ClientPool) egress.Writer {
+	conf := a.config.SpillQueue
+	if conf.Dir == "" {
+		return pool
+	}
+
+	queue, err := egress.NewSegmentQueue(
+		conf.Dir,
+		conf.MaxSizeBytes,
+		conf.SegmentSizeBytes,
+		diodes.AlertFunc(func(missed int) {
+			metric.IncCounter("dropped",
+				metric.WithIncrement(uint64(missed)),
+				metric.WithVersion(2, 0),
+				metric.WithTag("direction", "spill"),
+			)
+			log.Printf("Dropped %d spilled v2 envelopes", missed)
+		}),
+	)
+	if err != nil {
+		log.Panicf("Failed to create spill queue in %s: %s", conf.Dir, err)
+	}
+
+	log.Printf("spilling v2 envelopes to %s when no doppler is reachable", conf.Dir)
+	return egress.NewSpillWriter(pool, queue, time.Second)
+}
@@ -14,27 +14,110 @@ type GRPC struct {
 	KeyFile  string
 }
 
+type SpillQueue struct {
+	Dir              string
+	MaxSizeBytes     int64
+	SegmentSizeBytes int64
+}
+
+type SyslogIngress struct {
+	UDPPort int
+	TCPPort int
+}
+
+type PrometheusTarget struct {
+	URL      string
+	SourceID string
+}
+
+type Prometheus struct {
+	Targets               []PrometheusTarget
+	ScrapeIntervalSeconds uint
+}
+
+type StatsD struct {
+	UDPPort                   int
+	FlushIntervalMilliseconds uint
+	SourceID                  string
+}
+
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimits struct {
+	Default               RateLimit
+	Sources               map[string]RateLimit
+	ReportIntervalSeconds uint
+}
+
+type TimerAggregation struct {
+	SourceIDs            []string
+	FlushIntervalSeconds uint
+	MaxSeries            int
+}
+
+type FilterReplacement struct {
+	Pattern string
+	With    string
+}
+
+type FilterRule struct {
+	Name string
+
+	EnvelopeType string
+	MetricName   string
+	SourceID     string
+	Tags         map[string]string
+
+	Drop         bool
+	Replacements []FilterReplacement
+	AddTags      map[string]string
+	RemoveTags   []string
+}
+
+type CounterAggregator struct {
+	MaxCounters int
+	TTLSeconds  uint
+}
+
 type Config struct {
 	Syslog     string
 	Deployment string
 	Zone       string
 	Job        string
 	Index      string
+	Tags       map[string]string
 
 	DisableUDP      bool
 	IncomingUDPPort int
 
-	GRPC GRPC
+	GRPC       GRPC
+	RateLimits RateLimits
+
+	SyslogIngress SyslogIngress
+	Prometheus    Prometheus
+	StatsD        StatsD
 
 	SharedSecret string // TODO: Delete when UDP is removed
 
 	DopplerAddr    string
 	DopplerAddrUDP string // TODO: Delete when UDP is removed
 
+	SpillQueue SpillQueue
+
+	FilterRules []FilterRule
+
+	CounterAggregator CounterAggregator
+	TimerAggregation  TimerAggregation
+
 	MetricBatchIntervalMilliseconds  uint
 	RuntimeStatsIntervalMilliseconds uint
 
-	PPROFPort uint32
+	PPROFPort  uint32
+	HealthAddr string
+	HealthPort uint32
 }
 
 func ParseConfig(configFile string) (*Config, error) {
@@ -51,6 +134,28 @@ func Parse(reader io.Reader) (*Config, error) {
 	config := &Config{
 		MetricBatchIntervalMilliseconds:  5000,
 		RuntimeStatsIntervalMilliseconds: 15000,
+		HealthAddr:                       "127.0.0.1",
+		SpillQueue: SpillQueue{
+			MaxSizeBytes:     100 * 1024 * 1024,
+			SegmentSizeBytes: 4 * 1024 * 1024,
+		},
+		CounterAggregator: CounterAggregator{
+			MaxCounters: 10000,
+		},
+		TimerAggregation: TimerAggregation{
+			FlushIntervalSeconds: 10,
+			MaxSeries:            10000,
+		},
+		Prometheus: Prometheus{
+			ScrapeIntervalSeconds: 15,
+		},
+		RateLimits: RateLimits{
+			ReportIntervalSeconds: 60,
+		},
+		StatsD: StatsD{
+			FlushIntervalMilliseconds: 10000,
+			SourceID:                  "statsd",
+		},
 	}
 	err := json.NewDecoder(reader).Decode(config)
 	if err != nil {
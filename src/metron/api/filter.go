@@ -0,0 +1,48 @@
+package api
+
+import (
+	"log"
+	"metron/filter"
+	"time"
+)
+
+// StartFilterEngine returns an engine for the configured filter rules, or
+// nil if there are none. The engine is shared by the v1 and v2 apps so that
+// each rule reports a single count of matches.
+func StartFilterEngine(c *Config) *filter.Engine {
+	if len(c.FilterRules) == 0 {
+		return nil
+	}
+
+	var rules []filter.Rule
+	for _, r := range c.FilterRules {
+		var repls []filter.Replacement
+		for _, repl := range r.Replacements {
+			repls = append(repls, filter.Replacement{
+				Pattern: repl.Pattern,
+				With:    repl.With,
+			})
+		}
+
+		rules = append(rules, filter.Rule{
+			Name:         r.Name,
+			EnvelopeType: r.EnvelopeType,
+			MetricName:   r.MetricName,
+			SourceID:     r.SourceID,
+			Tags:         r.Tags,
+			Drop:         r.Drop,
+			Replacements: repls,
+			AddTags:      r.AddTags,
+			RemoveTags:   r.RemoveTags,
+		})
+	}
+
+	engine, err := filter.New(rules)
+	if err != nil {
+		log.Panicf("Invalid filter rules: %s", err)
+	}
+	go engine.Start(time.Duration(c.MetricBatchIntervalMilliseconds) * time.Millisecond)
+
+	log.Printf("filtering envelopes with %d rules", len(rules))
+	return engine
+}
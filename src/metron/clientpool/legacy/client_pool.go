@@ -3,6 +3,7 @@ package legacy
 import (
 	"fmt"
 	"log"
+	"metron/health"
 	"net"
 	"sync"
 	"time"
@@ -47,6 +48,33 @@ func (p *ClientPool) Write(data []byte) error {
 	return nil
 }
 
+// SetDopplerAddr changes the address that the pool writes to. The current
+// connection is closed and the next write dials the new address.
+func (p *ClientPool) SetDopplerAddr(addr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.dopplerAddr = addr
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
+
+// Status reports the state of the UDP connection. The connection is only
+// dialed on the first write after a refresh.
+func (p *ClientPool) Status() health.ConnStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return health.ConnStatus{
+		Connected: p.conn != nil,
+		Doppler:   p.dopplerAddr,
+		Writes:    int64(p.writeCount),
+		MaxWrites: int64(p.maxWriteCount),
+	}
+}
+
 func (p *ClientPool) fetchConn() *net.UDPConn {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -42,6 +42,32 @@ var _ = Describe("ClientPool", func() {
 		Expect(m).To(HaveLen(1))
 	})
 
+	It("reports the connection status", func() {
+		Expect(pool.Status().Connected).To(BeFalse())
+
+		pool.Write([]byte("some-data"))
+
+		status := pool.Status()
+		Expect(status.Connected).To(BeTrue())
+		Expect(status.Doppler).To(Equal(conn.LocalAddr().String()))
+		Expect(status.MaxWrites).To(Equal(int64(10)))
+	})
+
+	It("writes to a new doppler address", func() {
+		addr, _ := net.ResolveUDPAddr("udp4", "localhost:0")
+		newConn, err := net.ListenUDP("udp4", addr)
+		Expect(err).ToNot(HaveOccurred())
+		defer newConn.Close()
+
+		pool.Write([]byte("some-data"))
+		pool.SetDopplerAddr(newConn.LocalAddr().String())
+
+		Expect(pool.Status().Connected).To(BeFalse())
+		cs, _ := readFromUDP(newConn)
+		pool.Write([]byte("some-data"))
+		Eventually(cs).Should(HaveLen(1))
+	})
+
 	It("refreshes the connection after maxWrites is hit", func() {
 		_, addrs := readFromUDP(conn)
 		for i := 0; i < 15; i++ {
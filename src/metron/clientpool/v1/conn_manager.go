@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"metron/health"
 	"plumbing"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -26,14 +28,16 @@ type ConnManager struct {
 	conn         unsafe.Pointer
 	maxWrites    int64
 	pollDuration time.Duration
-	connector    Connector
+	connector    unsafe.Pointer
+
+	connectMu sync.Mutex
 }
 
 func NewConnManager(c Connector, maxWrites int64, pollDuration time.Duration) *ConnManager {
 	m := &ConnManager{
 		maxWrites:    maxWrites,
 		pollDuration: pollDuration,
-		connector:    c,
+		connector:    unsafe.Pointer(&c),
 	}
 	go m.maintainConn()
 	return m
@@ -54,20 +58,46 @@ func (m *ConnManager) Write(data []byte) error {
 	// induce an error from the stream via the test
 	if err != nil {
 		log.Printf("error writing to doppler %s: %s", gRPCConn.name, err)
-		atomic.StorePointer(&m.conn, nil)
-		gRPCConn.closer.Close()
+		m.release(conn)
 		return err
 	}
 
 	if atomic.AddInt64(&gRPCConn.writes, 1) >= m.maxWrites {
 		log.Printf("recycling connection to doppler %s after %d writes", gRPCConn.name, m.maxWrites)
-		atomic.StorePointer(&m.conn, nil)
-		gRPCConn.closer.Close()
+		m.release(conn)
 	}
 
 	return nil
 }
 
+// release closes the given connection unless it has already been replaced.
+// A replaced connection is closed by connect.
+func (m *ConnManager) release(conn unsafe.Pointer) {
+	if atomic.CompareAndSwapPointer(&m.conn, conn, nil) {
+		(*grpcConn)(conn).closer.Close()
+	}
+}
+
+// Status reports whether the ConnManager currently has a connection and how
+// many writes it has made on it.
+func (m *ConnManager) Status() health.ConnStatus {
+	s := health.ConnStatus{
+		Doppler:   fmt.Sprintf("%s", m.loadConnector()),
+		MaxWrites: m.maxWrites,
+	}
+
+	conn := atomic.LoadPointer(&m.conn)
+	if conn == nil || (*grpcConn)(conn) == nil {
+		return s
+	}
+
+	gRPCConn := (*grpcConn)(conn)
+	s.Connected = true
+	s.Doppler = gRPCConn.name
+	s.Writes = atomic.LoadInt64(&gRPCConn.writes)
+	return s
+}
+
 func (m *ConnManager) maintainConn() {
 	for range time.Tick(m.pollDuration) {
 		conn := atomic.LoadPointer(&m.conn)
@@ -75,16 +105,47 @@ func (m *ConnManager) maintainConn() {
 			continue
 		}
 
-		closer, pusherClient, err := m.connector.Connect()
-		if err != nil {
-			log.Printf("error dialing doppler %s: %s", m.connector, err)
-			continue
-		}
+		m.connect(false)
+	}
+}
+
+// SetConnector replaces the connector and reconnects with it. If the new
+// connector fails to connect, the current connection is kept until it is
+// recycled.
+func (m *ConnManager) SetConnector(c Connector) {
+	atomic.StorePointer(&m.connector, unsafe.Pointer(&c))
+	m.connect(true)
+}
+
+// connect dials doppler. An existing connection is only replaced when
+// replace is true.
+func (m *ConnManager) connect(replace bool) {
+	m.connectMu.Lock()
+	defer m.connectMu.Unlock()
+
+	conn := atomic.LoadPointer(&m.conn)
+	if !replace && conn != nil && (*grpcConn)(conn) != nil {
+		return
+	}
 
-		atomic.StorePointer(&m.conn, unsafe.Pointer(&grpcConn{
-			name:   fmt.Sprintf("%s", m.connector),
-			client: pusherClient,
-			closer: closer,
-		}))
+	connector := m.loadConnector()
+	closer, pusherClient, err := connector.Connect()
+	if err != nil {
+		log.Printf("error dialing doppler %s: %s", connector, err)
+		return
 	}
+
+	old := atomic.SwapPointer(&m.conn, unsafe.Pointer(&grpcConn{
+		name:   fmt.Sprintf("%s", connector),
+		client: pusherClient,
+		closer: closer,
+	}))
+
+	if old != nil && (*grpcConn)(old) != nil {
+		(*grpcConn)(old).closer.Close()
+	}
+}
+
+func (m *ConnManager) loadConnector() Connector {
+	return *(*Connector)(atomic.LoadPointer(&m.connector))
 }
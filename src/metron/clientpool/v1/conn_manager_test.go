@@ -52,6 +52,40 @@ var _ = Describe("ConnManager", func() {
 				)))
 			})
 
+			It("reports the connection status", func() {
+				f := func() bool {
+					return connManager.Status().Connected
+				}
+				Eventually(f).Should(BeTrue())
+				Expect(connManager.Write([]byte("some-data"))).To(Succeed())
+
+				status := connManager.Status()
+				Expect(status.Writes).To(Equal(int64(1)))
+				Expect(status.MaxWrites).To(Equal(int64(5)))
+			})
+
+			It("reconnects with a new connector", func() {
+				f := func() bool {
+					return connManager.Status().Connected
+				}
+				Eventually(f).Should(BeTrue())
+
+				newConnector := newMockConnector()
+				newPusherClient := newMockDopplerIngestor_PusherClient()
+				close(newPusherClient.SendOutput.Ret0)
+				newConnector.ConnectOutput.Ret0 <- newMockCloser()
+				newConnector.ConnectOutput.Ret1 <- newPusherClient
+				newConnector.ConnectOutput.Ret2 <- nil
+				mockCloser.CloseOutput.Ret0 <- nil
+
+				connManager.SetConnector(newConnector)
+
+				Expect(mockCloser.CloseCalled).To(HaveLen(1))
+				Expect(connManager.Write([]byte("some-data"))).To(Succeed())
+				Expect(newPusherClient.SendCalled).To(HaveLen(1))
+				Expect(mockPusherClient.SendCalled).To(BeEmpty())
+			})
+
 			Describe("connection recycling", func() {
 				BeforeEach(func() {
 					close(mockCloser.CloseOutput.Ret0)
@@ -91,6 +125,36 @@ var _ = Describe("ConnManager", func() {
 				Expect(mockCloser.CloseCalled).To(HaveLen(1))
 			})
 		})
+
+		Context("when the connection is replaced during a failing Send()", func() {
+			It("keeps the new connection", func() {
+				f := func() bool {
+					return connManager.Status().Connected
+				}
+				Eventually(f).Should(BeTrue())
+
+				errs := make(chan error)
+				go func() {
+					errs <- connManager.Write([]byte("some-data"))
+				}()
+				Eventually(mockPusherClient.SendCalled).Should(Receive())
+
+				newConnector := newMockConnector()
+				newCloser := newMockCloser()
+				newConnector.ConnectOutput.Ret0 <- newCloser
+				newConnector.ConnectOutput.Ret1 <- newMockDopplerIngestor_PusherClient()
+				newConnector.ConnectOutput.Ret2 <- nil
+				close(mockCloser.CloseOutput.Ret0)
+				connManager.SetConnector(newConnector)
+
+				mockPusherClient.SendOutput.Ret0 <- errors.New("some-error")
+				Eventually(errs).Should(Receive(HaveOccurred()))
+
+				Expect(connManager.Status().Connected).To(BeTrue())
+				Expect(mockCloser.CloseCalled).To(HaveLen(1))
+				Expect(newCloser.CloseCalled).To(BeEmpty())
+			})
+		})
 	})
 
 	Context("when a connection is not able to be established", func() {
@@ -106,5 +170,12 @@ var _ = Describe("ConnManager", func() {
 			}
 			Consistently(f).Should(HaveOccurred())
 		})
+
+		It("reports that it is not connected", func() {
+			f := func() bool {
+				return connManager.Status().Connected
+			}
+			Consistently(f).Should(BeFalse())
+		})
 	})
 })
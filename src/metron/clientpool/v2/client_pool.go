@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math/rand"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
 	plumbing "plumbing/v2"
@@ -13,13 +14,39 @@ type Conn interface {
 	Write(data *plumbing.Envelope) (err error)
 }
 
+// statser is implemented by conns that track how they have behaved
+// recently. Conns that do not are given zero stats.
+type statser interface {
+	Stats() ConnStats
+}
+
+// orderRefreshWrites is the number of writes between asking the strategy for
+// a new order. In between, writes reuse the last order.
+const orderRefreshWrites = 100
+
 type ClientPool struct {
-	conns []unsafe.Pointer
+	// writes is first to keep it 64-bit aligned for atomic operations.
+	writes uint64
+	order  unsafe.Pointer
+
+	conns    []unsafe.Pointer
+	strategy Strategy
 }
 
+// New returns a ClientPool that weights its conns with a WeightedStrategy.
 func New(conns ...Conn) *ClientPool {
+	return NewWithStrategy(
+		NewWeightedStrategy(rand.NewSource(time.Now().UnixNano())),
+		conns...,
+	)
+}
+
+// NewWithStrategy returns a ClientPool that asks the given Strategy which
+// conns to try. The order is refreshed every orderRefreshWrites writes.
+func NewWithStrategy(s Strategy, conns ...Conn) *ClientPool {
 	pool := &ClientPool{
-		conns: make([]unsafe.Pointer, len(conns)),
+		conns:    make([]unsafe.Pointer, len(conns)),
+		strategy: s,
 	}
 
 	for i := range conns {
@@ -30,11 +57,8 @@ func New(conns ...Conn) *ClientPool {
 }
 
 func (c *ClientPool) Write(msg *plumbing.Envelope) error {
-	seed := rand.Int()
-	for i := range c.conns {
-		idx := (i + seed) % len(c.conns)
+	for _, idx := range c.loadOrder() {
 		conn := *(*Conn)(atomic.LoadPointer(&c.conns[idx]))
-
 		if err := conn.Write(msg); err == nil {
 			return nil
 		}
@@ -42,3 +66,29 @@ func (c *ClientPool) Write(msg *plumbing.Envelope) error {
 
 	return errors.New("unable to write to any dopplers")
 }
+
+// loadOrder returns the order in which to try the conns, asking the strategy
+// for a new one on the first write and every orderRefreshWrites after.
+func (c *ClientPool) loadOrder() []int {
+	order := atomic.LoadPointer(&c.order)
+	if order != nil && atomic.AddUint64(&c.writes, 1)%orderRefreshWrites != 0 {
+		return *(*[]int)(order)
+	}
+
+	o := c.strategy.Order(c.Stats())
+	atomic.StorePointer(&c.order, unsafe.Pointer(&o))
+	return o
+}
+
+// Stats returns the recent behavior of each conn, in the order the conns
+// were given to the pool.
+func (c *ClientPool) Stats() []ConnStats {
+	stats := make([]ConnStats, len(c.conns))
+	for i := range c.conns {
+		conn := *(*Conn)(atomic.LoadPointer(&c.conns[i]))
+		if s, ok := conn.(statser); ok {
+			stats[i] = s.Stats()
+		}
+	}
+	return stats
+}
@@ -76,8 +76,88 @@ var _ = Describe("ClientPool", func() {
 			})
 		})
 	})
+
+	Describe("Stats()", func() {
+		It("reports the stats of conns that track them", func() {
+			statsConn := newMockStatsConn()
+			statsConn.StatsOutput.Ret0 <- clientpool.ConnStats{
+				InZone:    true,
+				ErrorRate: 0.5,
+			}
+			pool = clientpool.New(statsConn, mockConns[0])
+
+			Expect(pool.Stats()).To(Equal([]clientpool.ConnStats{
+				{InZone: true, ErrorRate: 0.5},
+				{},
+			}))
+		})
+	})
+
+	Context("with a strategy", func() {
+		var (
+			strategy *mockStrategy
+		)
+
+		BeforeEach(func() {
+			strategy = newMockStrategy()
+			pool = clientpool.NewWithStrategy(strategy, toConns(mockConns)...)
+		})
+
+		It("passes the stats of every conn to the strategy", func() {
+			strategy.OrderOutput.Ret0 <- []int{2}
+			mockConns[2].WriteOutput.Err <- nil
+
+			pool.Write(&plumbing.Envelope{SourceId: "some-uuid"})
+
+			Expect(strategy.OrderInput.Stats).To(Receive(HaveLen(5)))
+		})
+
+		It("reuses the order between refreshes", func() {
+			strategy.OrderOutput.Ret0 <- []int{2}
+			for i := 0; i < 3; i++ {
+				mockConns[2].WriteOutput.Err <- nil
+				Expect(pool.Write(&plumbing.Envelope{SourceId: "some-uuid"})).To(Succeed())
+			}
+
+			Expect(strategy.OrderCalled).To(HaveLen(1))
+			Expect(mockConns[2].WriteCalled).To(HaveLen(3))
+		})
+
+		It("tries conns in the order given by the strategy", func() {
+			strategy.OrderOutput.Ret0 <- []int{3, 1}
+			mockConns[3].WriteOutput.Err <- fmt.Errorf("some-error")
+			mockConns[1].WriteOutput.Err <- nil
+
+			Expect(pool.Write(&plumbing.Envelope{SourceId: "some-uuid"})).To(Succeed())
+
+			Expect(mockConns[3].WriteCalled).To(HaveLen(1))
+			Expect(mockConns[1].WriteCalled).To(HaveLen(1))
+			for _, i := range []int{0, 2, 4} {
+				Expect(mockConns[i].WriteCalled).To(BeEmpty())
+			}
+		})
+
+		It("only tries the conns given by the strategy", func() {
+			strategy.OrderOutput.Ret0 <- []int{4}
+			mockConns[4].WriteOutput.Err <- fmt.Errorf("some-error")
+
+			Expect(pool.Write(&plumbing.Envelope{SourceId: "some-uuid"})).ToNot(Succeed())
+
+			for _, c := range mockConns[:4] {
+				Expect(c.WriteCalled).To(BeEmpty())
+			}
+		})
+	})
 })
 
+func toConns(mockConns []*mockConn) []clientpool.Conn {
+	var conns []clientpool.Conn
+	for _, c := range mockConns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
 func chooseData(conns []*mockConn) (idx int, value *plumbing.Envelope) {
 	var cases []reflect.SelectCase
 	for _, c := range conns {
@@ -5,67 +5,223 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"metric"
+	"metron/health"
 	plumbing "plumbing/v2"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
 )
 
 type Connector interface {
-	Connect() (io.Closer, plumbing.DopplerIngress_SenderClient, error)
+	Connect() (io.Closer, plumbing.DopplerIngress_BatchSenderClient, bool, error)
 }
 
 type v2GRPCConn struct {
 	name   string
-	client plumbing.DopplerIngress_SenderClient
+	client plumbing.DopplerIngress_BatchSenderClient
 	closer io.Closer
 	writes int64
+	inZone bool
 }
 
+// maxPendingBatches is the number of batches a ConnManager holds on to while
+// it is unable to send them. Beyond that the oldest envelopes are dropped.
+const maxPendingBatches = 10
+
 type ConnManager struct {
-	conn         unsafe.Pointer
-	maxWrites    int64
-	pollDuration time.Duration
-	connector    Connector
+	conn          unsafe.Pointer
+	maxWrites     int64
+	pollDuration  time.Duration
+	connector     unsafe.Pointer
+	batchSize     int
+	flushInterval time.Duration
+
+	mu    sync.Mutex
+	batch []*plumbing.Envelope
+
+	// sendMu serializes sends as a stream may not be sent on concurrently.
+	sendMu sync.Mutex
+	stats  connStats
+
+	connectMu sync.Mutex
 }
 
-func NewConnManager(c Connector, maxWrites int64, pollDuration time.Duration) *ConnManager {
+// NewConnManager returns a ConnManager that batches envelopes written to it.
+// A batch is sent to doppler once it holds batchSize envelopes or when
+// flushInterval has elapsed, whichever happens first.
+func NewConnManager(
+	c Connector,
+	maxWrites int64,
+	pollDuration time.Duration,
+	batchSize int,
+	flushInterval time.Duration,
+) *ConnManager {
 	m := &ConnManager{
-		maxWrites:    maxWrites,
-		pollDuration: pollDuration,
-		connector:    c,
+		maxWrites:     maxWrites,
+		pollDuration:  pollDuration,
+		connector:     unsafe.Pointer(&c),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		batch:         make([]*plumbing.Envelope, 0, batchSize),
 	}
 	go m.maintainConn()
+	go m.flushPeriodically()
 	return m
 }
 
+// Write adds the envelope to the current batch and sends the batch once it
+// is full. If the batch can not be sent, Write returns an error for the
+// given envelope and keeps the rest of the batch to send once it is
+// reconnected.
 func (m *ConnManager) Write(envelope *plumbing.Envelope) error {
 	conn := atomic.LoadPointer(&m.conn)
 	if conn == nil || (*v2GRPCConn)(conn) == nil {
 		return errors.New("no connection to doppler present")
 	}
 
-	gRPCConn := (*v2GRPCConn)(conn)
-	err := gRPCConn.client.Send(envelope)
+	m.mu.Lock()
+	m.batch = append(m.batch, envelope)
+	if len(m.batch) < m.batchSize {
+		m.mu.Unlock()
+		return nil
+	}
+	batch := m.takeBatch()
+	m.mu.Unlock()
+
+	if err := m.send((*v2GRPCConn)(conn), batch); err != nil {
+		m.requeue(batch[:len(batch)-1])
+		return err
+	}
+
+	return nil
+}
+
+func (m *ConnManager) flushPeriodically() {
+	for range time.Tick(m.flushInterval) {
+		conn := atomic.LoadPointer(&m.conn)
+		if conn == nil || (*v2GRPCConn)(conn) == nil {
+			continue
+		}
+
+		m.mu.Lock()
+		batch := m.takeBatch()
+		m.mu.Unlock()
+
+		if len(batch) == 0 {
+			continue
+		}
+
+		if err := m.send((*v2GRPCConn)(conn), batch); err != nil {
+			m.requeue(batch)
+		}
+	}
+}
+
+// takeBatch returns the pending batch and starts a new one. It must be
+// called with m.mu held.
+func (m *ConnManager) takeBatch() []*plumbing.Envelope {
+	batch := m.batch
+	m.batch = make([]*plumbing.Envelope, 0, m.batchSize)
+	return batch
+}
+
+// requeue puts envelopes that could not be sent in front of the pending
+// batch. When too many are pending, the oldest are dropped.
+func (m *ConnManager) requeue(envelopes []*plumbing.Envelope) {
+	if len(envelopes) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	m.batch = append(envelopes, m.batch...)
+	dropped := len(m.batch) - maxPendingBatches*m.batchSize
+	if dropped > 0 {
+		m.batch = m.batch[dropped:]
+	}
+	m.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("dropped %d envelopes that could not be sent to doppler", dropped)
+		metric.IncCounter("dropped",
+			metric.WithIncrement(uint64(dropped)),
+			metric.WithVersion(2, 0),
+			metric.WithTag("direction", "egress"),
+		)
+	}
+}
+
+// send sends the batch down the given connection.
+func (m *ConnManager) send(gRPCConn *v2GRPCConn, batch []*plumbing.Envelope) error {
+	m.sendMu.Lock()
+	start := time.Now()
+	err := gRPCConn.client.Send(&plumbing.EnvelopeBatch{Batch: batch})
+	m.stats.record(err, time.Since(start))
+	m.sendMu.Unlock()
 
 	// TODO: This block is untested because we don't know how to
 	// induce an error from the stream via the test
 	if err != nil {
 		log.Printf("error writing to doppler %s: %s", gRPCConn.name, err)
-		atomic.StorePointer(&m.conn, nil)
-		gRPCConn.closer.Close()
+		m.release(gRPCConn)
 		return err
 	}
 
-	if atomic.AddInt64(&gRPCConn.writes, 1) >= m.maxWrites {
+	if atomic.AddInt64(&gRPCConn.writes, int64(len(batch))) >= m.maxWrites {
 		log.Printf("recycling connection to doppler %s after %d writes", gRPCConn.name, m.maxWrites)
-		atomic.StorePointer(&m.conn, nil)
-		gRPCConn.closer.Close()
+		m.release(gRPCConn)
 	}
 
 	return nil
 }
 
+// release closes the given connection unless it has already been replaced.
+// A replaced connection is closed by connect.
+func (m *ConnManager) release(gRPCConn *v2GRPCConn) {
+	if atomic.CompareAndSwapPointer(&m.conn, unsafe.Pointer(gRPCConn), nil) {
+		gRPCConn.closer.Close()
+	}
+}
+
+// Status reports whether the ConnManager currently has a connection and how
+// many writes it has made on it.
+func (m *ConnManager) Status() health.ConnStatus {
+	s := health.ConnStatus{
+		Doppler:   fmt.Sprintf("%s", m.loadConnector()),
+		MaxWrites: m.maxWrites,
+	}
+
+	conn := atomic.LoadPointer(&m.conn)
+	if conn == nil || (*v2GRPCConn)(conn) == nil {
+		return s
+	}
+
+	gRPCConn := (*v2GRPCConn)(conn)
+	s.Connected = true
+	s.Doppler = gRPCConn.name
+	s.Writes = atomic.LoadInt64(&gRPCConn.writes)
+	return s
+}
+
+// InZone reports whether the ConnManager is currently connected to a doppler
+// in its own zone.
+func (m *ConnManager) InZone() bool {
+	conn := atomic.LoadPointer(&m.conn)
+	if conn == nil || (*v2GRPCConn)(conn) == nil {
+		return false
+	}
+	return (*v2GRPCConn)(conn).inZone
+}
+
+// Stats reports how sends to doppler have behaved recently and whether the
+// ConnManager is connected to a doppler in its own zone.
+func (m *ConnManager) Stats() ConnStats {
+	s := m.stats.snapshot()
+	s.InZone = m.InZone()
+	return s
+}
+
 func (m *ConnManager) maintainConn() {
 	for range time.Tick(m.pollDuration) {
 		conn := atomic.LoadPointer(&m.conn)
@@ -73,16 +229,80 @@ func (m *ConnManager) maintainConn() {
 			continue
 		}
 
-		closer, pusherClient, err := m.connector.Connect()
-		if err != nil {
-			log.Printf("error dialing doppler %s: %s", m.connector, err)
-			continue
-		}
+		m.connect(false)
+	}
+}
+
+// SetConnector replaces the connector and reconnects with it. Envelopes that
+// are waiting to be batched are sent on the new connection. If the new
+// connector fails to connect, the current connection is kept until it is
+// recycled.
+func (m *ConnManager) SetConnector(c Connector) {
+	atomic.StorePointer(&m.connector, unsafe.Pointer(&c))
+	m.connect(true)
+}
+
+// connect dials doppler. An existing connection is only replaced when
+// replace is true.
+func (m *ConnManager) connect(replace bool) {
+	m.connectMu.Lock()
+	defer m.connectMu.Unlock()
+
+	conn := atomic.LoadPointer(&m.conn)
+	if !replace && conn != nil && (*v2GRPCConn)(conn) != nil {
+		return
+	}
+
+	connector := m.loadConnector()
+	closer, pusherClient, inZone, err := connector.Connect()
+	if err != nil {
+		log.Printf("error dialing doppler %s: %s", connector, err)
+		return
+	}
+
+	old := atomic.SwapPointer(&m.conn, unsafe.Pointer(&v2GRPCConn{
+		name:   fmt.Sprintf("%s", connector),
+		client: pusherClient,
+		closer: closer,
+		inZone: inZone,
+	}))
+
+	if old != nil && (*v2GRPCConn)(old) != nil {
+		(*v2GRPCConn)(old).closer.Close()
+	}
+}
+
+func (m *ConnManager) loadConnector() Connector {
+	return *(*Connector)(atomic.LoadPointer(&m.connector))
+}
+
+// statsDecay is the weight given to each new send when updating the moving
+// averages.
+const statsDecay = 0.1
+
+type connStats struct {
+	mu        sync.Mutex
+	errorRate float64
+	latency   float64
+}
+
+func (s *connStats) record(err error, d time.Duration) {
+	var failed float64
+	if err != nil {
+		failed = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errorRate += statsDecay * (failed - s.errorRate)
+	s.latency += statsDecay * (float64(d) - s.latency)
+}
 
-		atomic.StorePointer(&m.conn, unsafe.Pointer(&v2GRPCConn{
-			name:   fmt.Sprintf("%s", m.connector),
-			client: pusherClient,
-			closer: closer,
-		}))
+func (s *connStats) snapshot() ConnStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ConnStats{
+		ErrorRate: s.errorRate,
+		Latency:   time.Duration(s.latency),
 	}
 }
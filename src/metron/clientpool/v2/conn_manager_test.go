@@ -16,21 +16,22 @@ var _ = Describe("ConnManager", func() {
 		connManager      *clientpool.ConnManager
 		mockConnector    *mockV2Connector
 		mockCloser       *mockCloser
-		mockSenderClient *mockDopplerIngress_SenderClient
+		mockSenderClient *mockDopplerIngress_BatchSenderClient
 	)
 
 	BeforeEach(func() {
 		mockConnector = newMockV2Connector()
-		connManager = clientpool.NewConnManager(mockConnector, 5, time.Millisecond)
+		connManager = clientpool.NewConnManager(mockConnector, 5, time.Millisecond, 1, time.Hour)
 		mockCloser = newMockCloser()
-		mockSenderClient = newMockDopplerIngress_SenderClient()
+		mockSenderClient = newMockDopplerIngress_BatchSenderClient()
 	})
 
 	Context("when a connection is able to be established", func() {
 		BeforeEach(func() {
 			mockConnector.ConnectOutput.Ret0 <- mockCloser
 			mockConnector.ConnectOutput.Ret1 <- mockSenderClient
-			mockConnector.ConnectOutput.Ret2 <- nil
+			mockConnector.ConnectOutput.Ret2 <- true
+			mockConnector.ConnectOutput.Ret3 <- nil
 		})
 
 		Context("when Send() does not return an error", func() {
@@ -46,16 +47,72 @@ var _ = Describe("ConnManager", func() {
 				Eventually(f).Should(Succeed())
 
 				Eventually(mockSenderClient.SendInput.Arg0).Should(Receive(Equal(
-					&plumbing.Envelope{SourceId: "some-uuid"},
+					&plumbing.EnvelopeBatch{
+						Batch: []*plumbing.Envelope{{SourceId: "some-uuid"}},
+					},
 				)))
 			})
 
+			It("reports the connection status", func() {
+				f := func() bool {
+					return connManager.Status().Connected
+				}
+				Eventually(f).Should(BeTrue())
+				Expect(connManager.Write(&plumbing.Envelope{SourceId: "some-uuid"})).To(Succeed())
+
+				status := connManager.Status()
+				Expect(status.Writes).To(Equal(int64(1)))
+				Expect(status.MaxWrites).To(Equal(int64(5)))
+			})
+
+			It("reports whether the doppler is in the zone", func() {
+				Eventually(connManager.InZone).Should(BeTrue())
+			})
+
+			It("reconnects with a new connector", func() {
+				Eventually(connManager.InZone).Should(BeTrue())
+
+				newConnector := newMockV2Connector()
+				newSenderClient := newMockDopplerIngress_BatchSenderClient()
+				close(newSenderClient.SendOutput.Ret0)
+				newConnector.ConnectOutput.Ret0 <- newMockCloser()
+				newConnector.ConnectOutput.Ret1 <- newSenderClient
+				newConnector.ConnectOutput.Ret2 <- false
+				newConnector.ConnectOutput.Ret3 <- nil
+				mockCloser.CloseOutput.Ret0 <- nil
+
+				connManager.SetConnector(newConnector)
+
+				Expect(mockCloser.CloseCalled).To(HaveLen(1))
+				Expect(connManager.InZone()).To(BeFalse())
+				Expect(connManager.Write(&plumbing.Envelope{SourceId: "some-uuid"})).To(Succeed())
+				Expect(newSenderClient.SendCalled).To(HaveLen(1))
+				Expect(mockSenderClient.SendCalled).To(BeEmpty())
+			})
+
+			It("keeps the connection when the new connector fails", func() {
+				Eventually(connManager.InZone).Should(BeTrue())
+
+				newConnector := newMockV2Connector()
+				close(newConnector.ConnectOutput.Ret0)
+				close(newConnector.ConnectOutput.Ret1)
+				close(newConnector.ConnectOutput.Ret2)
+				testhelpers.AlwaysReturn(newConnector.ConnectOutput.Ret3, errors.New("some-error"))
+
+				connManager.SetConnector(newConnector)
+
+				Expect(mockCloser.CloseCalled).To(BeEmpty())
+				Expect(connManager.Write(&plumbing.Envelope{SourceId: "some-uuid"})).To(Succeed())
+				Expect(mockSenderClient.SendCalled).To(HaveLen(1))
+			})
+
 			Describe("connection recycling", func() {
 				BeforeEach(func() {
 					close(mockCloser.CloseOutput.Ret0)
 					mockConnector.ConnectOutput.Ret0 <- mockCloser
 					mockConnector.ConnectOutput.Ret1 <- mockSenderClient
-					mockConnector.ConnectOutput.Ret2 <- nil
+					mockConnector.ConnectOutput.Ret2 <- true
+					mockConnector.ConnectOutput.Ret3 <- nil
 				})
 
 				It("recycles the connections after max writes", func() {
@@ -88,6 +145,127 @@ var _ = Describe("ConnManager", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(mockCloser.CloseCalled).To(HaveLen(1))
 			})
+
+			It("tracks the error rate of sends", func() {
+				Expect(connManager.Stats().ErrorRate).To(BeZero())
+
+				connManager.Write(&plumbing.Envelope{SourceId: "some-uuid"})
+
+				Expect(connManager.Stats().ErrorRate).To(BeNumerically(">", 0))
+			})
+		})
+
+		Context("when the connection is replaced during a failing Send()", func() {
+			It("keeps the new connection", func() {
+				Eventually(connManager.InZone).Should(BeTrue())
+
+				errs := make(chan error)
+				go func() {
+					errs <- connManager.Write(&plumbing.Envelope{SourceId: "some-uuid"})
+				}()
+				Eventually(mockSenderClient.SendCalled).Should(Receive())
+
+				newConnector := newMockV2Connector()
+				newCloser := newMockCloser()
+				newConnector.ConnectOutput.Ret0 <- newCloser
+				newConnector.ConnectOutput.Ret1 <- newMockDopplerIngress_BatchSenderClient()
+				newConnector.ConnectOutput.Ret2 <- true
+				newConnector.ConnectOutput.Ret3 <- nil
+				close(mockCloser.CloseOutput.Ret0)
+				connManager.SetConnector(newConnector)
+
+				mockSenderClient.SendOutput.Ret0 <- errors.New("some-error")
+				Eventually(errs).Should(Receive(HaveOccurred()))
+
+				Expect(connManager.Status().Connected).To(BeTrue())
+				Expect(mockCloser.CloseCalled).To(HaveLen(1))
+				Expect(newCloser.CloseCalled).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("batching", func() {
+		BeforeEach(func() {
+			mockConnector = newMockV2Connector()
+			mockConnector.ConnectOutput.Ret0 <- mockCloser
+			mockConnector.ConnectOutput.Ret1 <- mockSenderClient
+			mockConnector.ConnectOutput.Ret2 <- true
+			mockConnector.ConnectOutput.Ret3 <- nil
+			close(mockSenderClient.SendOutput.Ret0)
+		})
+
+		It("sends a batch once it is full", func() {
+			connManager = clientpool.NewConnManager(mockConnector, 100, time.Millisecond, 3, time.Hour)
+			e := &plumbing.Envelope{SourceId: "some-uuid"}
+			Eventually(func() error {
+				return connManager.Write(e)
+			}).Should(Succeed())
+			Expect(connManager.Write(e)).To(Succeed())
+			Expect(mockSenderClient.SendCalled).To(BeEmpty())
+
+			Expect(connManager.Write(e)).To(Succeed())
+			Expect(mockSenderClient.SendInput.Arg0).To(Receive(Equal(
+				&plumbing.EnvelopeBatch{
+					Batch: []*plumbing.Envelope{e, e, e},
+				},
+			)))
+		})
+
+		It("sends a partial batch after the flush interval", func() {
+			connManager = clientpool.NewConnManager(mockConnector, 100, time.Millisecond, 100, 10*time.Millisecond)
+			e := &plumbing.Envelope{SourceId: "some-uuid"}
+			Eventually(func() error {
+				return connManager.Write(e)
+			}).Should(Succeed())
+
+			Eventually(mockSenderClient.SendInput.Arg0).Should(Receive(Equal(
+				&plumbing.EnvelopeBatch{
+					Batch: []*plumbing.Envelope{e},
+				},
+			)))
+		})
+	})
+
+	Describe("when a batch can not be sent", func() {
+		var retrySenderClient *mockDopplerIngress_BatchSenderClient
+
+		BeforeEach(func() {
+			mockConnector = newMockV2Connector()
+			close(mockCloser.CloseOutput.Ret0)
+			mockSenderClient.SendOutput.Ret0 <- errors.New("some-error")
+			mockConnector.ConnectOutput.Ret0 <- mockCloser
+			mockConnector.ConnectOutput.Ret1 <- mockSenderClient
+			mockConnector.ConnectOutput.Ret2 <- true
+			mockConnector.ConnectOutput.Ret3 <- nil
+
+			retrySenderClient = newMockDopplerIngress_BatchSenderClient()
+			close(retrySenderClient.SendOutput.Ret0)
+			mockConnector.ConnectOutput.Ret0 <- newMockCloser()
+			mockConnector.ConnectOutput.Ret1 <- retrySenderClient
+			mockConnector.ConnectOutput.Ret2 <- true
+			mockConnector.ConnectOutput.Ret3 <- nil
+
+			connManager = clientpool.NewConnManager(mockConnector, 100, time.Millisecond, 2, time.Hour)
+		})
+
+		It("fails the envelope that filled the batch and resends the rest", func() {
+			e1 := &plumbing.Envelope{SourceId: "some-uuid-1"}
+			e2 := &plumbing.Envelope{SourceId: "some-uuid-2"}
+			e3 := &plumbing.Envelope{SourceId: "some-uuid-3"}
+
+			Eventually(func() error {
+				return connManager.Write(e1)
+			}).Should(Succeed())
+			Expect(connManager.Write(e2)).ToNot(Succeed())
+
+			Eventually(func() error {
+				return connManager.Write(e3)
+			}).Should(Succeed())
+			Expect(retrySenderClient.SendInput.Arg0).To(Receive(Equal(
+				&plumbing.EnvelopeBatch{
+					Batch: []*plumbing.Envelope{e1, e3},
+				},
+			)))
 		})
 	})
 
@@ -95,7 +273,8 @@ var _ = Describe("ConnManager", func() {
 		BeforeEach(func() {
 			close(mockConnector.ConnectOutput.Ret0)
 			close(mockConnector.ConnectOutput.Ret1)
-			testhelpers.AlwaysReturn(mockConnector.ConnectOutput.Ret2, errors.New("some-error"))
+			close(mockConnector.ConnectOutput.Ret2)
+			testhelpers.AlwaysReturn(mockConnector.ConnectOutput.Ret3, errors.New("some-error"))
 		})
 
 		It("always returns an error", func() {
@@ -104,5 +283,13 @@ var _ = Describe("ConnManager", func() {
 			}
 			Consistently(f).Should(HaveOccurred())
 		})
+
+		It("reports that it is not connected", func() {
+			f := func() bool {
+				return connManager.Status().Connected
+			}
+			Consistently(f).Should(BeFalse())
+			Expect(connManager.InZone()).To(BeFalse())
+		})
 	})
 })
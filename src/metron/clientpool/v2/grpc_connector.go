@@ -8,6 +8,7 @@ import (
 	plumbing "plumbing/v2"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 type GRPCConnector struct {
@@ -38,22 +39,25 @@ func MakeGRPCConnector(
 	}
 }
 
-func (c GRPCConnector) Connect() (io.Closer, plumbing.DopplerIngress_SenderClient, error) {
+// Connect dials a doppler in the connector's zone and falls back to any
+// doppler. The returned bool reports whether the doppler is in the zone.
+func (c GRPCConnector) Connect() (io.Closer, plumbing.DopplerIngress_BatchSenderClient, bool, error) {
 	closer, pusher, err := c.connect(c.zonePrefix + "." + c.doppler)
 	if err != nil {
-		return c.connect(c.doppler)
+		closer, pusher, err = c.connect(c.doppler)
+		return closer, pusher, false, err
 	}
-	return closer, pusher, err
+	return closer, pusher, true, nil
 }
 
-func (c GRPCConnector) connect(doppler string) (io.Closer, plumbing.DopplerIngress_SenderClient, error) {
+func (c GRPCConnector) connect(doppler string) (io.Closer, plumbing.DopplerIngress_BatchSenderClient, error) {
 	conn, err := c.dial(doppler, c.opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error dialing ingestor stream to %s: %s", c, err)
 	}
 	client := c.ingestorClient(conn)
 	log.Printf("successfully connected to doppler %s", c)
-	pusher, err := client.Sender(context.Background())
+	pusher, err := client.BatchSender(context.Background())
 	if err != nil {
 		// TODO: this close is not tested as we don't know how to assert
 		// against a grpc.ClientConn being closed.
@@ -62,9 +66,60 @@ func (c GRPCConnector) connect(doppler string) (io.Closer, plumbing.DopplerIngre
 	}
 	log.Printf("successfully established a stream to doppler %s", c)
 
-	return conn, pusher, err
+	return conn, &batchClient{
+		DopplerIngress_BatchSenderClient: pusher,
+		client:                           client,
+		name:                             c.String(),
+	}, nil
 }
 
 func (c GRPCConnector) String() string {
 	return fmt.Sprintf("[%s]%s", c.zonePrefix, c.doppler)
 }
+
+// batchClient sends batches on a BatchSender stream. Dopplers that predate
+// BatchSender end that stream with codes.Unimplemented, in which case the
+// batches are sent envelope by envelope on a Sender stream instead. Sends
+// must not be made concurrently.
+type batchClient struct {
+	plumbing.DopplerIngress_BatchSenderClient
+	client plumbing.DopplerIngressClient
+	name   string
+	sender plumbing.DopplerIngress_SenderClient
+}
+
+func (c *batchClient) Send(batch *plumbing.EnvelopeBatch) error {
+	if c.sender == nil {
+		err := c.DopplerIngress_BatchSenderClient.Send(batch)
+		if err != io.EOF {
+			return err
+		}
+
+		// The stream has ended. Its status tells why.
+		_, err = c.DopplerIngress_BatchSenderClient.CloseAndRecv()
+		if grpc.Code(err) != codes.Unimplemented {
+			return io.EOF
+		}
+
+		sender, err := c.client.Sender(context.Background())
+		if err != nil {
+			return fmt.Errorf("error establishing fallback stream to %s: %s", c.name, err)
+		}
+		log.Printf("doppler %s does not support batches, falling back to sending envelopes", c.name)
+		c.sender = sender
+	}
+
+	for _, e := range batch.Batch {
+		if err := c.sender.Send(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *batchClient) CloseAndRecv() (*plumbing.SenderResponse, error) {
+	if c.sender != nil {
+		return c.sender.CloseAndRecv()
+	}
+	return c.DopplerIngress_BatchSenderClient.CloseAndRecv()
+}
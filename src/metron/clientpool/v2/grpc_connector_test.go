@@ -2,8 +2,11 @@ package v2_test
 
 import (
 	"errors"
+	"io"
 
+	"github.com/apoydence/eachers/testhelpers"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -18,7 +21,7 @@ var _ = Describe("GRPCConnector", func() {
 			df               *mockDialFunc
 			cf               *mockIngressClientFunc
 			mockSender       *mockDopplerIngressClient
-			mockSenderClient *mockDopplerIngress_SenderClient
+			mockSenderClient *mockDopplerIngress_BatchSenderClient
 			clientConn       *grpc.ClientConn
 		)
 
@@ -30,35 +33,105 @@ var _ = Describe("GRPCConnector", func() {
 
 			cf = newMockIngressClientFunc()
 			mockSender = newMockDopplerIngressClient()
-			mockSenderClient = newMockDopplerIngress_SenderClient()
+			mockSenderClient = newMockDopplerIngress_BatchSenderClient()
 
 			cf.retIngressClient <- mockSender
-			mockSender.SenderOutput.Ret0 <- mockSenderClient
-			mockSender.SenderOutput.Ret1 <- nil
+			mockSender.BatchSenderOutput.Ret0 <- mockSenderClient
+			mockSender.BatchSenderOutput.Ret1 <- nil
 		})
 
 		It("connects to the dns name with az prefix", func() {
 			connector := clientpool.MakeGRPCConnector("test-name", "z1", df.fn, cf.fn, grpc.WithInsecure())
-			_, _, err := connector.Connect()
+			_, _, inZone, err := connector.Connect()
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(df.inputDoppler).To(Receive(Equal("z1.test-name")))
+			Expect(inZone).To(BeTrue())
 		})
 
 		It("returns the original client connection", func() {
 			connector := clientpool.MakeGRPCConnector("test-name", "", df.fn, cf.fn, grpc.WithInsecure())
-			conn, _, err := connector.Connect()
+			conn, _, _, err := connector.Connect()
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(conn).To(Equal(clientConn))
 		})
 
-		It("returns the pusher client", func() {
+		It("sends batches on the BatchSender stream", func() {
 			connector := clientpool.MakeGRPCConnector("test-name", "", df.fn, cf.fn, grpc.WithInsecure())
-			_, pusherClient, err := connector.Connect()
+			_, pusherClient, _, err := connector.Connect()
 			Expect(err).ToNot(HaveOccurred())
 
-			Expect(pusherClient).To(Equal(mockSenderClient))
+			batch := &plumbing.EnvelopeBatch{
+				Batch: []*plumbing.Envelope{{SourceId: "some-id"}},
+			}
+			mockSenderClient.SendOutput.Ret0 <- nil
+			Expect(pusherClient.Send(batch)).To(Succeed())
+			Expect(mockSenderClient.SendInput.Arg0).To(Receive(Equal(batch)))
+		})
+
+		Context("when doppler does not implement BatchSender", func() {
+			var (
+				mockEnvelopeSender *mockDopplerIngress_SenderClient
+				batch              *plumbing.EnvelopeBatch
+			)
+
+			BeforeEach(func() {
+				mockEnvelopeSender = newMockDopplerIngress_SenderClient()
+				batch = &plumbing.EnvelopeBatch{
+					Batch: []*plumbing.Envelope{
+						{SourceId: "some-id"},
+						{SourceId: "other-id"},
+					},
+				}
+
+				mockSenderClient.SendOutput.Ret0 <- io.EOF
+				mockSenderClient.CloseAndRecvOutput.Ret0 <- nil
+				mockSenderClient.CloseAndRecvOutput.Ret1 <- grpc.Errorf(codes.Unimplemented, "unknown method")
+			})
+
+			It("sends the envelopes on a Sender stream", func() {
+				mockSender.SenderOutput.Ret0 <- mockEnvelopeSender
+				mockSender.SenderOutput.Ret1 <- nil
+				testhelpers.AlwaysReturn(mockEnvelopeSender.SendOutput.Ret0, nil)
+
+				connector := clientpool.MakeGRPCConnector("test-name", "", df.fn, cf.fn, grpc.WithInsecure())
+				_, pusherClient, _, err := connector.Connect()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(pusherClient.Send(batch)).To(Succeed())
+				Expect(pusherClient.Send(batch)).To(Succeed())
+
+				Expect(mockSenderClient.SendCalled).To(HaveLen(1))
+				Expect(mockSender.SenderCalled).To(HaveLen(1))
+				Expect(mockEnvelopeSender.SendInput.Arg0).To(HaveLen(4))
+				Expect(mockEnvelopeSender.SendInput.Arg0).To(Receive(Equal(batch.Batch[0])))
+				Expect(mockEnvelopeSender.SendInput.Arg0).To(Receive(Equal(batch.Batch[1])))
+			})
+
+			It("returns an error when the Sender stream can not be opened", func() {
+				mockSender.SenderOutput.Ret0 <- nil
+				mockSender.SenderOutput.Ret1 <- errors.New("some-error")
+
+				connector := clientpool.MakeGRPCConnector("test-name", "", df.fn, cf.fn, grpc.WithInsecure())
+				_, pusherClient, _, err := connector.Connect()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(pusherClient.Send(batch)).ToNot(Succeed())
+			})
+		})
+
+		It("does not fall back when the stream ends for another reason", func() {
+			mockSenderClient.SendOutput.Ret0 <- io.EOF
+			mockSenderClient.CloseAndRecvOutput.Ret0 <- nil
+			mockSenderClient.CloseAndRecvOutput.Ret1 <- grpc.Errorf(codes.Unavailable, "going away")
+
+			connector := clientpool.MakeGRPCConnector("test-name", "", df.fn, cf.fn, grpc.WithInsecure())
+			_, pusherClient, _, err := connector.Connect()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pusherClient.Send(&plumbing.EnvelopeBatch{})).To(Equal(io.EOF))
+			Expect(mockSender.SenderCalled).To(BeEmpty())
 		})
 	})
 
@@ -67,26 +140,27 @@ var _ = Describe("GRPCConnector", func() {
 			df := newMockDialFunc()
 			cf := newMockIngressClientFunc()
 			mockSender := newMockDopplerIngressClient()
-			mockSenderClient := newMockDopplerIngress_SenderClient()
+			mockSenderClient := newMockDopplerIngress_BatchSenderClient()
 
 			df.retClientConn <- newMockClientConn()
 			df.retErr <- nil
-			mockSender.SenderOutput.Ret0 <- nil
-			mockSender.SenderOutput.Ret1 <- errors.New("fake error")
+			mockSender.BatchSenderOutput.Ret0 <- nil
+			mockSender.BatchSenderOutput.Ret1 <- errors.New("fake error")
 			cf.retIngressClient <- mockSender
 
 			df.retClientConn <- &grpc.ClientConn{}
 			df.retErr <- nil
-			mockSender.SenderOutput.Ret0 <- mockSenderClient
-			mockSender.SenderOutput.Ret1 <- nil
+			mockSender.BatchSenderOutput.Ret0 <- mockSenderClient
+			mockSender.BatchSenderOutput.Ret1 <- nil
 			cf.retIngressClient <- mockSender
 
 			connector := clientpool.MakeGRPCConnector("test-name", "z1", df.fn, cf.fn)
-			_, _, err := connector.Connect()
+			_, _, inZone, err := connector.Connect()
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(df.inputDoppler).To(Receive(Equal("z1.test-name")))
 			Expect(df.inputDoppler).To(Receive(Equal("test-name")))
+			Expect(inZone).To(BeFalse())
 		})
 	})
 
@@ -101,7 +175,7 @@ var _ = Describe("GRPCConnector", func() {
 			df.retErr <- errors.New("fake error")
 
 			connector := clientpool.MakeGRPCConnector("test-name", "z1", df.fn, nil)
-			_, _, err := connector.Connect()
+			_, _, _, err := connector.Connect()
 			Expect(err).To(HaveOccurred())
 		})
 	})
@@ -15,8 +15,9 @@ type mockV2Connector struct {
 	ConnectCalled chan bool
 	ConnectOutput struct {
 		Ret0 chan io.Closer
-		Ret1 chan plumbing.DopplerIngress_SenderClient
-		Ret2 chan error
+		Ret1 chan plumbing.DopplerIngress_BatchSenderClient
+		Ret2 chan bool
+		Ret3 chan error
 	}
 }
 
@@ -24,13 +25,14 @@ func newMockV2Connector() *mockV2Connector {
 	m := &mockV2Connector{}
 	m.ConnectCalled = make(chan bool, 100)
 	m.ConnectOutput.Ret0 = make(chan io.Closer, 100)
-	m.ConnectOutput.Ret1 = make(chan plumbing.DopplerIngress_SenderClient, 100)
-	m.ConnectOutput.Ret2 = make(chan error, 100)
+	m.ConnectOutput.Ret1 = make(chan plumbing.DopplerIngress_BatchSenderClient, 100)
+	m.ConnectOutput.Ret2 = make(chan bool, 100)
+	m.ConnectOutput.Ret3 = make(chan error, 100)
 	return m
 }
-func (m *mockV2Connector) Connect() (io.Closer, plumbing.DopplerIngress_SenderClient, error) {
+func (m *mockV2Connector) Connect() (io.Closer, plumbing.DopplerIngress_BatchSenderClient, bool, error) {
 	m.ConnectCalled <- true
-	return <-m.ConnectOutput.Ret0, <-m.ConnectOutput.Ret1, <-m.ConnectOutput.Ret2
+	return <-m.ConnectOutput.Ret0, <-m.ConnectOutput.Ret1, <-m.ConnectOutput.Ret2, <-m.ConnectOutput.Ret3
 }
 
 type mockDopplerIngressClient struct {
@@ -43,6 +45,15 @@ type mockDopplerIngressClient struct {
 		Ret0 chan plumbing.DopplerIngress_SenderClient
 		Ret1 chan error
 	}
+	BatchSenderCalled chan bool
+	BatchSenderInput  struct {
+		Ctx  chan context.Context
+		Opts chan []grpc.CallOption
+	}
+	BatchSenderOutput struct {
+		Ret0 chan plumbing.DopplerIngress_BatchSenderClient
+		Ret1 chan error
+	}
 }
 
 func newMockDopplerIngressClient() *mockDopplerIngressClient {
@@ -52,6 +63,11 @@ func newMockDopplerIngressClient() *mockDopplerIngressClient {
 	m.SenderInput.Opts = make(chan []grpc.CallOption, 100)
 	m.SenderOutput.Ret0 = make(chan plumbing.DopplerIngress_SenderClient, 100)
 	m.SenderOutput.Ret1 = make(chan error, 100)
+	m.BatchSenderCalled = make(chan bool, 100)
+	m.BatchSenderInput.Ctx = make(chan context.Context, 100)
+	m.BatchSenderInput.Opts = make(chan []grpc.CallOption, 100)
+	m.BatchSenderOutput.Ret0 = make(chan plumbing.DopplerIngress_BatchSenderClient, 100)
+	m.BatchSenderOutput.Ret1 = make(chan error, 100)
 	return m
 }
 func (m *mockDopplerIngressClient) Sender(ctx context.Context, opts ...grpc.CallOption) (plumbing.DopplerIngress_SenderClient, error) {
@@ -60,6 +76,119 @@ func (m *mockDopplerIngressClient) Sender(ctx context.Context, opts ...grpc.Call
 	m.SenderInput.Opts <- opts
 	return <-m.SenderOutput.Ret0, <-m.SenderOutput.Ret1
 }
+func (m *mockDopplerIngressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (plumbing.DopplerIngress_BatchSenderClient, error) {
+	m.BatchSenderCalled <- true
+	m.BatchSenderInput.Ctx <- ctx
+	m.BatchSenderInput.Opts <- opts
+	return <-m.BatchSenderOutput.Ret0, <-m.BatchSenderOutput.Ret1
+}
+
+type mockDopplerIngress_BatchSenderClient struct {
+	SendCalled chan bool
+	SendInput  struct {
+		Arg0 chan *plumbing.EnvelopeBatch
+	}
+	SendOutput struct {
+		Ret0 chan error
+	}
+	CloseAndRecvCalled chan bool
+	CloseAndRecvOutput struct {
+		Ret0 chan *plumbing.SenderResponse
+		Ret1 chan error
+	}
+	HeaderCalled chan bool
+	HeaderOutput struct {
+		Ret0 chan metadata.MD
+		Ret1 chan error
+	}
+	TrailerCalled chan bool
+	TrailerOutput struct {
+		Ret0 chan metadata.MD
+	}
+	CloseSendCalled chan bool
+	CloseSendOutput struct {
+		Ret0 chan error
+	}
+	ContextCalled chan bool
+	ContextOutput struct {
+		Ret0 chan context.Context
+	}
+	SendMsgCalled chan bool
+	SendMsgInput  struct {
+		M chan interface{}
+	}
+	SendMsgOutput struct {
+		Ret0 chan error
+	}
+	RecvMsgCalled chan bool
+	RecvMsgInput  struct {
+		M chan interface{}
+	}
+	RecvMsgOutput struct {
+		Ret0 chan error
+	}
+}
+
+func newMockDopplerIngress_BatchSenderClient() *mockDopplerIngress_BatchSenderClient {
+	m := &mockDopplerIngress_BatchSenderClient{}
+	m.SendCalled = make(chan bool, 100)
+	m.SendInput.Arg0 = make(chan *plumbing.EnvelopeBatch, 100)
+	m.SendOutput.Ret0 = make(chan error, 100)
+	m.CloseAndRecvCalled = make(chan bool, 100)
+	m.CloseAndRecvOutput.Ret0 = make(chan *plumbing.SenderResponse, 100)
+	m.CloseAndRecvOutput.Ret1 = make(chan error, 100)
+	m.HeaderCalled = make(chan bool, 100)
+	m.HeaderOutput.Ret0 = make(chan metadata.MD, 100)
+	m.HeaderOutput.Ret1 = make(chan error, 100)
+	m.TrailerCalled = make(chan bool, 100)
+	m.TrailerOutput.Ret0 = make(chan metadata.MD, 100)
+	m.CloseSendCalled = make(chan bool, 100)
+	m.CloseSendOutput.Ret0 = make(chan error, 100)
+	m.ContextCalled = make(chan bool, 100)
+	m.ContextOutput.Ret0 = make(chan context.Context, 100)
+	m.SendMsgCalled = make(chan bool, 100)
+	m.SendMsgInput.M = make(chan interface{}, 100)
+	m.SendMsgOutput.Ret0 = make(chan error, 100)
+	m.RecvMsgCalled = make(chan bool, 100)
+	m.RecvMsgInput.M = make(chan interface{}, 100)
+	m.RecvMsgOutput.Ret0 = make(chan error, 100)
+	return m
+}
+func (m *mockDopplerIngress_BatchSenderClient) Send(arg0 *plumbing.EnvelopeBatch) error {
+	m.SendCalled <- true
+	m.SendInput.Arg0 <- arg0
+	return <-m.SendOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderClient) CloseAndRecv() (*plumbing.SenderResponse, error) {
+	m.CloseAndRecvCalled <- true
+	return <-m.CloseAndRecvOutput.Ret0, <-m.CloseAndRecvOutput.Ret1
+}
+func (m *mockDopplerIngress_BatchSenderClient) Header() (metadata.MD, error) {
+	m.HeaderCalled <- true
+	return <-m.HeaderOutput.Ret0, <-m.HeaderOutput.Ret1
+}
+func (m *mockDopplerIngress_BatchSenderClient) Trailer() metadata.MD {
+	m.TrailerCalled <- true
+	return <-m.TrailerOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderClient) CloseSend() error {
+	m.CloseSendCalled <- true
+	return <-m.CloseSendOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderClient) Context() context.Context {
+	m.ContextCalled <- true
+	return <-m.ContextOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderClient) SendMsg(m_ interface{}) error {
+	m.SendMsgCalled <- true
+	m.SendMsgInput.M <- m_
+	return <-m.SendMsgOutput.Ret0
+}
+func (m *mockDopplerIngress_BatchSenderClient) RecvMsg(m_ interface{}) error {
+	m.RecvMsgCalled <- true
+	m.RecvMsgInput.M <- m_
+	return <-m.RecvMsgOutput.Ret0
+}
 
 type mockDopplerIngress_SenderClient struct {
 	SendCalled chan bool
@@ -231,3 +360,59 @@ func (m *mockConn) Write(data *plumbing.Envelope) (err error) {
 	m.WriteInput.Data <- data
 	return <-m.WriteOutput.Err
 }
+
+type mockStatsConn struct {
+	WriteCalled chan bool
+	WriteInput  struct {
+		Data chan *plumbing.Envelope
+	}
+	WriteOutput struct {
+		Err chan error
+	}
+	StatsCalled chan bool
+	StatsOutput struct {
+		Ret0 chan clientpool.ConnStats
+	}
+}
+
+func newMockStatsConn() *mockStatsConn {
+	m := &mockStatsConn{}
+	m.WriteCalled = make(chan bool, 100)
+	m.WriteInput.Data = make(chan *plumbing.Envelope, 100)
+	m.WriteOutput.Err = make(chan error, 100)
+	m.StatsCalled = make(chan bool, 100)
+	m.StatsOutput.Ret0 = make(chan clientpool.ConnStats, 100)
+	return m
+}
+func (m *mockStatsConn) Write(data *plumbing.Envelope) (err error) {
+	m.WriteCalled <- true
+	m.WriteInput.Data <- data
+	return <-m.WriteOutput.Err
+}
+func (m *mockStatsConn) Stats() clientpool.ConnStats {
+	m.StatsCalled <- true
+	return <-m.StatsOutput.Ret0
+}
+
+type mockStrategy struct {
+	OrderCalled chan bool
+	OrderInput  struct {
+		Stats chan []clientpool.ConnStats
+	}
+	OrderOutput struct {
+		Ret0 chan []int
+	}
+}
+
+func newMockStrategy() *mockStrategy {
+	m := &mockStrategy{}
+	m.OrderCalled = make(chan bool, 100)
+	m.OrderInput.Stats = make(chan []clientpool.ConnStats, 100)
+	m.OrderOutput.Ret0 = make(chan []int, 100)
+	return m
+}
+func (m *mockStrategy) Order(stats []clientpool.ConnStats) []int {
+	m.OrderCalled <- true
+	m.OrderInput.Stats <- stats
+	return <-m.OrderOutput.Ret0
+}
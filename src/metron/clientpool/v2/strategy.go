@@ -0,0 +1,111 @@
+package v2
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ConnStats describes how a conn has behaved recently.
+type ConnStats struct {
+	// InZone is true when the conn is connected to a doppler in the same
+	// zone as metron.
+	InZone bool
+	// ErrorRate is a moving average of failed sends to doppler, between 0
+	// and 1.
+	ErrorRate float64
+	// Latency is a moving average of the time a send to doppler takes.
+	Latency time.Duration
+}
+
+// Strategy decides the order in which the pool tries its conns for a write.
+type Strategy interface {
+	// Order returns indexes into stats, the preferred conn first. Conns that
+	// are left out are not tried.
+	Order(stats []ConnStats) []int
+}
+
+const (
+	defaultZoneWeight = 4
+	minWeight         = 0.01
+	latencyFloor      = 100 * time.Microsecond
+)
+
+// WeightedStrategy orders conns randomly, weighting each by its health.
+// Healthy conns in the same zone are the most likely to be tried first. A
+// conn's weight drops as its error rate and latency rise, but never to zero
+// so it is still tried now and then and can recover.
+type WeightedStrategy struct {
+	zoneWeight float64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewWeightedStrategy returns a WeightedStrategy that draws from the given
+// source.
+func NewWeightedStrategy(src rand.Source) *WeightedStrategy {
+	return &WeightedStrategy{
+		zoneWeight: defaultZoneWeight,
+		rnd:        rand.New(src),
+	}
+}
+
+// Order implements Strategy.
+func (s *WeightedStrategy) Order(stats []ConnStats) []int {
+	weights := make([]float64, len(stats))
+	var total float64
+	for i, st := range stats {
+		weights[i] = s.weight(st)
+		total += weights[i]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order := make([]int, 0, len(stats))
+	for len(order) < len(stats) {
+		n := s.rnd.Float64() * total
+		for i, w := range weights {
+			if w == 0 {
+				continue
+			}
+
+			n -= w
+			if n < 0 || isLast(weights, i) {
+				order = append(order, i)
+				total -= w
+				weights[i] = 0
+				break
+			}
+		}
+	}
+
+	return order
+}
+
+func (s *WeightedStrategy) weight(st ConnStats) float64 {
+	latency := st.Latency
+	if latency < latencyFloor {
+		latency = latencyFloor
+	}
+
+	w := (1 - st.ErrorRate) * float64(latencyFloor) / float64(latency)
+	if st.InZone {
+		w *= s.zoneWeight
+	}
+
+	return math.Max(w, minWeight)
+}
+
+// isLast reports whether i is the last index with a weight left. It guards
+// against rounding leaving a conn unpicked.
+func isLast(weights []float64, i int) bool {
+	for _, w := range weights[i+1:] {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
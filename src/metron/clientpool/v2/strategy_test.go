@@ -0,0 +1,78 @@
+package v2_test
+
+import (
+	"math/rand"
+	clientpool "metron/clientpool/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WeightedStrategy", func() {
+	var (
+		strategy *clientpool.WeightedStrategy
+	)
+
+	BeforeEach(func() {
+		strategy = clientpool.NewWeightedStrategy(rand.NewSource(1))
+	})
+
+	firstPicks := func(stats []clientpool.ConnStats) map[int]int {
+		picks := make(map[int]int)
+		for i := 0; i < 1000; i++ {
+			picks[strategy.Order(stats)[0]]++
+		}
+		return picks
+	}
+
+	It("orders every conn", func() {
+		stats := make([]clientpool.ConnStats, 5)
+		stats[2].ErrorRate = 1
+
+		Expect(strategy.Order(stats)).To(ConsistOf(0, 1, 2, 3, 4))
+	})
+
+	It("spreads writes across healthy conns", func() {
+		picks := firstPicks(make([]clientpool.ConnStats, 2))
+
+		Expect(picks[0]).To(BeNumerically("~", 500, 100))
+		Expect(picks[1]).To(BeNumerically("~", 500, 100))
+	})
+
+	It("prefers conns in the same zone", func() {
+		picks := firstPicks([]clientpool.ConnStats{
+			{InZone: true},
+			{},
+		})
+
+		Expect(picks[0]).To(BeNumerically(">", 700))
+	})
+
+	It("shifts away from conns with errors", func() {
+		picks := firstPicks([]clientpool.ConnStats{
+			{InZone: true, ErrorRate: 0.9},
+			{},
+		})
+
+		Expect(picks[1]).To(BeNumerically(">", 600))
+	})
+
+	It("shifts away from slow conns", func() {
+		picks := firstPicks([]clientpool.ConnStats{
+			{Latency: 50 * time.Millisecond},
+			{},
+		})
+
+		Expect(picks[1]).To(BeNumerically(">", 950))
+	})
+
+	It("still tries unhealthy conns now and then", func() {
+		picks := firstPicks([]clientpool.ConnStats{
+			{ErrorRate: 1},
+			{},
+		})
+
+		Expect(picks[0]).To(BeNumerically(">", 0))
+	})
+})
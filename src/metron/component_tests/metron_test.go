@@ -99,15 +99,15 @@ var _ = Describe("Metron", func() {
 				return sender.Send(emitEnvelope)
 			}, 5).Should(Succeed())
 
-			var rx v2.DopplerIngress_SenderServer
-			Expect(consumerServer.V2.SenderInput.Arg0).Should(Receive(&rx))
+			var rx v2.DopplerIngress_BatchSenderServer
+			Expect(consumerServer.V2.BatchSenderInput.Arg0).Should(Receive(&rx))
 
-			f := func() *v2.Envelope {
-				envelope, err := rx.Recv()
+			f := func() []*v2.Envelope {
+				envelopeBatch, err := rx.Recv()
 				Expect(err).ToNot(HaveOccurred())
-				return envelope
+				return envelopeBatch.Batch
 			}
-			Eventually(f).Should(Equal(emitEnvelope))
+			Eventually(f).Should(ContainElement(emitEnvelope))
 		})
 
 		It("emits metrics to the v2 API", func() {
@@ -131,16 +131,21 @@ var _ = Describe("Metron", func() {
 				}
 			}()
 
-			var rx v2.DopplerIngress_SenderServer
-			Eventually(consumerServer.V2.SenderInput.Arg0).Should(Receive(&rx))
+			var rx v2.DopplerIngress_BatchSenderServer
+			Eventually(consumerServer.V2.BatchSenderInput.Arg0).Should(Receive(&rx))
 
 			f := func() bool {
 				sender.Send(emitEnvelope)
-				envelope, err := rx.Recv()
+				envelopeBatch, err := rx.Recv()
 				Expect(err).ToNot(HaveOccurred())
 
-				return envelope.GetCounter() != nil &&
-					envelope.GetCounter().GetTotal() > 5
+				for _, envelope := range envelopeBatch.Batch {
+					if envelope.GetCounter() != nil &&
+						envelope.GetCounter().GetTotal() > 5 {
+						return true
+					}
+				}
+				return false
 			}
 			Eventually(f, 15, "1ns").Should(Equal(true))
 		})
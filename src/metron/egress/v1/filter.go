@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"metron/filter"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+// Filter drops and rewrites envelopes according to the rules of an engine.
+type Filter struct {
+	engine       *filter.Engine
+	outputWriter EnvelopeWriter
+}
+
+// NewFilter returns a Filter that writes envelopes that are not dropped to
+// outputWriter.
+func NewFilter(e *filter.Engine, outputWriter EnvelopeWriter) *Filter {
+	return &Filter{
+		engine:       e,
+		outputWriter: outputWriter,
+	}
+}
+
+func (f *Filter) Write(envelope *events.Envelope) {
+	if !f.engine.Apply(filterEnvelope{envelope}) {
+		return
+	}
+
+	f.outputWriter.Write(envelope)
+}
+
+// filterEnvelope adapts a v1 envelope to filter.Envelope. The source ID of
+// logs and container metrics is the app ID, and the origin otherwise.
+type filterEnvelope struct {
+	e *events.Envelope
+}
+
+func (e filterEnvelope) Type() string {
+	switch e.e.GetEventType() {
+	case events.Envelope_LogMessage:
+		return "log"
+	case events.Envelope_CounterEvent:
+		return "counter"
+	case events.Envelope_ValueMetric, events.Envelope_ContainerMetric:
+		return "gauge"
+	case events.Envelope_HttpStartStop:
+		return "timer"
+	case events.Envelope_Error:
+		return "error"
+	default:
+		return ""
+	}
+}
+
+func (e filterEnvelope) Names() []string {
+	switch e.e.GetEventType() {
+	case events.Envelope_CounterEvent:
+		return []string{e.e.GetCounterEvent().GetName()}
+	case events.Envelope_ValueMetric:
+		return []string{e.e.GetValueMetric().GetName()}
+	default:
+		return nil
+	}
+}
+
+func (e filterEnvelope) SourceID() string {
+	if id := e.e.GetLogMessage().GetAppId(); id != "" {
+		return id
+	}
+
+	if id := e.e.GetContainerMetric().GetApplicationId(); id != "" {
+		return id
+	}
+
+	return e.e.GetOrigin()
+}
+
+func (e filterEnvelope) Tag(name string) (string, bool) {
+	v, ok := e.e.GetTags()[name]
+	return v, ok
+}
+
+func (e filterEnvelope) SetTag(name, value string) {
+	if e.e.Tags == nil {
+		e.e.Tags = make(map[string]string)
+	}
+	e.e.Tags[name] = value
+}
+
+func (e filterEnvelope) RemoveTag(name string) {
+	delete(e.e.Tags, name)
+}
+
+func (e filterEnvelope) Payload() []byte {
+	return e.e.GetLogMessage().GetMessage()
+}
+
+func (e filterEnvelope) SetPayload(p []byte) {
+	if m := e.e.GetLogMessage(); m != nil {
+		m.Message = p
+	}
+}
@@ -0,0 +1,80 @@
+package v1_test
+
+import (
+	egress "metron/egress/v1"
+	"metron/filter"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filter", func() {
+	var (
+		mockWriter *MockEnvelopeWriter
+		f          *egress.Filter
+	)
+
+	BeforeEach(func() {
+		mockWriter = &MockEnvelopeWriter{}
+
+		engine, err := filter.New([]filter.Rule{
+			{
+				Name:         "drop-debug",
+				EnvelopeType: "gauge",
+				MetricName:   "metricName",
+				Drop:         true,
+			},
+			{
+				Name:         "redact",
+				EnvelopeType: "log",
+				SourceID:     "some-app",
+				Replacements: []filter.Replacement{{Pattern: `password=\S+`, With: "password=REDACTED"}},
+				AddTags:      map[string]string{"redacted": "true"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		f = egress.NewFilter(engine, mockWriter)
+	})
+
+	It("drops matching envelopes", func() {
+		f.Write(basicMessage())
+
+		Expect(mockWriter.Events).To(BeEmpty())
+	})
+
+	It("rewrites matching logs", func() {
+		f.Write(&events.Envelope{
+			Origin:    proto.String("some-origin"),
+			EventType: events.Envelope_LogMessage.Enum(),
+			LogMessage: &events.LogMessage{
+				Message:     []byte("login password=hunter2 ok"),
+				MessageType: events.LogMessage_OUT.Enum(),
+				Timestamp:   proto.Int64(1),
+				AppId:       proto.String("some-app"),
+			},
+		})
+
+		Expect(mockWriter.Events).To(HaveLen(1))
+		e := mockWriter.Events[0]
+		Expect(string(e.GetLogMessage().GetMessage())).To(Equal("login password=REDACTED ok"))
+		Expect(e.GetTags()).To(Equal(map[string]string{"redacted": "true"}))
+	})
+
+	It("passes through envelopes that match no rule", func() {
+		e := &events.Envelope{
+			Origin:    proto.String("some-app"),
+			EventType: events.Envelope_CounterEvent.Enum(),
+			CounterEvent: &events.CounterEvent{
+				Name:  proto.String("metricName"),
+				Delta: proto.Uint64(1),
+			},
+		}
+		f.Write(e)
+
+		Expect(mockWriter.Events).To(ConsistOf(e))
+	})
+})
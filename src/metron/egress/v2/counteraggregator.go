@@ -1,10 +1,13 @@
 package v2
 
 import (
+	"container/list"
 	"crypto/sha1"
 	"fmt"
 	"io"
+	"metric"
 	"sort"
+	"time"
 
 	plumbing "plumbing/v2"
 )
@@ -14,41 +17,126 @@ type counterID struct {
 	tagsHash string
 }
 
+type counterTotal struct {
+	id       counterID
+	total    uint64
+	lastSeen time.Time
+}
+
+// CounterAggregator converts counter deltas into running totals. Totals are
+// evicted individually, either when they are the least recently used and the
+// cardinality limit is reached or when they have not been updated within the
+// TTL.
 type CounterAggregator struct {
-	writer        Writer
-	counterTotals map[counterID]uint64
+	writer      Writer
+	maxCounters int
+	ttl         time.Duration
+
+	// counterTotals indexes into lru. The front of lru is the most recently
+	// used counter.
+	counterTotals map[counterID]*list.Element
+	lru           *list.List
 }
 
-func New(w Writer) *CounterAggregator {
-	return &CounterAggregator{
+// CounterAggregatorOption configures a CounterAggregator.
+type CounterAggregatorOption func(*CounterAggregator)
+
+// WithMaxCounters sets the number of counter totals that are kept before the
+// least recently used is evicted. It defaults to 10000. Values below 1 are
+// ignored and the default is used.
+func WithMaxCounters(n int) CounterAggregatorOption {
+	return func(ca *CounterAggregator) {
+		if n < 1 {
+			return
+		}
+		ca.maxCounters = n
+	}
+}
+
+// WithCounterTTL sets how long a counter total is kept without being updated.
+// A TTL of zero, the default, keeps totals until they are evicted by the
+// cardinality limit.
+func WithCounterTTL(d time.Duration) CounterAggregatorOption {
+	return func(ca *CounterAggregator) {
+		ca.ttl = d
+	}
+}
+
+func New(w Writer, opts ...CounterAggregatorOption) *CounterAggregator {
+	ca := &CounterAggregator{
 		writer:        w,
-		counterTotals: make(map[counterID]uint64),
+		maxCounters:   10000,
+		counterTotals: make(map[counterID]*list.Element),
+		lru:           list.New(),
+	}
+	for _, o := range opts {
+		o(ca)
 	}
+	return ca
 }
 
+// Write converts counter deltas into totals before writing them. Counters
+// that already carry a total, such as scraped prometheus counters, are
+// written unchanged.
 func (ca *CounterAggregator) Write(msg *plumbing.Envelope) error {
-	if msg.GetCounter() != nil {
-		if len(ca.counterTotals) > 10000 {
-			ca.resetTotals()
-		}
+	_, isTotal := msg.GetCounter().GetValue().(*plumbing.Counter_Total)
+	if msg.GetCounter() != nil && !isTotal {
+		now := time.Now()
+		ca.evictExpired(now)
 
 		id := counterID{
 			name:     msg.GetCounter().Name,
 			tagsHash: hashTags(msg.GetTags()),
 		}
 
-		ca.counterTotals[id] = ca.counterTotals[id] + msg.GetCounter().GetDelta()
+		ct := ca.lookup(id)
+		ct.total += msg.GetCounter().GetDelta()
+		ct.lastSeen = now
 
 		msg.GetCounter().Value = &plumbing.Counter_Total{
-			Total: ca.counterTotals[id],
+			Total: ct.total,
 		}
 	}
 
 	return ca.writer.Write(msg)
 }
 
-func (ca *CounterAggregator) resetTotals() {
-	ca.counterTotals = make(map[counterID]uint64)
+func (ca *CounterAggregator) lookup(id counterID) *counterTotal {
+	if e, ok := ca.counterTotals[id]; ok {
+		ca.lru.MoveToFront(e)
+		return e.Value.(*counterTotal)
+	}
+
+	for len(ca.counterTotals) >= ca.maxCounters && ca.lru.Len() > 0 {
+		ca.evict(ca.lru.Back(), "lru")
+	}
+
+	ct := &counterTotal{id: id}
+	ca.counterTotals[id] = ca.lru.PushFront(ct)
+	return ct
+}
+
+func (ca *CounterAggregator) evictExpired(now time.Time) {
+	if ca.ttl <= 0 {
+		return
+	}
+
+	for e := ca.lru.Back(); e != nil; e = ca.lru.Back() {
+		if now.Sub(e.Value.(*counterTotal).lastSeen) < ca.ttl {
+			return
+		}
+		ca.evict(e, "ttl")
+	}
+}
+
+func (ca *CounterAggregator) evict(e *list.Element, reason string) {
+	ct := ca.lru.Remove(e).(*counterTotal)
+	delete(ca.counterTotals, ct.id)
+
+	metric.IncCounter("counter_evictions",
+		metric.WithVersion(2, 0),
+		metric.WithTag("reason", reason),
+	)
 }
 
 func hashTags(tags map[string]*plumbing.Value) string {
@@ -2,6 +2,7 @@ package v2_test
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -86,20 +87,24 @@ var _ = Describe("Counteraggregator", func() {
 		Expect(receivedEnvelope.GetCounter().GetTotal()).To(Equal(uint64(20)))
 	})
 
-	It("calculations are unaffected for counter envelopes with total set", func() {
+	It("writes counter envelopes with total set unchanged", func() {
 		mockWriter := newMockWriter()
 		close(mockWriter.WriteOutput.Ret0)
 
 		aggregator := egress.New(mockWriter)
 		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
 		aggregator.Write(buildCounterEnvelopeWithTotal(5000, "name-1", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(5, "name-1", "origin-1"))
 
 		var receivedEnvelope *plumbing.Envelope
 		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
 		Expect(receivedEnvelope.GetCounter().GetTotal()).To(Equal(uint64(10)))
 
 		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
-		Expect(receivedEnvelope.GetCounter().GetTotal()).To(Equal(uint64(10)))
+		Expect(receivedEnvelope.GetCounter().GetTotal()).To(Equal(uint64(5000)))
+
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+		Expect(receivedEnvelope.GetCounter().GetTotal()).To(Equal(uint64(15)))
 	})
 
 	It("prunes the cache of totals when there are too many unique counters", func() {
@@ -125,6 +130,67 @@ var _ = Describe("Counteraggregator", func() {
 		Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
 		Expect(receivedEnvelope.GetCounter().GetTotal()).To(Equal(uint64(10)))
 	})
+
+	It("only evicts the least recently used counter when at the limit", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.New(mockWriter, egress.WithMaxCounters(2))
+		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-2", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-3", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-2", "origin-1"))
+
+		var totals []uint64
+		for i := 0; i < 6; i++ {
+			var receivedEnvelope *plumbing.Envelope
+			Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+			totals = append(totals, receivedEnvelope.GetCounter().GetTotal())
+		}
+		Expect(totals).To(Equal([]uint64{10, 10, 20, 10, 30, 10}))
+	})
+
+	It("uses the default limit when the max counters is below 1", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.New(mockWriter, egress.WithMaxCounters(0))
+		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-2", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
+
+		var totals []uint64
+		for i := 0; i < 3; i++ {
+			var receivedEnvelope *plumbing.Envelope
+			Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+			totals = append(totals, receivedEnvelope.GetCounter().GetTotal())
+		}
+		Expect(totals).To(Equal([]uint64{10, 10, 20}))
+	})
+
+	It("evicts counters that have not been updated within the TTL", func() {
+		mockWriter := newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator := egress.New(mockWriter, egress.WithCounterTTL(50*time.Millisecond))
+		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-2", "origin-1"))
+		time.Sleep(30 * time.Millisecond)
+		aggregator.Write(buildCounterEnvelope(10, "name-2", "origin-1"))
+		time.Sleep(30 * time.Millisecond)
+		aggregator.Write(buildCounterEnvelope(10, "name-1", "origin-1"))
+		aggregator.Write(buildCounterEnvelope(10, "name-2", "origin-1"))
+
+		var totals []uint64
+		for i := 0; i < 5; i++ {
+			var receivedEnvelope *plumbing.Envelope
+			Expect(mockWriter.WriteInput.Msg).To(Receive(&receivedEnvelope))
+			totals = append(totals, receivedEnvelope.GetCounter().GetTotal())
+		}
+		Expect(totals).To(Equal([]uint64{10, 10, 20, 10, 30}))
+	})
 })
 
 func buildCounterEnvelope(delta uint64, name, origin string) *plumbing.Envelope {
@@ -0,0 +1,112 @@
+package v2
+
+import (
+	"metron/filter"
+	plumbing "plumbing/v2"
+)
+
+// Filter drops and rewrites envelopes according to the rules of an engine.
+type Filter struct {
+	engine *filter.Engine
+	writer Writer
+}
+
+// NewFilter returns a Filter that writes envelopes that are not dropped to w.
+func NewFilter(e *filter.Engine, w Writer) *Filter {
+	return &Filter{
+		engine: e,
+		writer: w,
+	}
+}
+
+func (f *Filter) Write(msg *plumbing.Envelope) error {
+	if !f.engine.Apply(envelope{msg}) {
+		return nil
+	}
+
+	return f.writer.Write(msg)
+}
+
+// envelope adapts a v2 envelope to filter.Envelope. Only text tags can be
+// matched.
+type envelope struct {
+	e *plumbing.Envelope
+}
+
+func (e envelope) Type() string {
+	switch e.e.GetMessage().(type) {
+	case *plumbing.Envelope_Log:
+		return "log"
+	case *plumbing.Envelope_Counter:
+		return "counter"
+	case *plumbing.Envelope_Gauge:
+		return "gauge"
+	case *plumbing.Envelope_Timer:
+		return "timer"
+	default:
+		return ""
+	}
+}
+
+func (e envelope) Names() []string {
+	switch m := e.e.GetMessage().(type) {
+	case *plumbing.Envelope_Counter:
+		return []string{m.Counter.Name}
+	case *plumbing.Envelope_Timer:
+		return []string{m.Timer.Name}
+	case *plumbing.Envelope_Gauge:
+		var names []string
+		for name := range m.Gauge.Metrics {
+			names = append(names, name)
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+func (e envelope) SourceID() string {
+	return e.e.SourceId
+}
+
+func (e envelope) Tag(name string) (string, bool) {
+	v, ok := e.e.Tags[name]
+	if !ok {
+		return "", false
+	}
+
+	t, ok := v.GetData().(*plumbing.Value_Text)
+	if !ok {
+		return "", false
+	}
+	return t.Text, true
+}
+
+func (e envelope) SetTag(name, value string) {
+	if e.e.Tags == nil {
+		e.e.Tags = make(map[string]*plumbing.Value)
+	}
+
+	e.e.Tags[name] = &plumbing.Value{
+		Data: &plumbing.Value_Text{
+			Text: value,
+		},
+	}
+}
+
+func (e envelope) RemoveTag(name string) {
+	delete(e.e.Tags, name)
+}
+
+func (e envelope) Payload() []byte {
+	if l := e.e.GetLog(); l != nil {
+		return l.Payload
+	}
+	return nil
+}
+
+func (e envelope) SetPayload(p []byte) {
+	if l := e.e.GetLog(); l != nil {
+		l.Payload = p
+	}
+}
@@ -0,0 +1,108 @@
+package v2_test
+
+import (
+	egress "metron/egress/v2"
+	"metron/filter"
+	plumbing "plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filter", func() {
+	var (
+		mockWriter *mockWriter
+		f          *egress.Filter
+	)
+
+	BeforeEach(func() {
+		mockWriter = newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		engine, err := filter.New([]filter.Rule{
+			{
+				Name:         "drop-debug",
+				EnvelopeType: "gauge",
+				MetricName:   "debug",
+				Drop:         true,
+			},
+			{
+				Name:         "redact",
+				EnvelopeType: "log",
+				SourceID:     "some-app",
+				Tags:         map[string]string{"env": "prod"},
+				Replacements: []filter.Replacement{{Pattern: `password=\S+`, With: "password=REDACTED"}},
+				AddTags:      map[string]string{"redacted": "true"},
+				RemoveTags:   []string{"env"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		f = egress.NewFilter(engine, mockWriter)
+	})
+
+	It("drops matching envelopes", func() {
+		e := &plumbing.Envelope{
+			Message: &plumbing.Envelope_Gauge{
+				Gauge: &plumbing.Gauge{
+					Metrics: map[string]*plumbing.GaugeValue{
+						"debug": {Unit: "count", Value: 1},
+						"other": {Unit: "count", Value: 2},
+					},
+				},
+			},
+		}
+
+		Expect(f.Write(e)).To(Succeed())
+		Expect(mockWriter.WriteCalled).To(BeEmpty())
+	})
+
+	It("rewrites matching logs", func() {
+		e := &plumbing.Envelope{
+			SourceId: "some-app",
+			Tags: map[string]*plumbing.Value{
+				"env": {Data: &plumbing.Value_Text{Text: "prod"}},
+			},
+			Message: &plumbing.Envelope_Log{
+				Log: &plumbing.Log{Payload: []byte("login password=hunter2 ok")},
+			},
+		}
+
+		Expect(f.Write(e)).To(Succeed())
+
+		var written *plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&written))
+		Expect(string(written.GetLog().Payload)).To(Equal("login password=REDACTED ok"))
+		Expect(written.Tags).To(HaveLen(1))
+		Expect(written.Tags["redacted"].GetText()).To(Equal("true"))
+	})
+
+	It("does not match tags that are not text", func() {
+		e := &plumbing.Envelope{
+			SourceId: "some-app",
+			Tags: map[string]*plumbing.Value{
+				"env": {Data: &plumbing.Value_Integer{Integer: 1}},
+			},
+			Message: &plumbing.Envelope_Log{
+				Log: &plumbing.Log{Payload: []byte("password=hunter2")},
+			},
+		}
+
+		Expect(f.Write(e)).To(Succeed())
+
+		var written *plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&written))
+		Expect(string(written.GetLog().Payload)).To(Equal("password=hunter2"))
+	})
+
+	It("passes through envelopes that match no rule", func() {
+		e := &plumbing.Envelope{
+			Message: &plumbing.Envelope_Counter{
+				Counter: &plumbing.Counter{Name: "debug"},
+			},
+		}
+
+		Expect(f.Write(e)).To(Succeed())
+		Expect(mockWriter.WriteInput.Msg).To(Receive(Equal(e)))
+	})
+})
@@ -5,7 +5,10 @@
 
 package v2_test
 
-import v2 "plumbing/v2"
+import (
+	"metric"
+	v2 "plumbing/v2"
+)
 
 type mockNexter struct {
 	NextCalled chan bool
@@ -47,3 +50,93 @@ func (m *mockWriter) Write(msg *v2.Envelope) error {
 	m.WriteInput.Msg <- msg
 	return <-m.WriteOutput.Ret0
 }
+
+type mockSpillQueue struct {
+	PushCalled chan bool
+	PushInput  struct {
+		E chan *v2.Envelope
+	}
+	PushOutput struct {
+		Ret0 chan error
+	}
+	PeekCalled chan bool
+	PeekOutput struct {
+		Ret0 chan *v2.Envelope
+		Ret1 chan bool
+	}
+	PopCalled chan bool
+	LenCalled chan bool
+	LenOutput struct {
+		Ret0 chan int
+	}
+}
+
+func newMockSpillQueue() *mockSpillQueue {
+	m := &mockSpillQueue{}
+	m.PushCalled = make(chan bool, 100)
+	m.PushInput.E = make(chan *v2.Envelope, 100)
+	m.PushOutput.Ret0 = make(chan error, 100)
+	m.PeekCalled = make(chan bool, 100)
+	m.PeekOutput.Ret0 = make(chan *v2.Envelope, 100)
+	m.PeekOutput.Ret1 = make(chan bool, 100)
+	m.PopCalled = make(chan bool, 100)
+	m.LenCalled = make(chan bool, 100)
+	m.LenOutput.Ret0 = make(chan int, 100)
+	return m
+}
+func (m *mockSpillQueue) Push(e *v2.Envelope) error {
+	m.PushCalled <- true
+	m.PushInput.E <- e
+	return <-m.PushOutput.Ret0
+}
+func (m *mockSpillQueue) Peek() (*v2.Envelope, bool) {
+	m.PeekCalled <- true
+	return <-m.PeekOutput.Ret0, <-m.PeekOutput.Ret1
+}
+func (m *mockSpillQueue) Pop() {
+	m.PopCalled <- true
+}
+func (m *mockSpillQueue) Len() int {
+	m.LenCalled <- true
+	return <-m.LenOutput.Ret0
+}
+
+type mockMetricEmitter struct {
+	IncCounterCalled chan bool
+	IncCounterInput  struct {
+		Name    chan string
+		Options chan []metric.IncrementOpt
+	}
+	SetGaugeCalled chan bool
+	SetGaugeInput  struct {
+		Name    chan string
+		Value   chan float64
+		Unit    chan string
+		Options chan []metric.IncrementOpt
+	}
+}
+
+func newMockMetricEmitter() *mockMetricEmitter {
+	m := &mockMetricEmitter{}
+	m.IncCounterCalled = make(chan bool, 100)
+	m.IncCounterInput.Name = make(chan string, 100)
+	m.IncCounterInput.Options = make(chan []metric.IncrementOpt, 100)
+	m.SetGaugeCalled = make(chan bool, 100)
+	m.SetGaugeInput.Name = make(chan string, 100)
+	m.SetGaugeInput.Value = make(chan float64, 100)
+	m.SetGaugeInput.Unit = make(chan string, 100)
+	m.SetGaugeInput.Options = make(chan []metric.IncrementOpt, 100)
+	return m
+}
+func (m *mockMetricEmitter) IncCounter(name string, options ...metric.IncrementOpt) {
+	m.IncCounterCalled <- true
+	m.IncCounterInput.Name <- name
+	m.IncCounterInput.Options <- options
+}
+func (m *mockMetricEmitter) SetGauge(name string, value float64, unit string, options ...metric.IncrementOpt) {
+	m.SetGaugeCalled <- true
+	m.SetGaugeInput.Name <- name
+	m.SetGaugeInput.Value <- value
+	m.SetGaugeInput.Unit <- unit
+	m.SetGaugeInput.Options <- options
+}
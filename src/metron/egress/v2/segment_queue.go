@@ -0,0 +1,385 @@
+package v2
+
+import (
+	"bufio"
+	"diodes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	plumbing "plumbing/v2"
+
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	segmentExt       = ".seg"
+	recordHeaderSize = 4
+)
+
+// SegmentQueue is a bounded FIFO of envelopes stored in segment files on
+// disk. Once the queue has reached its maximum size the oldest segment is
+// discarded and the alerter is notified of the envelopes lost. Segments left
+// behind by a previous process are picked up and replayed first.
+type SegmentQueue struct {
+	mu           sync.Mutex
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+	alerter      diodes.Alerter
+
+	segments []*segment
+	size     int64
+	length   int
+
+	writer     *os.File
+	readerFile *os.File
+	reader     *bufio.Reader
+	next       *plumbing.Envelope
+}
+
+type segment struct {
+	seq   uint64
+	size  int64
+	count int
+}
+
+// NewSegmentQueue returns a SegmentQueue that stores its segments in dir. The
+// directory is created if it does not exist.
+func NewSegmentQueue(
+	dir string,
+	maxBytes int64,
+	segmentBytes int64,
+	alerter diodes.Alerter,
+) (*SegmentQueue, error) {
+	if segmentBytes <= 0 || maxBytes < segmentBytes {
+		return nil, fmt.Errorf("invalid segment queue sizes: max %d, segment %d", maxBytes, segmentBytes)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	q := &SegmentQueue{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		segmentBytes: segmentBytes,
+		alerter:      alerter,
+	}
+
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// Push appends the envelope to the queue.
+func (q *SegmentQueue) Push(e *plumbing.Envelope) error {
+	data, err := proto.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	record := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(record, uint32(len(data)))
+	copy(record[recordHeaderSize:], data)
+
+	if int64(len(record)) > q.segmentBytes {
+		return errors.New("envelope is larger than a segment")
+	}
+
+	q.mu.Lock()
+	var dropped int
+	for q.size+int64(len(record)) > q.maxBytes && len(q.segments) > 0 {
+		dropped += q.dropHead()
+	}
+	err = q.write(record)
+	q.mu.Unlock()
+
+	if dropped > 0 {
+		q.alerter.Alert(dropped)
+	}
+
+	return err
+}
+
+// Peek returns the envelope at the front of the queue without removing it.
+// It returns false if the queue is empty.
+func (q *SegmentQueue) Peek() (*plumbing.Envelope, bool) {
+	q.mu.Lock()
+	var dropped int
+	for q.next == nil && q.length > 0 {
+		head := q.segments[0]
+		if head.count == 0 {
+			q.removeHead()
+			continue
+		}
+
+		e, err := q.read(head)
+		if err != nil {
+			log.Printf("discarding unreadable segment %d: %s", head.seq, err)
+			dropped += head.count
+			q.length -= head.count
+			head.count = 0
+			q.closeReader()
+			continue
+		}
+
+		q.next = e
+	}
+	e := q.next
+	q.mu.Unlock()
+
+	if dropped > 0 {
+		q.alerter.Alert(dropped)
+	}
+
+	return e, e != nil
+}
+
+// Pop removes the envelope returned by the last call to Peek.
+func (q *SegmentQueue) Pop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.next == nil {
+		return
+	}
+
+	q.next = nil
+	q.length--
+	head := q.segments[0]
+	head.count--
+
+	if head.count == 0 && len(q.segments) > 1 {
+		q.removeHead()
+	}
+}
+
+// Len returns the number of envelopes in the queue.
+func (q *SegmentQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.length
+}
+
+func (q *SegmentQueue) write(record []byte) error {
+	tail := q.tail()
+	if q.writer == nil || tail.size+int64(len(record)) > q.segmentBytes {
+		if err := q.rotate(); err != nil {
+			return err
+		}
+		tail = q.tail()
+	}
+
+	n, err := q.writer.Write(record)
+	tail.size += int64(n)
+	q.size += int64(n)
+	if err != nil {
+		// The partial record is dropped when the segment is reloaded, rotate
+		// so that it is never read back.
+		q.closeWriter()
+		return err
+	}
+
+	tail.count++
+	q.length++
+
+	return nil
+}
+
+func (q *SegmentQueue) rotate() error {
+	q.closeWriter()
+
+	var seq uint64
+	if tail := q.tail(); tail != nil {
+		seq = tail.seq + 1
+	}
+
+	f, err := os.OpenFile(q.path(seq), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	q.writer = f
+	q.segments = append(q.segments, &segment{seq: seq})
+
+	return nil
+}
+
+func (q *SegmentQueue) read(head *segment) (*plumbing.Envelope, error) {
+	if q.reader == nil {
+		f, err := os.Open(q.path(head.seq))
+		if err != nil {
+			return nil, err
+		}
+		q.readerFile = f
+		q.reader = bufio.NewReader(f)
+	}
+
+	data, err := readRecord(q.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var e plumbing.Envelope
+	if err := proto.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// dropHead discards the oldest segment and returns the number of unread
+// envelopes it contained.
+func (q *SegmentQueue) dropHead() int {
+	head := q.segments[0]
+	count := head.count
+	q.length -= count
+	q.next = nil
+	q.removeHead()
+
+	return count
+}
+
+func (q *SegmentQueue) removeHead() {
+	head := q.segments[0]
+	q.closeReader()
+	if len(q.segments) == 1 {
+		q.closeWriter()
+	}
+
+	if err := os.Remove(q.path(head.seq)); err != nil {
+		log.Printf("failed to remove segment %d: %s", head.seq, err)
+	}
+
+	q.size -= head.size
+	q.segments = q.segments[1:]
+}
+
+func (q *SegmentQueue) closeReader() {
+	if q.readerFile == nil {
+		return
+	}
+
+	q.readerFile.Close()
+	q.readerFile = nil
+	q.reader = nil
+}
+
+func (q *SegmentQueue) closeWriter() {
+	if q.writer == nil {
+		return
+	}
+
+	q.writer.Close()
+	q.writer = nil
+}
+
+func (q *SegmentQueue) tail() *segment {
+	if len(q.segments) == 0 {
+		return nil
+	}
+
+	return q.segments[len(q.segments)-1]
+}
+
+func (q *SegmentQueue) path(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+func (q *SegmentQueue) load() error {
+	paths, err := filepath.Glob(filepath.Join(q.dir, "*"+segmentExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(p), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		count, size, err := scanSegment(p)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			os.Remove(p)
+			continue
+		}
+
+		q.segments = append(q.segments, &segment{
+			seq:   seq,
+			size:  size,
+			count: count,
+		})
+		q.size += size
+		q.length += count
+	}
+
+	if q.length > 0 {
+		log.Printf("loaded %d spilled envelopes from %s", q.length, q.dir)
+	}
+
+	return nil
+}
+
+// scanSegment counts the records in a segment file. A partially written
+// record at the end of the file is truncated.
+func scanSegment(path string) (int, int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var (
+		count int
+		size  int64
+	)
+	for {
+		data, err := readRecord(r)
+		if err == io.EOF {
+			return count, size, nil
+		}
+
+		if err != nil {
+			log.Printf("truncating segment %s at offset %d: %s", path, size, err)
+			return count, size, f.Truncate(size)
+		}
+
+		count++
+		size += int64(recordHeaderSize + len(data))
+	}
+}
+
+func readRecord(r io.Reader) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,163 @@
+package v2_test
+
+import (
+	"diodes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	egress "metron/egress/v2"
+	plumbing "plumbing/v2"
+)
+
+var _ = Describe("SegmentQueue", func() {
+	var (
+		dir     string
+		alerts  chan int
+		alerter diodes.AlertFunc
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "segment-queue")
+		Expect(err).ToNot(HaveOccurred())
+
+		alerts = make(chan int, 100)
+		alerter = diodes.AlertFunc(func(missed int) {
+			alerts <- missed
+		})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("returns envelopes in the order they were pushed", func() {
+		q, err := egress.NewSegmentQueue(dir, 1024, 1024, alerter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(q.Push(buildLogEnvelope("a"))).To(Succeed())
+		Expect(q.Push(buildLogEnvelope("b"))).To(Succeed())
+		Expect(q.Len()).To(Equal(2))
+
+		Expect(popPayload(q)).To(Equal("a"))
+		Expect(popPayload(q)).To(Equal("b"))
+		Expect(q.Len()).To(Equal(0))
+
+		_, ok := q.Peek()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns the same envelope from Peek until it is popped", func() {
+		q, err := egress.NewSegmentQueue(dir, 1024, 1024, alerter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(q.Push(buildLogEnvelope("a"))).To(Succeed())
+		Expect(q.Push(buildLogEnvelope("b"))).To(Succeed())
+
+		first, ok := q.Peek()
+		Expect(ok).To(BeTrue())
+		second, ok := q.Peek()
+		Expect(ok).To(BeTrue())
+		Expect(second).To(Equal(first))
+	})
+
+	It("removes segments once they have been read", func() {
+		q, err := egress.NewSegmentQueue(dir, 1024, 64, alerter)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			Expect(q.Push(buildLogEnvelope(fmt.Sprint("message-", i)))).To(Succeed())
+		}
+		Expect(segmentFiles(dir)).To(BeNumerically(">", 1))
+
+		for i := 0; i < 10; i++ {
+			Expect(popPayload(q)).To(Equal(fmt.Sprint("message-", i)))
+		}
+		Expect(segmentFiles(dir)).To(Equal(1))
+	})
+
+	It("drops the oldest segment when it is full", func() {
+		q, err := egress.NewSegmentQueue(dir, 128, 64, alerter)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			Expect(q.Push(buildLogEnvelope(fmt.Sprint("message-", i)))).To(Succeed())
+		}
+
+		var dropped int
+		for len(alerts) > 0 {
+			dropped += <-alerts
+		}
+		Expect(dropped).To(BeNumerically(">", 0))
+		Expect(q.Len()).To(Equal(10 - dropped))
+
+		Expect(popPayload(q)).To(Equal(fmt.Sprint("message-", dropped)))
+	})
+
+	It("replays envelopes left on disk by a previous queue", func() {
+		q, err := egress.NewSegmentQueue(dir, 1024, 1024, alerter)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(q.Push(buildLogEnvelope("a"))).To(Succeed())
+		Expect(q.Push(buildLogEnvelope("b"))).To(Succeed())
+
+		q, err = egress.NewSegmentQueue(dir, 1024, 1024, alerter)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(q.Len()).To(Equal(2))
+
+		Expect(q.Push(buildLogEnvelope("c"))).To(Succeed())
+		Expect(popPayload(q)).To(Equal("a"))
+		Expect(popPayload(q)).To(Equal("b"))
+		Expect(popPayload(q)).To(Equal("c"))
+	})
+
+	It("truncates a partially written envelope left on disk", func() {
+		q, err := egress.NewSegmentQueue(dir, 1024, 1024, alerter)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(q.Push(buildLogEnvelope("a"))).To(Succeed())
+
+		f, err := os.OpenFile(filepath.Join(dir, "00000000000000000000.seg"), os.O_WRONLY|os.O_APPEND, 0600)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write([]byte{0, 0, 0, 10, 1, 2})
+		Expect(err).ToNot(HaveOccurred())
+		f.Close()
+
+		q, err = egress.NewSegmentQueue(dir, 1024, 1024, alerter)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(q.Len()).To(Equal(1))
+		Expect(popPayload(q)).To(Equal("a"))
+	})
+
+	It("returns an error for invalid sizes", func() {
+		_, err := egress.NewSegmentQueue(dir, 64, 128, alerter)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func buildLogEnvelope(payload string) *plumbing.Envelope {
+	return &plumbing.Envelope{
+		SourceId: "some-id",
+		Message: &plumbing.Envelope_Log{
+			Log: &plumbing.Log{
+				Payload: []byte(payload),
+			},
+		},
+	}
+}
+
+func popPayload(q *egress.SegmentQueue) string {
+	e, ok := q.Peek()
+	Expect(ok).To(BeTrue())
+	q.Pop()
+	return string(e.GetLog().Payload)
+}
+
+func segmentFiles(dir string) int {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	Expect(err).ToNot(HaveOccurred())
+	return len(paths)
+}
@@ -0,0 +1,191 @@
+package v2
+
+import (
+	"log"
+	"metric"
+	plumbing "plumbing/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type SpillQueue interface {
+	Push(e *plumbing.Envelope) error
+	Peek() (*plumbing.Envelope, bool)
+	Pop()
+	Len() int
+}
+
+// MetricEmitter emits the metrics of a SpillWriter.
+type MetricEmitter interface {
+	IncCounter(name string, options ...metric.IncrementOpt)
+	SetGauge(name string, value float64, unit string, options ...metric.IncrementOpt)
+}
+
+// replayBatchSize is the number of spilled envelopes replayed in a row
+// before the replay checks whether it has caught up.
+const replayBatchSize = 100
+
+// SpillWriter writes envelopes to the given Writer. When the Writer fails,
+// envelopes are spilled to the queue instead and replayed in order once the
+// Writer accepts envelopes again. Once the replay has caught up, envelopes
+// are written straight through again.
+type SpillWriter struct {
+	writer         Writer
+	queue          SpillQueue
+	retryInterval  time.Duration
+	metricInterval time.Duration
+	emitter        MetricEmitter
+
+	// mu guards spilling. Envelopes are only pushed to the queue with mu
+	// held so the replay can tell when it has caught up.
+	mu       sync.Mutex
+	spilling bool
+
+	spilled  uint64
+	replayed uint64
+}
+
+// SpillWriterOption configures a SpillWriter.
+type SpillWriterOption func(*SpillWriter)
+
+// WithSpillMetricInterval sets how often the spill metrics and queue depth are
+// emitted. It defaults to 5 seconds.
+func WithSpillMetricInterval(d time.Duration) SpillWriterOption {
+	return func(s *SpillWriter) {
+		s.metricInterval = d
+	}
+}
+
+// WithSpillMetricEmitter sets where the spill metrics are emitted. It
+// defaults to the metric package.
+func WithSpillMetricEmitter(e MetricEmitter) SpillWriterOption {
+	return func(s *SpillWriter) {
+		s.emitter = e
+	}
+}
+
+// NewSpillWriter returns a SpillWriter and starts replaying any envelopes
+// already in the queue.
+func NewSpillWriter(w Writer, q SpillQueue, retryInterval time.Duration, opts ...SpillWriterOption) *SpillWriter {
+	s := &SpillWriter{
+		writer:         w,
+		queue:          q,
+		retryInterval:  retryInterval,
+		metricInterval: 5 * time.Second,
+		emitter:        packageEmitter{},
+		spilling:       q.Len() > 0,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	go s.replay()
+	go s.emitMetrics()
+
+	return s
+}
+
+// Write writes the envelope straight through unless envelopes are spilled.
+// Otherwise, or if the write fails, the envelope is queued behind the
+// spilled envelopes.
+func (s *SpillWriter) Write(e *plumbing.Envelope) error {
+	if !s.isSpilling() {
+		if err := s.writer.Write(e); err == nil {
+			return nil
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.queue.Push(e); err != nil {
+		return err
+	}
+	s.spilling = true
+	atomic.AddUint64(&s.spilled, 1)
+
+	return nil
+}
+
+func (s *SpillWriter) isSpilling() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spilling
+}
+
+func (s *SpillWriter) replay() {
+	for {
+		if !s.isSpilling() || !s.replayBatch() {
+			time.Sleep(s.retryInterval)
+		}
+	}
+}
+
+// replayBatch writes up to replayBatchSize spilled envelopes to the writer.
+// It returns false when the writer fails or the replay has caught up.
+func (s *SpillWriter) replayBatch() bool {
+	for i := 0; i < replayBatchSize; i++ {
+		e, ok := s.queue.Peek()
+		if !ok {
+			s.catchUp()
+			return false
+		}
+
+		if err := s.writer.Write(e); err != nil {
+			return false
+		}
+
+		s.queue.Pop()
+		atomic.AddUint64(&s.replayed, 1)
+	}
+
+	return true
+}
+
+// catchUp lets envelopes be written straight through again if nothing has
+// been spilled since the queue was found empty.
+func (s *SpillWriter) catchUp() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.queue.Peek(); !ok {
+		s.spilling = false
+	}
+}
+
+func (s *SpillWriter) emitMetrics() {
+	for range time.Tick(s.metricInterval) {
+		spilled := atomic.SwapUint64(&s.spilled, 0)
+		replayed := atomic.SwapUint64(&s.replayed, 0)
+		depth := s.queue.Len()
+
+		s.emitter.SetGauge("spill_queue_depth", float64(depth), "envelopes",
+			metric.WithVersion(2, 0),
+		)
+
+		if spilled == 0 && replayed == 0 && depth == 0 {
+			continue
+		}
+
+		s.emitter.IncCounter("spilled",
+			metric.WithIncrement(spilled),
+			metric.WithVersion(2, 0),
+		)
+		s.emitter.IncCounter("replayed",
+			metric.WithIncrement(replayed),
+			metric.WithVersion(2, 0),
+		)
+		log.Printf("spill queue (v2): spilled %d, replayed %d, depth %d", spilled, replayed, depth)
+	}
+}
+
+// packageEmitter emits metrics with the metric package.
+type packageEmitter struct{}
+
+func (packageEmitter) IncCounter(name string, options ...metric.IncrementOpt) {
+	metric.IncCounter(name, options...)
+}
+
+func (packageEmitter) SetGauge(name string, value float64, unit string, options ...metric.IncrementOpt) {
+	metric.SetGauge(name, value, unit, options...)
+}
@@ -0,0 +1,134 @@
+package v2_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/apoydence/eachers/testhelpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	egress "metron/egress/v2"
+	plumbing "plumbing/v2"
+)
+
+var _ = Describe("SpillWriter", func() {
+	var (
+		mockWriter *mockWriter
+		mockQueue  *mockSpillQueue
+		writer     *egress.SpillWriter
+		envelope   *plumbing.Envelope
+	)
+
+	BeforeEach(func() {
+		mockWriter = newMockWriter()
+		mockQueue = newMockSpillQueue()
+		envelope = &plumbing.Envelope{SourceId: "some-id"}
+	})
+
+	Context("when nothing has been spilled", func() {
+		BeforeEach(func() {
+			close(mockQueue.PeekOutput.Ret0)
+			close(mockQueue.PeekOutput.Ret1)
+			mockQueue.LenOutput.Ret0 <- 0
+			writer = egress.NewSpillWriter(mockWriter, mockQueue, time.Millisecond)
+		})
+
+		It("writes the envelope to the writer", func() {
+			mockWriter.WriteOutput.Ret0 <- nil
+
+			Expect(writer.Write(envelope)).To(Succeed())
+			Expect(mockWriter.WriteInput.Msg).To(Receive(Equal(envelope)))
+			Expect(mockQueue.PushCalled).To(BeEmpty())
+		})
+
+		It("spills the envelope when the writer fails", func() {
+			mockWriter.WriteOutput.Ret0 <- errors.New("some-error")
+			mockQueue.PushOutput.Ret0 <- nil
+
+			Expect(writer.Write(envelope)).To(Succeed())
+			Expect(mockQueue.PushInput.E).To(Receive(Equal(envelope)))
+		})
+
+		It("returns an error when the envelope can not be spilled", func() {
+			mockWriter.WriteOutput.Ret0 <- errors.New("some-error")
+			mockQueue.PushOutput.Ret0 <- errors.New("some-error")
+
+			Expect(writer.Write(envelope)).ToNot(Succeed())
+		})
+	})
+
+	Context("when envelopes have been spilled", func() {
+		It("spills the envelope without writing it", func() {
+			spilled := &plumbing.Envelope{SourceId: "spilled-id"}
+			testhelpers.AlwaysReturn(mockQueue.PeekOutput.Ret0, spilled)
+			testhelpers.AlwaysReturn(mockQueue.PeekOutput.Ret1, true)
+			testhelpers.AlwaysReturn(mockWriter.WriteOutput.Ret0, errors.New("some-error"))
+			mockQueue.LenOutput.Ret0 <- 1
+			mockQueue.PushOutput.Ret0 <- nil
+			writer = egress.NewSpillWriter(mockWriter, mockQueue, time.Millisecond)
+
+			Expect(writer.Write(envelope)).To(Succeed())
+			Expect(mockQueue.PushInput.E).To(Receive(Equal(envelope)))
+			Consistently(mockWriter.WriteInput.Msg).ShouldNot(Receive(Equal(envelope)))
+		})
+
+		It("replays spilled envelopes to the writer", func() {
+			mockQueue.PeekOutput.Ret0 <- envelope
+			mockQueue.PeekOutput.Ret1 <- true
+			close(mockQueue.PeekOutput.Ret0)
+			close(mockQueue.PeekOutput.Ret1)
+			mockQueue.LenOutput.Ret0 <- 1
+			mockWriter.WriteOutput.Ret0 <- nil
+			writer = egress.NewSpillWriter(mockWriter, mockQueue, time.Millisecond)
+
+			Eventually(mockWriter.WriteInput.Msg).Should(Receive(Equal(envelope)))
+			Eventually(mockQueue.PopCalled).Should(Receive())
+		})
+
+		It("writes envelopes straight through once the replay has caught up", func() {
+			spilled := &plumbing.Envelope{SourceId: "spilled-id"}
+			mockQueue.PeekOutput.Ret0 <- spilled
+			mockQueue.PeekOutput.Ret1 <- true
+			close(mockQueue.PeekOutput.Ret0)
+			close(mockQueue.PeekOutput.Ret1)
+			mockQueue.LenOutput.Ret0 <- 1
+			testhelpers.AlwaysReturn(mockWriter.WriteOutput.Ret0, nil)
+			writer = egress.NewSpillWriter(mockWriter, mockQueue, time.Millisecond)
+
+			Eventually(mockWriter.WriteInput.Msg).Should(Receive(Equal(spilled)))
+			Eventually(mockQueue.PeekCalled).Should(HaveLen(3))
+
+			Expect(writer.Write(envelope)).To(Succeed())
+			Expect(mockWriter.WriteInput.Msg).To(Receive(Equal(envelope)))
+			Expect(mockQueue.PushCalled).To(BeEmpty())
+		})
+
+		It("keeps the envelope spilled while the writer fails", func() {
+			for i := 0; i < 5; i++ {
+				mockQueue.PeekOutput.Ret0 <- envelope
+				mockQueue.PeekOutput.Ret1 <- true
+				mockWriter.WriteOutput.Ret0 <- errors.New("some-error")
+			}
+			mockQueue.LenOutput.Ret0 <- 1
+			writer = egress.NewSpillWriter(mockWriter, mockQueue, time.Millisecond)
+
+			Eventually(mockWriter.WriteCalled).Should(HaveLen(5))
+			Consistently(mockQueue.PopCalled).Should(BeEmpty())
+		})
+	})
+
+	It("emits the depth of the queue", func() {
+		close(mockQueue.PeekOutput.Ret0)
+		close(mockQueue.PeekOutput.Ret1)
+		testhelpers.AlwaysReturn(mockQueue.LenOutput.Ret0, 7)
+		emitter := newMockMetricEmitter()
+		egress.NewSpillWriter(mockWriter, mockQueue, time.Millisecond,
+			egress.WithSpillMetricInterval(time.Millisecond),
+			egress.WithSpillMetricEmitter(emitter),
+		)
+
+		Eventually(emitter.SetGaugeInput.Name).Should(Receive(Equal("spill_queue_depth")))
+		Expect(emitter.SetGaugeInput.Value).To(Receive(Equal(7.0)))
+	})
+})
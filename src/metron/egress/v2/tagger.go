@@ -0,0 +1,54 @@
+package v2
+
+import (
+	plumbing "plumbing/v2"
+
+	"code.cloudfoundry.org/localip"
+)
+
+// Tagger adds the identity of the host and any configured static tags to
+// envelopes. Tags that are already set on an envelope are left alone.
+type Tagger struct {
+	tags   map[string]string
+	writer Writer
+}
+
+// NewTagger returns a Tagger that adds the deployment, job, index, ip and
+// extra tags to each envelope before writing it to w.
+func NewTagger(deployment, job, index string, extraTags map[string]string, w Writer) *Tagger {
+	ip, _ := localip.LocalIP()
+
+	tags := make(map[string]string)
+	for k, v := range extraTags {
+		tags[k] = v
+	}
+	tags["deployment"] = deployment
+	tags["job"] = job
+	tags["index"] = index
+	tags["ip"] = ip
+
+	return &Tagger{
+		tags:   tags,
+		writer: w,
+	}
+}
+
+func (t *Tagger) Write(msg *plumbing.Envelope) error {
+	if msg.Tags == nil {
+		msg.Tags = make(map[string]*plumbing.Value)
+	}
+
+	for k, v := range t.tags {
+		if _, ok := msg.Tags[k]; ok {
+			continue
+		}
+
+		msg.Tags[k] = &plumbing.Value{
+			Data: &plumbing.Value_Text{
+				Text: v,
+			},
+		}
+	}
+
+	return t.writer.Write(msg)
+}
@@ -0,0 +1,86 @@
+package v2_test
+
+import (
+	egress "metron/egress/v2"
+	plumbing "plumbing/v2"
+
+	"code.cloudfoundry.org/localip"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tagger", func() {
+	var (
+		mockWriter *mockWriter
+		tagger     *egress.Tagger
+	)
+
+	BeforeEach(func() {
+		mockWriter = newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		tagger = egress.NewTagger(
+			"some-deployment",
+			"some-job",
+			"some-index",
+			map[string]string{"some-tag": "some-value"},
+			mockWriter,
+		)
+	})
+
+	It("adds the identity and extra tags to the envelope", func() {
+		err := tagger.Write(&plumbing.Envelope{SourceId: "some-id"})
+		Expect(err).ToNot(HaveOccurred())
+
+		ip, err := localip.LocalIP()
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-id"))
+		Expect(e.Tags).To(HaveLen(5))
+		Expect(e.Tags["deployment"].GetText()).To(Equal("some-deployment"))
+		Expect(e.Tags["job"].GetText()).To(Equal("some-job"))
+		Expect(e.Tags["index"].GetText()).To(Equal("some-index"))
+		Expect(e.Tags["ip"].GetText()).To(Equal(ip))
+		Expect(e.Tags["some-tag"].GetText()).To(Equal("some-value"))
+	})
+
+	It("does not overwrite tags that are already set", func() {
+		err := tagger.Write(&plumbing.Envelope{
+			Tags: map[string]*plumbing.Value{
+				"job": {
+					Data: &plumbing.Value_Text{Text: "other-job"},
+				},
+				"some-tag": {
+					Data: &plumbing.Value_Integer{Integer: 99},
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&e))
+		Expect(e.Tags["job"].GetText()).To(Equal("other-job"))
+		Expect(e.Tags["some-tag"].GetInteger()).To(Equal(int64(99)))
+		Expect(e.Tags["deployment"].GetText()).To(Equal("some-deployment"))
+	})
+
+	It("does not let extra tags replace the identity tags", func() {
+		tagger = egress.NewTagger(
+			"some-deployment",
+			"some-job",
+			"some-index",
+			map[string]string{"job": "other-job"},
+			mockWriter,
+		)
+
+		err := tagger.Write(&plumbing.Envelope{})
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&e))
+		Expect(e.Tags["job"].GetText()).To(Equal("some-job"))
+	})
+})
@@ -0,0 +1,221 @@
+package v2
+
+import (
+	"math"
+	"metric"
+	"sort"
+	"sync"
+	"time"
+
+	plumbing "plumbing/v2"
+)
+
+// histogramGrowth is the ratio between the bounds of neighbouring histogram
+// buckets. It keeps the relative error of a percentile under 1%.
+const histogramGrowth = 1.02
+
+var logHistogramGrowth = math.Log(histogramGrowth)
+
+type timerID struct {
+	sourceID string
+	name     string
+	tagsHash string
+}
+
+type timerSeries struct {
+	sourceID string
+	name     string
+	tags     map[string]*plumbing.Value
+	hist     *histogram
+}
+
+// TimerAggregator folds timers from opted in sources into a histogram per
+// source ID, name and tags. On each flush it writes a gauge envelope per
+// series with the count, sum, p50, p95 and p99 of the durations, in
+// nanoseconds. All other envelopes are passed through.
+//
+// Writes to the next writer are serialized, so the next writer does not
+// need to be safe for concurrent use.
+type TimerAggregator struct {
+	writer    Writer
+	sourceIDs map[string]bool
+	maxSeries int
+
+	mu     sync.Mutex
+	series map[timerID]*timerSeries
+}
+
+// TimerAggregatorOption configures a TimerAggregator.
+type TimerAggregatorOption func(*TimerAggregator)
+
+// WithMaxTimerSeries sets the number of series that are aggregated between
+// flushes. Timers for new series beyond the limit are passed through. It
+// defaults to 10000.
+func WithMaxTimerSeries(n int) TimerAggregatorOption {
+	return func(ta *TimerAggregator) {
+		ta.maxSeries = n
+	}
+}
+
+// NewTimerAggregator returns a TimerAggregator that aggregates timers from
+// the given source IDs.
+func NewTimerAggregator(w Writer, sourceIDs []string, opts ...TimerAggregatorOption) *TimerAggregator {
+	ta := &TimerAggregator{
+		writer:    w,
+		sourceIDs: make(map[string]bool),
+		maxSeries: 10000,
+		series:    make(map[timerID]*timerSeries),
+	}
+	for _, id := range sourceIDs {
+		ta.sourceIDs[id] = true
+	}
+	for _, o := range opts {
+		o(ta)
+	}
+	return ta
+}
+
+func (ta *TimerAggregator) Write(msg *plumbing.Envelope) error {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	timer := msg.GetTimer()
+	if timer == nil || !ta.sourceIDs[msg.SourceId] {
+		return ta.writer.Write(msg)
+	}
+
+	id := timerID{
+		sourceID: msg.SourceId,
+		name:     timer.Name,
+		tagsHash: hashTags(msg.GetTags()),
+	}
+
+	s, ok := ta.series[id]
+	if !ok {
+		if len(ta.series) >= ta.maxSeries {
+			return ta.writer.Write(msg)
+		}
+
+		s = &timerSeries{
+			sourceID: msg.SourceId,
+			name:     timer.Name,
+			tags:     msg.GetTags(),
+			hist:     newHistogram(),
+		}
+		ta.series[id] = s
+	}
+	s.hist.record(timer.Stop - timer.Start)
+
+	return nil
+}
+
+// Start flushes on the given interval.
+func (ta *TimerAggregator) Start(interval time.Duration) {
+	for range time.Tick(interval) {
+		ta.Flush()
+	}
+}
+
+// Flush writes a summary of each series and resets the histograms.
+func (ta *TimerAggregator) Flush() {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	for id, s := range ta.series {
+		delete(ta.series, id)
+
+		err := ta.writer.Write(s.envelope(now))
+		if err != nil {
+			metric.IncCounter("dropped",
+				metric.WithVersion(2, 0),
+				metric.WithTag("direction", "egress"),
+			)
+		}
+	}
+}
+
+func (s *timerSeries) envelope(timestamp int64) *plumbing.Envelope {
+	h := s.hist
+	return &plumbing.Envelope{
+		Timestamp: timestamp,
+		SourceId:  s.sourceID,
+		Tags:      s.tags,
+		Message: &plumbing.Envelope_Gauge{
+			Gauge: &plumbing.Gauge{
+				Metrics: map[string]*plumbing.GaugeValue{
+					s.name + ".count": {Unit: "count", Value: float64(h.count)},
+					s.name + ".sum":   {Unit: "ns", Value: h.sum},
+					s.name + ".p50":   {Unit: "ns", Value: h.quantile(0.5)},
+					s.name + ".p95":   {Unit: "ns", Value: h.quantile(0.95)},
+					s.name + ".p99":   {Unit: "ns", Value: h.quantile(0.99)},
+				},
+			},
+		},
+	}
+}
+
+// histogram counts durations in exponentially sized buckets.
+type histogram struct {
+	buckets map[int]uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		buckets: make(map[int]uint64),
+	}
+}
+
+func (h *histogram) record(d int64) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.buckets[bucketIndex(d)]++
+	h.count++
+	h.sum += float64(d)
+}
+
+// quantile returns an estimate of the duration below which q of the
+// recorded durations fall.
+func (h *histogram) quantile(q float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+
+	indexes := make([]int, 0, len(h.buckets))
+	for i := range h.buckets {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	var seen uint64
+	for _, i := range indexes {
+		seen += h.buckets[i]
+		if seen >= rank {
+			return bucketValue(i)
+		}
+	}
+	return bucketValue(indexes[len(indexes)-1])
+}
+
+// bucketIndex returns the index of the bucket that holds d. Bucket i holds
+// durations in (growth^(i-1), growth^i]. Zero has a bucket of its own.
+func bucketIndex(d int64) int {
+	if d == 0 {
+		return -1
+	}
+	return int(math.Ceil(math.Log(float64(d)) / logHistogramGrowth))
+}
+
+// bucketValue returns the midpoint of a bucket.
+func bucketValue(i int) float64 {
+	if i < 0 {
+		return 0
+	}
+	upper := math.Pow(histogramGrowth, float64(i))
+	return (upper + upper/histogramGrowth) / 2
+}
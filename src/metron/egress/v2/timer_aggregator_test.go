@@ -0,0 +1,119 @@
+package v2_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	egress "metron/egress/v2"
+	plumbing "plumbing/v2"
+)
+
+var _ = Describe("TimerAggregator", func() {
+	var (
+		mockWriter *mockWriter
+		aggregator *egress.TimerAggregator
+	)
+
+	BeforeEach(func() {
+		mockWriter = newMockWriter()
+		close(mockWriter.WriteOutput.Ret0)
+
+		aggregator = egress.NewTimerAggregator(mockWriter, []string{"gorouter"})
+	})
+
+	It("passes through timers from sources that are not opted in", func() {
+		e := buildTimerEnvelope("other-source", "http", time.Millisecond, nil)
+
+		Expect(aggregator.Write(e)).To(Succeed())
+
+		Expect(mockWriter.WriteInput.Msg).To(Receive(Equal(e)))
+	})
+
+	It("passes through envelopes that are not timers", func() {
+		e := buildCounterEnvelope(10, "name-1", "origin-1")
+		e.SourceId = "gorouter"
+
+		Expect(aggregator.Write(e)).To(Succeed())
+
+		Expect(mockWriter.WriteInput.Msg).To(Receive(Equal(e)))
+	})
+
+	It("writes a summary of the timers on flush", func() {
+		tags := map[string]*plumbing.Value{
+			"uri": {Data: &plumbing.Value_Text{Text: "/v2/apps"}},
+		}
+		for i := 1; i <= 100; i++ {
+			e := buildTimerEnvelope("gorouter", "http", time.Duration(i)*time.Millisecond, tags)
+			Expect(aggregator.Write(e)).To(Succeed())
+		}
+		Expect(mockWriter.WriteCalled).To(BeEmpty())
+
+		aggregator.Flush()
+
+		var e *plumbing.Envelope
+		Expect(mockWriter.WriteInput.Msg).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("gorouter"))
+		Expect(e.Tags).To(Equal(tags))
+
+		metrics := e.GetGauge().Metrics
+		Expect(metrics).To(HaveLen(5))
+		Expect(metrics["http.count"]).To(Equal(&plumbing.GaugeValue{Unit: "count", Value: 100}))
+		Expect(metrics["http.sum"]).To(Equal(&plumbing.GaugeValue{Unit: "ns", Value: float64(5050 * time.Millisecond)}))
+		Expect(metrics["http.p50"].Unit).To(Equal("ns"))
+		Expect(metrics["http.p50"].Value).To(BeNumerically("~", float64(50*time.Millisecond), float64(time.Millisecond)))
+		Expect(metrics["http.p95"].Value).To(BeNumerically("~", float64(95*time.Millisecond), float64(time.Millisecond)))
+		Expect(metrics["http.p99"].Value).To(BeNumerically("~", float64(99*time.Millisecond), float64(time.Millisecond)))
+	})
+
+	It("keeps a series for each name and set of tags", func() {
+		aggregator.Write(buildTimerEnvelope("gorouter", "http", time.Millisecond, nil))
+		aggregator.Write(buildTimerEnvelope("gorouter", "other", time.Millisecond, nil))
+		aggregator.Write(buildTimerEnvelope("gorouter", "http", time.Millisecond, map[string]*plumbing.Value{
+			"uri": {Data: &plumbing.Value_Text{Text: "/v2/apps"}},
+		}))
+
+		aggregator.Flush()
+
+		Expect(mockWriter.WriteInput.Msg).To(HaveLen(3))
+	})
+
+	It("resets the series after a flush", func() {
+		aggregator.Write(buildTimerEnvelope("gorouter", "http", time.Millisecond, nil))
+		aggregator.Flush()
+		Expect(mockWriter.WriteInput.Msg).To(HaveLen(1))
+
+		aggregator.Flush()
+
+		Expect(mockWriter.WriteInput.Msg).To(HaveLen(1))
+	})
+
+	It("passes through timers for new series beyond the limit", func() {
+		aggregator = egress.NewTimerAggregator(
+			mockWriter,
+			[]string{"gorouter"},
+			egress.WithMaxTimerSeries(1),
+		)
+
+		aggregator.Write(buildTimerEnvelope("gorouter", "http", time.Millisecond, nil))
+		e := buildTimerEnvelope("gorouter", "other", time.Millisecond, nil)
+		aggregator.Write(e)
+
+		Expect(mockWriter.WriteInput.Msg).To(Receive(Equal(e)))
+	})
+})
+
+func buildTimerEnvelope(sourceID, name string, d time.Duration, tags map[string]*plumbing.Value) *plumbing.Envelope {
+	return &plumbing.Envelope{
+		SourceId: sourceID,
+		Tags:     tags,
+		Message: &plumbing.Envelope_Timer{
+			Timer: &plumbing.Timer{
+				Name:  name,
+				Start: 1000,
+				Stop:  1000 + int64(d),
+			},
+		},
+	}
+}
@@ -0,0 +1,200 @@
+// Package filter drops and rewrites envelopes according to configured rules.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"metric"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// Rule describes which envelopes to match and what to do with them. Every
+// match field that is set must match for the rule to apply.
+type Rule struct {
+	Name string
+
+	EnvelopeType string
+	MetricName   string
+	SourceID     string
+	Tags         map[string]string
+
+	Drop         bool
+	Replacements []Replacement
+	AddTags      map[string]string
+	RemoveTags   []string
+}
+
+// Replacement replaces every match of Pattern in a log payload. With may
+// refer to submatches as described by regexp.Regexp.Expand.
+type Replacement struct {
+	Pattern string
+	With    string
+}
+
+// Envelope is the view of a v1 or v2 envelope that rules work on.
+type Envelope interface {
+	// Type is one of log, counter, gauge, timer or error.
+	Type() string
+	// Names returns the metric names the envelope holds.
+	Names() []string
+	SourceID() string
+	Tag(name string) (string, bool)
+	SetTag(name, value string)
+	RemoveTag(name string)
+	// Payload returns the log payload, or nil if the envelope is not a log.
+	Payload() []byte
+	SetPayload(p []byte)
+}
+
+var envelopeTypes = map[string]bool{
+	"log":     true,
+	"counter": true,
+	"gauge":   true,
+	"timer":   true,
+	"error":   true,
+}
+
+// Engine applies rules to envelopes in the order they were given.
+type Engine struct {
+	rules []*rule
+}
+
+type rule struct {
+	Rule
+	patterns []*regexp.Regexp
+	matches  uint64
+	reported uint64
+}
+
+// New returns an Engine for the given rules. It returns an error if a rule
+// has no name, matches an unknown envelope type or has an invalid pattern.
+func New(rules []Rule) (*Engine, error) {
+	e := &Engine{}
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, errors.New("filter rules must have a name")
+		}
+
+		if r.EnvelopeType != "" && !envelopeTypes[r.EnvelopeType] {
+			return nil, fmt.Errorf("filter rule %s: unknown envelope type %q", r.Name, r.EnvelopeType)
+		}
+
+		compiled := &rule{Rule: r}
+		for _, repl := range r.Replacements {
+			p, err := regexp.Compile(repl.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("filter rule %s: %s", r.Name, err)
+			}
+			compiled.patterns = append(compiled.patterns, p)
+		}
+
+		e.rules = append(e.rules, compiled)
+	}
+
+	return e, nil
+}
+
+// Apply runs every matching rule against the envelope. It returns false if
+// the envelope should be dropped. No further rules are run once an envelope
+// is dropped.
+func (e *Engine) Apply(env Envelope) bool {
+	for _, r := range e.rules {
+		if !r.match(env) {
+			continue
+		}
+		atomic.AddUint64(&r.matches, 1)
+
+		if r.Drop {
+			return false
+		}
+		r.apply(env)
+	}
+
+	return true
+}
+
+// Matches returns the number of envelopes each rule has matched, keyed by
+// rule name.
+func (e *Engine) Matches() map[string]uint64 {
+	m := make(map[string]uint64, len(e.rules))
+	for _, r := range e.rules {
+		m[r.Name] += atomic.LoadUint64(&r.matches)
+	}
+	return m
+}
+
+// Start emits a filter_matches metric for each rule on the given interval.
+func (e *Engine) Start(interval time.Duration) {
+	for range time.Tick(interval) {
+		e.report()
+	}
+}
+
+func (e *Engine) report() {
+	for _, r := range e.rules {
+		total := atomic.LoadUint64(&r.matches)
+		delta := total - r.reported
+		if delta == 0 {
+			continue
+		}
+		r.reported = total
+
+		metric.IncCounter("filter_matches",
+			metric.WithIncrement(delta),
+			metric.WithVersion(2, 0),
+			metric.WithTag("rule", r.Name),
+		)
+	}
+}
+
+func (r *rule) match(env Envelope) bool {
+	if r.EnvelopeType != "" && r.EnvelopeType != env.Type() {
+		return false
+	}
+
+	if r.SourceID != "" && r.SourceID != env.SourceID() {
+		return false
+	}
+
+	if r.MetricName != "" && !contains(env.Names(), r.MetricName) {
+		return false
+	}
+
+	for k, v := range r.Tags {
+		if actual, ok := env.Tag(k); !ok || actual != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *rule) apply(env Envelope) {
+	if len(r.patterns) > 0 {
+		if payload := env.Payload(); payload != nil {
+			for i, p := range r.patterns {
+				payload = p.ReplaceAll(payload, []byte(r.Replacements[i].With))
+			}
+			env.SetPayload(payload)
+		}
+	}
+
+	for _, k := range r.RemoveTags {
+		env.RemoveTag(k)
+	}
+
+	for k, v := range r.AddTags {
+		env.SetTag(k, v)
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
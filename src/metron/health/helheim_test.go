@@ -0,0 +1,64 @@
+// This file was generated by github.com/nelsam/hel.  Do not
+// edit this code by hand unless you *really* know what you're
+// doing.  Expect any changes made manually to be overwritten
+// the next time hel regenerates this file.
+
+package health_test
+
+import "metron/health"
+
+type mockConnReporter struct {
+	StatusCalled chan bool
+	StatusOutput struct {
+		Ret0 chan health.ConnStatus
+	}
+}
+
+func newMockConnReporter() *mockConnReporter {
+	m := &mockConnReporter{}
+	m.StatusCalled = make(chan bool, 100)
+	m.StatusOutput.Ret0 = make(chan health.ConnStatus, 100)
+	return m
+}
+func (m *mockConnReporter) Status() health.ConnStatus {
+	m.StatusCalled <- true
+	return <-m.StatusOutput.Ret0
+}
+
+type mockDiode struct {
+	LenCalled chan bool
+	LenOutput struct {
+		Ret0 chan int
+	}
+	CapCalled chan bool
+	CapOutput struct {
+		Ret0 chan int
+	}
+	DroppedCalled chan bool
+	DroppedOutput struct {
+		Ret0 chan uint64
+	}
+}
+
+func newMockDiode() *mockDiode {
+	m := &mockDiode{}
+	m.LenCalled = make(chan bool, 100)
+	m.LenOutput.Ret0 = make(chan int, 100)
+	m.CapCalled = make(chan bool, 100)
+	m.CapOutput.Ret0 = make(chan int, 100)
+	m.DroppedCalled = make(chan bool, 100)
+	m.DroppedOutput.Ret0 = make(chan uint64, 100)
+	return m
+}
+func (m *mockDiode) Len() int {
+	m.LenCalled <- true
+	return <-m.LenOutput.Ret0
+}
+func (m *mockDiode) Cap() int {
+	m.CapCalled <- true
+	return <-m.CapOutput.Ret0
+}
+func (m *mockDiode) Dropped() uint64 {
+	m.DroppedCalled <- true
+	return <-m.DroppedOutput.Ret0
+}
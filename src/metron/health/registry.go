@@ -0,0 +1,121 @@
+package health
+
+import "sync"
+
+// ConnStatus describes the state of a single connection to a doppler.
+type ConnStatus struct {
+	Connected bool   `json:"connected"`
+	Doppler   string `json:"doppler"`
+	Writes    int64  `json:"writes"`
+	MaxWrites int64  `json:"max_writes"`
+}
+
+// ConnReporter reports the state of a connection.
+type ConnReporter interface {
+	Status() ConnStatus
+}
+
+// Diode is a buffer that can report how full it is and how much it has
+// dropped.
+type Diode interface {
+	Len() int
+	Cap() int
+	Dropped() uint64
+}
+
+// DiodeStatus describes the fill level of a diode.
+type DiodeStatus struct {
+	Len     int    `json:"len"`
+	Cap     int    `json:"cap"`
+	Dropped uint64 `json:"dropped"`
+}
+
+// Status is a snapshot of everything registered with a Registry.
+type Status struct {
+	Ready       bool                    `json:"ready"`
+	Connections map[string][]ConnStatus `json:"connections"`
+	Diodes      map[string]DiodeStatus  `json:"diodes"`
+}
+
+type connGroup struct {
+	required bool
+	conns    []ConnReporter
+}
+
+// Registry collects the connections and diodes of a running metron.
+type Registry struct {
+	mu     sync.RWMutex
+	groups map[string]connGroup
+	diodes map[string]Diode
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		groups: make(map[string]connGroup),
+		diodes: make(map[string]Diode),
+	}
+}
+
+// RegisterConns adds a named group of connections. Metron is only ready when
+// every required group has at least one connected connection.
+func (r *Registry) RegisterConns(group string, required bool, conns ...ConnReporter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.groups[group] = connGroup{
+		required: required,
+		conns:    conns,
+	}
+}
+
+// RegisterDiode adds a named diode.
+func (r *Registry) RegisterDiode(name string, d Diode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.diodes[name] = d
+}
+
+// Status returns the current state of every registered connection and diode.
+// Nothing is ready until at least one required group has been registered.
+func (r *Registry) Status() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s := Status{
+		Connections: make(map[string][]ConnStatus),
+		Diodes:      make(map[string]DiodeStatus),
+	}
+
+	var required, connected int
+	for name, g := range r.groups {
+		var anyConnected bool
+		statuses := make([]ConnStatus, 0, len(g.conns))
+		for _, c := range g.conns {
+			cs := c.Status()
+			anyConnected = anyConnected || cs.Connected
+			statuses = append(statuses, cs)
+		}
+		s.Connections[name] = statuses
+
+		if !g.required {
+			continue
+		}
+		required++
+		if anyConnected {
+			connected++
+		}
+	}
+	s.Ready = required > 0 && required == connected
+
+	for name, d := range r.diodes {
+		s.Diodes[name] = DiodeStatus{
+			Len:     d.Len(),
+			Cap:     d.Cap(),
+			Dropped: d.Dropped(),
+		}
+	}
+
+	return s
+}
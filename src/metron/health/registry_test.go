@@ -0,0 +1,66 @@
+//go:generate hel
+
+package health_test
+
+import (
+	"metron/health"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var (
+		registry *health.Registry
+	)
+
+	BeforeEach(func() {
+		registry = health.NewRegistry()
+	})
+
+	It("is not ready when nothing is registered", func() {
+		Expect(registry.Status().Ready).To(BeFalse())
+	})
+
+	It("reports the status of each connection by group", func() {
+		a, b := newMockConnReporter(), newMockConnReporter()
+		a.StatusOutput.Ret0 <- health.ConnStatus{Connected: true, Doppler: "doppler-a", Writes: 3, MaxWrites: 10}
+		b.StatusOutput.Ret0 <- health.ConnStatus{Doppler: "doppler-b", MaxWrites: 10}
+		registry.RegisterConns("v2", true, a, b)
+
+		Expect(registry.Status().Connections).To(Equal(map[string][]health.ConnStatus{
+			"v2": {
+				{Connected: true, Doppler: "doppler-a", Writes: 3, MaxWrites: 10},
+				{Doppler: "doppler-b", MaxWrites: 10},
+			},
+		}))
+	})
+
+	It("is ready when every required group has a connection", func() {
+		a, b, c := newMockConnReporter(), newMockConnReporter(), newMockConnReporter()
+		a.StatusOutput.Ret0 <- health.ConnStatus{}
+		a.StatusOutput.Ret0 <- health.ConnStatus{Connected: true}
+		b.StatusOutput.Ret0 <- health.ConnStatus{Connected: true}
+		b.StatusOutput.Ret0 <- health.ConnStatus{Connected: true}
+		c.StatusOutput.Ret0 <- health.ConnStatus{}
+		c.StatusOutput.Ret0 <- health.ConnStatus{}
+		registry.RegisterConns("v1", true, a)
+		registry.RegisterConns("v2", true, b)
+		registry.RegisterConns("udp", false, c)
+
+		Expect(registry.Status().Ready).To(BeFalse())
+		Expect(registry.Status().Ready).To(BeTrue())
+	})
+
+	It("reports the fill level of each diode", func() {
+		d := newMockDiode()
+		d.LenOutput.Ret0 <- 5
+		d.CapOutput.Ret0 <- 10
+		d.DroppedOutput.Ret0 <- 99
+		registry.RegisterDiode("ingress", d)
+
+		Expect(registry.Status().Diodes).To(Equal(map[string]health.DiodeStatus{
+			"ingress": {Len: 5, Cap: 10, Dropped: 99},
+		}))
+	})
+})
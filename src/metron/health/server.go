@@ -0,0 +1,77 @@
+package health
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+)
+
+// Server serves the state of a Registry as JSON.
+type Server struct {
+	lis      net.Listener
+	registry *Registry
+}
+
+// NewServer binds to the given address.
+func NewServer(addr string, r *Registry) (*Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Serving health on %s", lis.Addr())
+
+	return &Server{
+		lis:      lis,
+		registry: r,
+	}, nil
+}
+
+// Addr returns the address the server is bound to.
+func (s *Server) Addr() net.Addr {
+	return s.lis.Addr()
+}
+
+// Start serves requests until the server is stopped. /health responds with a
+// 503 until metron is ready. /status always responds with a 200 and the full
+// state.
+func (s *Server) Start() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.health)
+	mux.HandleFunc("/status", s.status)
+
+	err := http.Serve(s.lis, mux)
+	if err != nil {
+		log.Printf("Health server stopped: %s", err)
+	}
+}
+
+// Stop closes the listener.
+func (s *Server) Stop() {
+	s.lis.Close()
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	status := s.registry.Status()
+
+	code := http.StatusOK
+	if !status.Ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, struct {
+		Ready bool `json:"ready"`
+	}{status.Ready})
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.registry.Status())
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write health response: %s", err)
+	}
+}
@@ -0,0 +1,77 @@
+package health_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"metron/health"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		server *health.Server
+		conn   *mockConnReporter
+		url    string
+	)
+
+	BeforeEach(func() {
+		conn = newMockConnReporter()
+		registry := health.NewRegistry()
+		registry.RegisterConns("v2", true, conn)
+
+		var err error
+		server, err = health.NewServer("127.0.0.1:0", registry)
+		Expect(err).ToNot(HaveOccurred())
+		go server.Start()
+
+		url = fmt.Sprintf("http://%s", server.Addr())
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	Describe("/health", func() {
+		It("responds with a 200 when ready", func() {
+			conn.StatusOutput.Ret0 <- health.ConnStatus{Connected: true}
+
+			resp, err := http.Get(url + "/health")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+		})
+
+		It("responds with a 503 when not ready", func() {
+			conn.StatusOutput.Ret0 <- health.ConnStatus{}
+
+			resp, err := http.Get(url + "/health")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
+	Describe("/status", func() {
+		It("responds with the full status", func() {
+			conn.StatusOutput.Ret0 <- health.ConnStatus{Doppler: "doppler-a", MaxWrites: 10}
+
+			resp, err := http.Get(url + "/status")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var status health.Status
+			Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+			Expect(status.Ready).To(BeFalse())
+			Expect(status.Connections["v2"]).To(ConsistOf(
+				health.ConnStatus{Doppler: "doppler-a", MaxWrites: 10},
+			))
+		})
+	})
+})
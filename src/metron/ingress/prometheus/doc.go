@@ -0,0 +1,5 @@
+//go:generate hel
+
+// Package prometheus scrapes Prometheus text format endpoints and converts
+// the samples into v2 envelopes.
+package prometheus
@@ -0,0 +1,28 @@
+// This file was generated by github.com/nelsam/hel.  Do not
+// edit this code by hand unless you *really* know what you're
+// doing.  Expect any changes made manually to be overwritten
+// the next time hel regenerates this file.
+
+package prometheus_test
+
+import (
+	v2 "plumbing/v2"
+)
+
+type mockDataSetter struct {
+	SetCalled chan bool
+	SetInput  struct {
+		E chan *v2.Envelope
+	}
+}
+
+func newMockDataSetter() *mockDataSetter {
+	m := &mockDataSetter{}
+	m.SetCalled = make(chan bool, 100)
+	m.SetInput.E = make(chan *v2.Envelope, 100)
+	return m
+}
+func (m *mockDataSetter) Set(e *v2.Envelope) {
+	m.SetCalled <- true
+	m.SetInput.E <- e
+}
@@ -0,0 +1,197 @@
+package prometheus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// metricType is the type given by a "# TYPE" comment.
+type metricType string
+
+const (
+	counterType   metricType = "counter"
+	gaugeType     metricType = "gauge"
+	histogramType metricType = "histogram"
+	summaryType   metricType = "summary"
+	untypedType   metricType = "untyped"
+)
+
+// Sample is a single line of the text exposition format.
+type Sample struct {
+	Name   string
+	Type   metricType
+	Labels map[string]string
+	Value  float64
+
+	// Timestamp is in milliseconds since the epoch. It is zero when the
+	// sample has no timestamp.
+	Timestamp int64
+}
+
+// Parse reads samples in the Prometheus text exposition format.
+func Parse(r io.Reader) ([]Sample, error) {
+	types := make(map[string]metricType)
+
+	var samples []Sample
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			parseComment(line, types)
+			continue
+		}
+
+		s, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		s.Type = lookupType(s.Name, types)
+
+		samples = append(samples, s)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func parseComment(line string, types map[string]metricType) {
+	fields := strings.Fields(line[1:])
+	if len(fields) < 3 || fields[0] != "TYPE" {
+		return
+	}
+
+	types[fields[1]] = metricType(fields[2])
+}
+
+// lookupType finds the type of a sample. Histogram and summary samples use
+// suffixed names (e.g. _bucket) of the name given in the TYPE comment.
+func lookupType(name string, types map[string]metricType) metricType {
+	if t, ok := types[name]; ok {
+		return t
+	}
+
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+
+		t, ok := types[strings.TrimSuffix(name, suffix)]
+		if ok && (t == histogramType || t == summaryType) {
+			return t
+		}
+	}
+
+	return untypedType
+}
+
+func parseSample(line string) (Sample, error) {
+	s := Sample{
+		Labels: make(map[string]string),
+	}
+
+	end := strings.IndexAny(line, "{ \t")
+	if end < 1 {
+		return Sample{}, errors.New("invalid sample")
+	}
+	s.Name = line[:end]
+	rest := line[end:]
+
+	if rest[0] == '{' {
+		var err error
+		rest, err = parseLabels(rest[1:], s.Labels)
+		if err != nil {
+			return Sample{}, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return Sample{}, errors.New("invalid sample value")
+	}
+
+	var err error
+	s.Value, err = strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("invalid sample value: %s", err)
+	}
+
+	if len(fields) == 2 {
+		s.Timestamp, err = strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return Sample{}, fmt.Errorf("invalid sample timestamp: %s", err)
+		}
+	}
+
+	return s, nil
+}
+
+// parseLabels reads label pairs up to the closing brace and returns what is
+// left of the line.
+func parseLabels(line string, labels map[string]string) (string, error) {
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(line, "}") {
+			return line[1:], nil
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 1 {
+			return "", errors.New("invalid label")
+		}
+		name := strings.TrimSpace(line[:eq])
+
+		line = strings.TrimLeft(line[eq+1:], " \t")
+		if !strings.HasPrefix(line, `"`) {
+			return "", errors.New("invalid label value")
+		}
+
+		value, n, err := parseLabelValue(line[1:])
+		if err != nil {
+			return "", err
+		}
+		labels[name] = value
+
+		line = strings.TrimLeft(line[1+n:], " \t")
+		if strings.HasPrefix(line, ",") {
+			line = line[1:]
+		} else if !strings.HasPrefix(line, "}") {
+			return "", errors.New("invalid label separator")
+		}
+	}
+}
+
+// parseLabelValue reads a label value up to the closing quote. It returns
+// the unescaped value and the number of bytes read including the quote.
+func parseLabelValue(line string) (string, int, error) {
+	var value []byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch c {
+		case '"':
+			return string(value), i + 1, nil
+		case '\\':
+			if i+1 < len(line) {
+				i++
+				switch line[i] {
+				case 'n':
+					c = '\n'
+				default:
+					c = line[i]
+				}
+			}
+		}
+		value = append(value, c)
+	}
+
+	return "", 0, errors.New("unterminated label value")
+}
@@ -0,0 +1,84 @@
+package prometheus_test
+
+import (
+	"math"
+	"metron/ingress/prometheus"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("parses samples with their types and labels", func() {
+		text := `
+# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"}    3 1395066363000
+
+# TYPE memory_bytes gauge
+memory_bytes 1.5e+06
+
+untyped_metric{ label = "a\"b\\c\nd" , } -Inf
+`
+		samples, err := prometheus.Parse(strings.NewReader(text))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(HaveLen(4))
+
+		Expect(samples[0].Name).To(Equal("http_requests_total"))
+		Expect(samples[0].Labels).To(Equal(map[string]string{"method": "post", "code": "200"}))
+		Expect(samples[0].Value).To(Equal(1027.0))
+		Expect(samples[0].Timestamp).To(Equal(int64(1395066363000)))
+		Expect(string(samples[0].Type)).To(Equal("counter"))
+
+		Expect(samples[1].Value).To(Equal(3.0))
+
+		Expect(samples[2].Name).To(Equal("memory_bytes"))
+		Expect(samples[2].Labels).To(BeEmpty())
+		Expect(samples[2].Value).To(Equal(1.5e+06))
+		Expect(samples[2].Timestamp).To(BeZero())
+		Expect(string(samples[2].Type)).To(Equal("gauge"))
+
+		Expect(samples[3].Labels).To(Equal(map[string]string{"label": "a\"b\\c\nd"}))
+		Expect(math.IsInf(samples[3].Value, -1)).To(BeTrue())
+		Expect(string(samples[3].Type)).To(Equal("untyped"))
+	})
+
+	It("types histogram and summary samples by their base name", func() {
+		text := `# TYPE latency histogram
+latency_bucket{le="0.5"} 10
+latency_bucket{le="+Inf"} 12
+latency_sum 4.2
+latency_count 12
+# TYPE rpc summary
+rpc{quantile="0.99"} 0.3
+rpc_count 7
+other_count 1
+`
+		samples, err := prometheus.Parse(strings.NewReader(text))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(HaveLen(7))
+
+		for _, s := range samples[:4] {
+			Expect(string(s.Type)).To(Equal("histogram"))
+		}
+		Expect(samples[1].Labels["le"]).To(Equal("+Inf"))
+		Expect(string(samples[4].Type)).To(Equal("summary"))
+		Expect(string(samples[5].Type)).To(Equal("summary"))
+		Expect(string(samples[6].Type)).To(Equal("untyped"))
+	})
+
+	It("returns an error for invalid samples", func() {
+		for _, text := range []string{
+			"no_value",
+			"bad_value abc",
+			`bad_label{a=b} 1`,
+			`unterminated{a="b} 1`,
+			`bad_timestamp 1 abc`,
+		} {
+			_, err := prometheus.Parse(strings.NewReader(text))
+			Expect(err).To(HaveOccurred(), text)
+		}
+	})
+})
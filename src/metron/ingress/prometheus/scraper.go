@@ -0,0 +1,139 @@
+package prometheus
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"metric"
+	"net/http"
+	"time"
+
+	v2 "plumbing/v2"
+)
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// Target is an endpoint that is scraped. Envelopes from the target are given
+// the target's source ID.
+type Target struct {
+	URL      string
+	SourceID string
+}
+
+// Scraper periodically scrapes targets and writes the converted samples.
+// Gauges and untyped samples become gauges, counters become counters and
+// each sample of a histogram or summary becomes its own gauge. Labels become
+// tags.
+type Scraper struct {
+	targets  []Target
+	interval time.Duration
+	setter   DataSetter
+	client   *http.Client
+}
+
+// NewScraper returns a new unstarted Scraper.
+func NewScraper(targets []Target, interval time.Duration, s DataSetter) *Scraper {
+	return &Scraper{
+		targets:  targets,
+		interval: interval,
+		setter:   s,
+		client:   &http.Client{Timeout: interval},
+	}
+}
+
+// Start scrapes every target on the interval. It does not return.
+func (s *Scraper) Start() {
+	for range time.Tick(s.interval) {
+		s.Scrape()
+	}
+}
+
+// Scrape scrapes every target once.
+func (s *Scraper) Scrape() {
+	for _, t := range s.targets {
+		if err := s.scrape(t); err != nil {
+			log.Printf("Failed to scrape %s: %s", t.URL, err)
+			metric.IncCounter("scrape_errors",
+				metric.WithVersion(2, 0),
+				metric.WithTag("source_id", t.SourceID),
+			)
+		}
+	}
+}
+
+func (s *Scraper) scrape(t Target) error {
+	resp, err := s.client.Get(t.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	samples, err := Parse(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixNano()
+	for _, sample := range samples {
+		e := s.convert(t, sample)
+		if e == nil {
+			continue
+		}
+
+		e.Timestamp = now
+		if sample.Timestamp != 0 {
+			e.Timestamp = sample.Timestamp * int64(time.Millisecond)
+		}
+
+		s.setter.Set(e)
+	}
+
+	return nil
+}
+
+func (s *Scraper) convert(t Target, sample Sample) *v2.Envelope {
+	if math.IsNaN(sample.Value) {
+		return nil
+	}
+
+	e := &v2.Envelope{
+		SourceId: t.SourceID,
+		Tags:     make(map[string]*v2.Value),
+	}
+	for k, v := range sample.Labels {
+		e.Tags[k] = &v2.Value{
+			Data: &v2.Value_Text{
+				Text: v,
+			},
+		}
+	}
+
+	if sample.Type == counterType {
+		e.Message = &v2.Envelope_Counter{
+			Counter: &v2.Counter{
+				Name: sample.Name,
+				Value: &v2.Counter_Total{
+					Total: uint64(sample.Value),
+				},
+			},
+		}
+		return e
+	}
+
+	e.Message = &v2.Envelope_Gauge{
+		Gauge: &v2.Gauge{
+			Metrics: map[string]*v2.GaugeValue{
+				sample.Name: {
+					Value: sample.Value,
+				},
+			},
+		},
+	}
+	return e
+}
@@ -0,0 +1,105 @@
+package prometheus_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"metron/ingress/prometheus"
+	v2 "plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scraper", func() {
+	var (
+		mockDataSetter *mockDataSetter
+		bodies         chan string
+		server         *httptest.Server
+		scraper        *prometheus.Scraper
+	)
+
+	BeforeEach(func() {
+		mockDataSetter = newMockDataSetter()
+		bodies = make(chan string, 10)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, <-bodies)
+		}))
+
+		scraper = prometheus.NewScraper(
+			[]prometheus.Target{{URL: server.URL + "/metrics", SourceID: "some-source"}},
+			time.Second,
+			mockDataSetter,
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("converts gauges with labels as tags", func() {
+		bodies <- "# TYPE memory_bytes gauge\nmemory_bytes{pool=\"heap\"} 1024 1395066363000\n"
+		scraper.Scrape()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-source"))
+		Expect(e.Timestamp).To(Equal(int64(1395066363000) * int64(time.Millisecond)))
+		Expect(e.Tags["pool"].GetText()).To(Equal("heap"))
+		Expect(e.GetGauge().Metrics["memory_bytes"].Value).To(Equal(1024.0))
+	})
+
+	It("converts histogram samples into per-bucket gauges", func() {
+		bodies <- `# TYPE latency histogram
+latency_bucket{le="0.5"} 10
+latency_sum 4.2
+`
+		scraper.Scrape()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.Tags["le"].GetText()).To(Equal("0.5"))
+		Expect(e.GetGauge().Metrics["latency_bucket"].Value).To(Equal(10.0))
+
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["latency_sum"].Value).To(Equal(4.2))
+	})
+
+	It("converts counters into totals", func() {
+		bodies <- "# TYPE requests counter\nrequests{code=\"200\"} 10\n"
+		bodies <- "# TYPE requests counter\nrequests{code=\"200\"} 15\n"
+		bodies <- "# TYPE requests counter\nrequests{code=\"200\"} 4\n"
+
+		var totals []uint64
+		for i := 0; i < 3; i++ {
+			scraper.Scrape()
+
+			var e *v2.Envelope
+			Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+			Expect(e.GetCounter().Name).To(Equal("requests"))
+			Expect(e.Tags["code"].GetText()).To(Equal("200"))
+			totals = append(totals, e.GetCounter().GetTotal())
+		}
+
+		Expect(totals).To(Equal([]uint64{10, 15, 4}))
+	})
+
+	It("skips NaN samples", func() {
+		bodies <- "rpc{quantile=\"0.5\"} NaN\nrpc_count 0\n"
+		scraper.Scrape()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics).To(HaveKey("rpc_count"))
+		Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+	})
+
+	It("does not write anything when the target can not be parsed", func() {
+		bodies <- "requests 1\ninvalid\n"
+		scraper.Scrape()
+
+		Expect(mockDataSetter.SetCalled).ToNot(Receive())
+	})
+})
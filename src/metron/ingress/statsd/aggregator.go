@@ -0,0 +1,204 @@
+package statsd
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v2 "plumbing/v2"
+)
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// sourceIDTag may be given as a DogStatsD tag to set the source ID of the
+// envelope.
+const sourceIDTag = "source_id"
+
+// maxIdleFlushes is the number of flushes a gauge is kept without being
+// updated.
+const maxIdleFlushes = 10
+
+type counter struct {
+	Stat
+	total float64
+}
+
+type gauge struct {
+	Stat
+	updated bool
+	idle    int
+}
+
+// Aggregator collects stats between flushes. Counters are summed and written
+// as a delta, gauges are written with their latest value and timers are
+// written as they arrive.
+type Aggregator struct {
+	sourceID string
+	setter   DataSetter
+
+	mu       sync.Mutex
+	counters map[string]*counter
+	gauges   map[string]*gauge
+}
+
+// NewAggregator returns an Aggregator that writes envelopes with the given
+// default source ID.
+func NewAggregator(sourceID string, s DataSetter) *Aggregator {
+	return &Aggregator{
+		sourceID: sourceID,
+		setter:   s,
+		counters: make(map[string]*counter),
+		gauges:   make(map[string]*gauge),
+	}
+}
+
+// Start flushes on the interval. It does not return.
+func (a *Aggregator) Start(interval time.Duration) {
+	for range time.Tick(interval) {
+		a.Flush()
+	}
+}
+
+// Add adds the stat to the current flush. Timers are written immediately.
+func (a *Aggregator) Add(s Stat) {
+	if s.Type == timerStat {
+		a.setter.Set(a.timerEnvelope(s))
+		return
+	}
+
+	key := statKey(s)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch s.Type {
+	case counterStat:
+		c, ok := a.counters[key]
+		if !ok {
+			c = &counter{Stat: s}
+			a.counters[key] = c
+		}
+		c.total += s.Value / s.SampleRate
+	case gaugeStat:
+		g, ok := a.gauges[key]
+		if !ok {
+			g = &gauge{Stat: s}
+			a.gauges[key] = g
+		}
+
+		if s.Relative && ok {
+			g.Value += s.Value
+		} else {
+			g.Value = s.Value
+		}
+		g.updated = true
+		g.idle = 0
+	}
+}
+
+// Flush writes the counters and gauges that were updated since the last
+// flush. Gauges keep their value so that relative updates apply to it until
+// they have not been updated for several flushes.
+func (a *Aggregator) Flush() {
+	a.mu.Lock()
+	counters := a.counters
+	a.counters = make(map[string]*counter)
+
+	var gauges []Stat
+	for key, g := range a.gauges {
+		if !g.updated {
+			g.idle++
+			if g.idle >= maxIdleFlushes {
+				delete(a.gauges, key)
+			}
+			continue
+		}
+
+		gauges = append(gauges, g.Stat)
+		g.updated = false
+	}
+	a.mu.Unlock()
+
+	for _, c := range counters {
+		if c.total < 0 {
+			continue
+		}
+
+		e := a.envelope(c.Stat)
+		e.Message = &v2.Envelope_Counter{
+			Counter: &v2.Counter{
+				Name: c.Name,
+				Value: &v2.Counter_Delta{
+					Delta: uint64(math.Floor(c.total + 0.5)),
+				},
+			},
+		}
+		a.setter.Set(e)
+	}
+
+	for _, g := range gauges {
+		e := a.envelope(g)
+		e.Message = &v2.Envelope_Gauge{
+			Gauge: &v2.Gauge{
+				Metrics: map[string]*v2.GaugeValue{
+					g.Name: {
+						Value: g.Value,
+					},
+				},
+			},
+		}
+		a.setter.Set(e)
+	}
+}
+
+func (a *Aggregator) timerEnvelope(s Stat) *v2.Envelope {
+	stop := time.Now()
+	start := stop.Add(-time.Duration(s.Value * float64(time.Millisecond)))
+
+	e := a.envelope(s)
+	e.Timestamp = stop.UnixNano()
+	e.Message = &v2.Envelope_Timer{
+		Timer: &v2.Timer{
+			Name:  s.Name,
+			Start: start.UnixNano(),
+			Stop:  stop.UnixNano(),
+		},
+	}
+	return e
+}
+
+func (a *Aggregator) envelope(s Stat) *v2.Envelope {
+	e := &v2.Envelope{
+		Timestamp: time.Now().UnixNano(),
+		SourceId:  a.sourceID,
+		Tags:      make(map[string]*v2.Value),
+	}
+
+	for k, v := range s.Tags {
+		if k == sourceIDTag {
+			e.SourceId = v
+			continue
+		}
+
+		e.Tags[k] = &v2.Value{
+			Data: &v2.Value_Text{
+				Text: v,
+			},
+		}
+	}
+	return e
+}
+
+func statKey(s Stat) string {
+	tags := make([]string, 0, len(s.Tags))
+	for k, v := range s.Tags {
+		tags = append(tags, k+":"+v)
+	}
+	sort.Strings(tags)
+
+	return s.Name + "|" + strings.Join(tags, ",")
+}
@@ -0,0 +1,134 @@
+package statsd_test
+
+import (
+	"time"
+
+	"metron/ingress/statsd"
+	v2 "plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregator", func() {
+	var (
+		mockDataSetter *mockDataSetter
+		aggregator     *statsd.Aggregator
+	)
+
+	BeforeEach(func() {
+		mockDataSetter = newMockDataSetter()
+		aggregator = statsd.NewAggregator("statsd", mockDataSetter)
+	})
+
+	counter := func(name string, value, sampleRate float64, tags map[string]string) statsd.Stat {
+		return statsd.Stat{Name: name, Type: "c", Value: value, SampleRate: sampleRate, Tags: tags}
+	}
+
+	gauge := func(name string, value float64, relative bool) statsd.Stat {
+		return statsd.Stat{Name: name, Type: "g", Value: value, Relative: relative, SampleRate: 1}
+	}
+
+	It("sums counters scaled by their sample rate per flush", func() {
+		aggregator.Add(counter("requests", 1, 1, nil))
+		aggregator.Add(counter("requests", 1, 0.1, nil))
+		aggregator.Add(counter("requests", 2, 0.5, nil))
+		aggregator.Flush()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("statsd"))
+		Expect(e.GetCounter().Name).To(Equal("requests"))
+		Expect(e.GetCounter().GetDelta()).To(Equal(uint64(15)))
+		Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+
+		aggregator.Add(counter("requests", 3, 1, nil))
+		aggregator.Flush()
+
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetCounter().GetDelta()).To(Equal(uint64(3)))
+	})
+
+	It("aggregates counters with different tags separately", func() {
+		aggregator.Add(counter("requests", 1, 1, map[string]string{"env": "prod"}))
+		aggregator.Add(counter("requests", 2, 1, map[string]string{"env": "dev"}))
+		aggregator.Add(counter("requests", 4, 1, map[string]string{"env": "prod"}))
+		aggregator.Flush()
+
+		deltas := map[string]uint64{}
+		for i := 0; i < 2; i++ {
+			var e *v2.Envelope
+			Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+			deltas[e.Tags["env"].GetText()] = e.GetCounter().GetDelta()
+		}
+		Expect(deltas).To(Equal(map[string]uint64{"prod": 5, "dev": 2}))
+	})
+
+	It("writes nothing when nothing was added since the last flush", func() {
+		aggregator.Add(counter("requests", 1, 1, nil))
+		aggregator.Add(gauge("memory", 10, false))
+		aggregator.Flush()
+		Expect(mockDataSetter.SetInput.E).To(Receive())
+		Expect(mockDataSetter.SetInput.E).To(Receive())
+
+		aggregator.Flush()
+		Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+	})
+
+	It("writes the latest gauge value and applies relative changes across flushes", func() {
+		aggregator.Add(gauge("memory", 10, false))
+		aggregator.Add(gauge("memory", 20, false))
+		aggregator.Add(gauge("memory", -5, true))
+		aggregator.Flush()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["memory"].Value).To(Equal(15.0))
+
+		aggregator.Add(gauge("memory", 3, true))
+		aggregator.Flush()
+
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["memory"].Value).To(Equal(18.0))
+	})
+
+	It("sets a gauge that starts with a relative change", func() {
+		aggregator.Add(gauge("memory", -5, true))
+		aggregator.Flush()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["memory"].Value).To(Equal(-5.0))
+	})
+
+	It("forgets gauges that have not been updated for several flushes", func() {
+		aggregator.Add(gauge("memory", 10, false))
+		for i := 0; i < 11; i++ {
+			aggregator.Flush()
+		}
+		Expect(mockDataSetter.SetInput.E).To(Receive())
+
+		aggregator.Add(gauge("memory", 1, true))
+		aggregator.Flush()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["memory"].Value).To(Equal(1.0))
+	})
+
+	It("writes timers immediately", func() {
+		aggregator.Add(statsd.Stat{
+			Name:       "latency",
+			Type:       "ms",
+			Value:      250,
+			SampleRate: 1,
+			Tags:       map[string]string{"source_id": "my-app"},
+		})
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.SourceId).To(Equal("my-app"))
+		Expect(e.Tags).To(BeEmpty())
+		Expect(time.Duration(e.GetTimer().Stop - e.GetTimer().Start)).To(Equal(250 * time.Millisecond))
+	})
+})
@@ -0,0 +1,5 @@
+//go:generate hel
+
+// Package statsd accepts StatsD metrics, including DogStatsD tags, over UDP
+// and converts them into v2 envelopes.
+package statsd
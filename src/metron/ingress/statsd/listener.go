@@ -0,0 +1,86 @@
+package statsd
+
+import (
+	"log"
+	"metric"
+	"net"
+	"time"
+)
+
+// invalidLogInterval is the minimum time between logs of invalid metrics.
+const invalidLogInterval = time.Minute
+
+// UDPListener reads StatsD datagrams. Each datagram may hold several
+// newline separated metrics.
+type UDPListener struct {
+	conn       net.PacketConn
+	aggregator *Aggregator
+
+	invalidCount int
+	lastLogged   time.Time
+}
+
+// NewUDPListener binds to the given address.
+func NewUDPListener(addr string, a *Aggregator) (*UDPListener, error) {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Listening for statsd on %s", conn.LocalAddr())
+
+	return &UDPListener{
+		conn:       conn,
+		aggregator: a,
+	}, nil
+}
+
+// Addr returns the address the listener is bound to.
+func (l *UDPListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// Start reads datagrams until the listener is stopped.
+func (l *UDPListener) Start() {
+	buf := make([]byte, 65535) //buffer with size = max theoretical UDP size
+	for {
+		n, _, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			log.Printf("Error while reading statsd: %s", err)
+			return
+		}
+
+		stats, errs := Parse(string(buf[:n]))
+		l.invalid(errs)
+		for _, s := range stats {
+			l.aggregator.Add(s)
+		}
+	}
+}
+
+// Stop closes the listener.
+func (l *UDPListener) Stop() {
+	l.conn.Close()
+}
+
+// invalid counts the dropped invalid metrics. The drops are logged at most
+// once per invalidLogInterval along with the latest error.
+func (l *UDPListener) invalid(errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+
+	metric.IncCounter("dropped",
+		metric.WithIncrement(uint64(len(errs))),
+		metric.WithVersion(2, 0),
+		metric.WithTag("direction", "ingress"),
+		metric.WithTag("protocol", "statsd"),
+	)
+
+	l.invalidCount += len(errs)
+	if time.Since(l.lastLogged) < invalidLogInterval {
+		return
+	}
+	log.Printf("Dropped %d invalid statsd metrics, last error: %s", l.invalidCount, errs[len(errs)-1])
+	l.invalidCount = 0
+	l.lastLogged = time.Now()
+}
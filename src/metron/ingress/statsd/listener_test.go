@@ -0,0 +1,124 @@
+package statsd_test
+
+import (
+	"net"
+	"time"
+
+	"metron/ingress/statsd"
+	v2 "plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UDPListener", func() {
+	var (
+		mockDataSetter *mockDataSetter
+		aggregator     *statsd.Aggregator
+		listener       *statsd.UDPListener
+		conn           net.Conn
+	)
+
+	BeforeEach(func() {
+		mockDataSetter = newMockDataSetter()
+		aggregator = statsd.NewAggregator("statsd", mockDataSetter)
+
+		var err error
+		listener, err = statsd.NewUDPListener("127.0.0.1:0", aggregator)
+		Expect(err).ToNot(HaveOccurred())
+		go listener.Start()
+
+		conn, err = net.Dial("udp4", listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		listener.Stop()
+	})
+
+	// send writes the datagram followed by a marker timer so that the test
+	// can wait until the datagram has been read.
+	send := func(datagram string) {
+		_, err := conn.Write([]byte(datagram + "\nmarker:1|ms"))
+		Expect(err).ToNot(HaveOccurred())
+
+		for {
+			var e *v2.Envelope
+			Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+			if e.GetTimer() != nil && e.GetTimer().Name == "marker" {
+				return
+			}
+		}
+	}
+
+	It("sums counters with their sample rate until a flush", func() {
+		send("requests:1|c\nrequests:2|c|@0.5\nother:1|c|#env:prod")
+		aggregator.Flush()
+
+		counters := map[string]*v2.Envelope{}
+		for i := 0; i < 2; i++ {
+			var e *v2.Envelope
+			Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+			counters[e.GetCounter().Name] = e
+		}
+
+		Expect(counters["requests"].GetCounter().GetDelta()).To(Equal(uint64(5)))
+		Expect(counters["requests"].SourceId).To(Equal("statsd"))
+		Expect(counters["other"].GetCounter().GetDelta()).To(Equal(uint64(1)))
+		Expect(counters["other"].Tags["env"].GetText()).To(Equal("prod"))
+
+		aggregator.Flush()
+		Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+	})
+
+	It("keeps the latest gauge value and applies relative changes", func() {
+		send("memory:10|g\nmemory:20|g")
+		aggregator.Flush()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["memory"].Value).To(Equal(20.0))
+
+		aggregator.Flush()
+		Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+
+		send("memory:-5|g\nmemory:+1|g")
+		aggregator.Flush()
+
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetGauge().Metrics["memory"].Value).To(Equal(16.0))
+	})
+
+	It("writes timers as they arrive", func() {
+		_, err := conn.Write([]byte("latency:250|ms|#route:/v2,source_id:my-app"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *v2.Envelope
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("my-app"))
+		Expect(e.Tags).To(HaveLen(1))
+		Expect(e.Tags["route"].GetText()).To(Equal("/v2"))
+		Expect(e.GetTimer().Name).To(Equal("latency"))
+		Expect(time.Duration(e.GetTimer().Stop - e.GetTimer().Start)).To(Equal(250 * time.Millisecond))
+	})
+
+	It("accepts DogStatsD tags without values", func() {
+		send("requests:1|c|#canary")
+		aggregator.Flush()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.Tags).To(HaveKey("canary"))
+	})
+
+	It("drops invalid lines", func() {
+		send("nope\nsets:1|s\nbad:abc|c\nrate:1|c|@2\ngood:1|c")
+		aggregator.Flush()
+
+		var e *v2.Envelope
+		Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+		Expect(e.GetCounter().Name).To(Equal("good"))
+		Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+	})
+})
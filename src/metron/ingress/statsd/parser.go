@@ -0,0 +1,124 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// statType is the type section of a metric.
+type statType string
+
+const (
+	counterStat statType = "c"
+	gaugeStat   statType = "g"
+	timerStat   statType = "ms"
+)
+
+// Stat is a single StatsD metric.
+type Stat struct {
+	Name  string
+	Type  statType
+	Value float64
+
+	// Relative is set for gauges given with a sign, which change the
+	// current value rather than replace it.
+	Relative bool
+
+	SampleRate float64
+	Tags       map[string]string
+}
+
+// Parse parses a packet of newline separated StatsD metrics. Invalid lines
+// are skipped and an error is returned for each of them.
+func Parse(packet string) ([]Stat, []error) {
+	var (
+		stats []Stat
+		errs  []error
+	)
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		s, err := parseLine(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q: %s", line, err))
+			continue
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, errs
+}
+
+// parseLine parses a line of the form
+// <name>:<value>|<type>[|@<sample rate>][|#<tag>:<value>,<tag>].
+func parseLine(line string) (Stat, error) {
+	colon := strings.IndexByte(line, ':')
+	if colon < 1 {
+		return Stat{}, errors.New("missing metric name")
+	}
+
+	s := Stat{
+		Name:       line[:colon],
+		SampleRate: 1,
+		Tags:       make(map[string]string),
+	}
+
+	parts := strings.Split(line[colon+1:], "|")
+	if len(parts) < 2 {
+		return Stat{}, errors.New("missing metric type")
+	}
+
+	switch parts[1] {
+	case "c":
+		s.Type = counterStat
+	case "g":
+		s.Type = gaugeStat
+		s.Relative = strings.HasPrefix(parts[0], "+") || strings.HasPrefix(parts[0], "-")
+	case "ms", "h":
+		s.Type = timerStat
+	default:
+		return Stat{}, fmt.Errorf("unsupported metric type %q", parts[1])
+	}
+
+	var err error
+	s.Value, err = strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return Stat{}, fmt.Errorf("invalid value: %s", err)
+	}
+
+	for _, p := range parts[2:] {
+		switch {
+		case strings.HasPrefix(p, "@"):
+			s.SampleRate, err = strconv.ParseFloat(p[1:], 64)
+			if err != nil || s.SampleRate <= 0 || s.SampleRate > 1 {
+				return Stat{}, fmt.Errorf("invalid sample rate %q", p[1:])
+			}
+		case strings.HasPrefix(p, "#"):
+			parseTags(p[1:], s.Tags)
+		default:
+			return Stat{}, fmt.Errorf("invalid metric section %q", p)
+		}
+	}
+
+	return s, nil
+}
+
+func parseTags(section string, tags map[string]string) {
+	for _, tag := range strings.Split(section, ",") {
+		if tag == "" {
+			continue
+		}
+
+		kv := strings.SplitN(tag, ":", 2)
+		if len(kv) == 1 {
+			tags[kv[0]] = ""
+			continue
+		}
+		tags[kv[0]] = kv[1]
+	}
+}
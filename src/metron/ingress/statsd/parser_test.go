@@ -0,0 +1,83 @@
+package statsd_test
+
+import (
+	"metron/ingress/statsd"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("parses counters, gauges and timers", func() {
+		stats, errs := statsd.Parse("requests:1|c\nmemory:10.5|g\nlatency:250|ms\nsize:3|h")
+		Expect(errs).To(BeEmpty())
+		Expect(stats).To(HaveLen(4))
+
+		Expect(stats[0].Name).To(Equal("requests"))
+		Expect(string(stats[0].Type)).To(Equal("c"))
+		Expect(stats[0].Value).To(Equal(1.0))
+		Expect(stats[0].SampleRate).To(Equal(1.0))
+
+		Expect(stats[1].Name).To(Equal("memory"))
+		Expect(string(stats[1].Type)).To(Equal("g"))
+		Expect(stats[1].Value).To(Equal(10.5))
+		Expect(stats[1].Relative).To(BeFalse())
+
+		Expect(string(stats[2].Type)).To(Equal("ms"))
+		Expect(stats[2].Value).To(Equal(250.0))
+		Expect(string(stats[3].Type)).To(Equal("ms"))
+	})
+
+	It("parses the sample rate", func() {
+		stats, errs := statsd.Parse("requests:2|c|@0.25")
+		Expect(errs).To(BeEmpty())
+		Expect(stats).To(HaveLen(1))
+		Expect(stats[0].SampleRate).To(Equal(0.25))
+	})
+
+	It("marks signed gauges as relative", func() {
+		stats, errs := statsd.Parse("memory:+5|g\nmemory:-3|g\nmemory:7|g")
+		Expect(errs).To(BeEmpty())
+		Expect(stats).To(HaveLen(3))
+
+		Expect(stats[0].Relative).To(BeTrue())
+		Expect(stats[0].Value).To(Equal(5.0))
+		Expect(stats[1].Relative).To(BeTrue())
+		Expect(stats[1].Value).To(Equal(-3.0))
+		Expect(stats[2].Relative).To(BeFalse())
+	})
+
+	It("does not mark signed counters as relative", func() {
+		stats, errs := statsd.Parse("requests:-1|c")
+		Expect(errs).To(BeEmpty())
+		Expect(stats[0].Relative).To(BeFalse())
+	})
+
+	It("parses DogStatsD tags", func() {
+		stats, errs := statsd.Parse("requests:1|c|@0.5|#env:prod,canary,url:http://a")
+		Expect(errs).To(BeEmpty())
+		Expect(stats[0].SampleRate).To(Equal(0.5))
+		Expect(stats[0].Tags).To(Equal(map[string]string{
+			"env":    "prod",
+			"canary": "",
+			"url":    "http://a",
+		}))
+	})
+
+	It("parses every metric of a multi-metric packet", func() {
+		stats, errs := statsd.Parse("a:1|c\n\nb:2|g\r\nc:3|ms\n")
+		Expect(errs).To(BeEmpty())
+		Expect(stats).To(HaveLen(3))
+		Expect(stats[0].Name).To(Equal("a"))
+		Expect(stats[1].Name).To(Equal("b"))
+		Expect(stats[2].Name).To(Equal("c"))
+	})
+
+	It("returns an error for each invalid line and keeps the valid ones", func() {
+		stats, errs := statsd.Parse("nope\n:1|c\nsets:1|s\nbad:abc|c\nrate:1|c|@2\nzero:1|c|@0\nextra:1|c|x\ngood:1|c")
+		Expect(errs).To(HaveLen(7))
+		Expect(errs[0].Error()).To(ContainSubstring(`"nope"`))
+		Expect(stats).To(HaveLen(1))
+		Expect(stats[0].Name).To(Equal("good"))
+	})
+})
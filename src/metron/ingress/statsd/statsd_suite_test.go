@@ -0,0 +1,13 @@
+package statsd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestStatsd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Statsd Ingress Suite")
+}
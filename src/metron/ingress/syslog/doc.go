@@ -0,0 +1,5 @@
+//go:generate hel
+
+// Package syslog accepts RFC 5424 syslog messages over UDP and TCP and
+// converts them into v2 log envelopes.
+package syslog
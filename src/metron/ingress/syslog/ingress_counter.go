@@ -0,0 +1,60 @@
+package syslog
+
+import (
+	"log"
+	"metric"
+	"time"
+)
+
+// invalidLogInterval is the minimum time between logs of invalid messages.
+const invalidLogInterval = time.Minute
+
+// ingressCounter counts the envelopes read by a single reader and emits them
+// every 1000 envelopes or 5 seconds.
+type ingressCounter struct {
+	transport   string
+	count       uint64
+	lastEmitted time.Time
+
+	invalidCount uint64
+	lastLogged   time.Time
+}
+
+func newIngressCounter(transport string) *ingressCounter {
+	return &ingressCounter{
+		transport:   transport,
+		lastEmitted: time.Now(),
+	}
+}
+
+func (c *ingressCounter) add(n uint64) {
+	c.count += n
+	if c.count >= 1000 || time.Since(c.lastEmitted) > 5*time.Second {
+		metric.IncCounter("ingress",
+			metric.WithIncrement(c.count),
+			metric.WithVersion(2, 0),
+			metric.WithTag("protocol", "syslog"),
+			metric.WithTag("transport", c.transport),
+		)
+		c.lastEmitted = time.Now()
+		c.count = 0
+	}
+}
+
+// invalid counts a dropped invalid message. The drops are logged at most
+// once per invalidLogInterval along with the latest error.
+func (c *ingressCounter) invalid(err error) {
+	metric.IncCounter("dropped",
+		metric.WithVersion(2, 0),
+		metric.WithTag("direction", "ingress"),
+		metric.WithTag("protocol", "syslog"),
+	)
+
+	c.invalidCount++
+	if time.Since(c.lastLogged) < invalidLogInterval {
+		return
+	}
+	log.Printf("Dropped %d invalid syslog messages (%s), last error: %s", c.invalidCount, c.transport, err)
+	c.invalidCount = 0
+	c.lastLogged = time.Now()
+}
@@ -0,0 +1,134 @@
+package syslog_test
+
+import (
+	"fmt"
+	"net"
+
+	"metron/ingress/syslog"
+	v2 "plumbing/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UDPListener", func() {
+	var (
+		mockDataSetter *mockDataSetter
+		listener       *syslog.UDPListener
+	)
+
+	BeforeEach(func() {
+		mockDataSetter = newMockDataSetter()
+
+		var err error
+		listener, err = syslog.NewUDPListener("127.0.0.1:0", mockDataSetter)
+		Expect(err).ToNot(HaveOccurred())
+		go listener.Start()
+	})
+
+	AfterEach(func() {
+		listener.Stop()
+	})
+
+	It("writes each datagram as an envelope", func() {
+		conn, err := net.Dial("udp4", listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("<14>1 - - some-app - - - some message\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *v2.Envelope
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-app"))
+		Expect(e.GetLog().Payload).To(Equal([]byte("some message")))
+	})
+
+	It("drops invalid messages", func() {
+		conn, err := net.Dial("udp4", listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("not syslog"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = conn.Write([]byte("<14>1 - - some-app - - - valid"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var e *v2.Envelope
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.GetLog().Payload).To(Equal([]byte("valid")))
+	})
+})
+
+var _ = Describe("TCPListener", func() {
+	var (
+		mockDataSetter *mockDataSetter
+		listener       *syslog.TCPListener
+		conn           net.Conn
+	)
+
+	BeforeEach(func() {
+		mockDataSetter = newMockDataSetter()
+
+		var err error
+		listener, err = syslog.NewTCPListener("127.0.0.1:0", mockDataSetter)
+		Expect(err).ToNot(HaveOccurred())
+		go listener.Start()
+
+		conn, err = net.Dial("tcp", listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		listener.Stop()
+	})
+
+	It("reads octet-counted messages", func() {
+		msg1 := "<14>1 - - app-1 - - - first\nmessage"
+		msg2 := "<11>1 - - app-2 - - - second"
+		fmt.Fprintf(conn, "%d %s%d %s", len(msg1), msg1, len(msg2), msg2)
+
+		var e *v2.Envelope
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("app-1"))
+		Expect(e.GetLog().Payload).To(Equal([]byte("first\nmessage")))
+
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("app-2"))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_ERR))
+	})
+
+	It("reads non-transparent framed messages", func() {
+		fmt.Fprint(conn, "<14>1 - - app-1 - - - first\r\n<14>1 - - app-2 - - - second\n")
+
+		var e *v2.Envelope
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("app-1"))
+		Expect(e.GetLog().Payload).To(Equal([]byte("first")))
+
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("app-2"))
+		Expect(e.GetLog().Payload).To(Equal([]byte("second")))
+	})
+
+	It("reads a mix of framing on the same connection", func() {
+		msg := "<14>1 - - app-2 - - - second"
+		fmt.Fprintf(conn, "<14>1 - - app-1 - - - first\n%d %s", len(msg), msg)
+
+		var e *v2.Envelope
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("app-1"))
+
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("app-2"))
+	})
+
+	It("skips invalid messages", func() {
+		fmt.Fprint(conn, "<999>garbage\n<14>1 - - some-app - - - valid\n")
+
+		var e *v2.Envelope
+		Eventually(mockDataSetter.SetInput.E).Should(Receive(&e))
+		Expect(e.GetLog().Payload).To(Equal([]byte("valid")))
+	})
+})
@@ -0,0 +1,300 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	v2 "plumbing/v2"
+)
+
+const nilValue = "-"
+
+var utf8BOM = []byte("\xEF\xBB\xBF")
+
+// Parse converts an RFC 5424 message into a v2 log envelope. The severity
+// determines the log type (ERR for error and above, otherwise OUT), the
+// APP-NAME becomes the source ID and structured data params become tags.
+func Parse(msg []byte) (*v2.Envelope, error) {
+	p := &parser{buf: msg}
+
+	pri, err := p.priority()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.version(); err != nil {
+		return nil, err
+	}
+
+	ts, err := p.timestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	// HOSTNAME
+	if _, err := p.field(); err != nil {
+		return nil, err
+	}
+
+	appName, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+
+	// PROCID and MSGID
+	for i := 0; i < 2; i++ {
+		if _, err := p.field(); err != nil {
+			return nil, err
+		}
+	}
+
+	tags, err := p.structuredData()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := p.message()
+	if err != nil {
+		return nil, err
+	}
+
+	logType := v2.Log_OUT
+	if pri&0x07 <= 3 {
+		logType = v2.Log_ERR
+	}
+
+	return &v2.Envelope{
+		Timestamp: ts.UnixNano(),
+		SourceId:  appName,
+		Tags:      tags,
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: payload,
+				Type:    logType,
+			},
+		},
+	}, nil
+}
+
+type parser struct {
+	buf []byte
+	pos int
+}
+
+func (p *parser) priority() (int, error) {
+	if !p.consume('<') {
+		return 0, errors.New("missing priority")
+	}
+
+	end := bytes.IndexByte(p.buf[p.pos:], '>')
+	if end < 1 || end > 3 {
+		return 0, errors.New("invalid priority")
+	}
+
+	pri, err := strconv.Atoi(string(p.buf[p.pos : p.pos+end]))
+	if err != nil || pri > 191 {
+		return 0, errors.New("invalid priority")
+	}
+	p.pos += end + 1
+
+	return pri, nil
+}
+
+func (p *parser) version() error {
+	start := p.pos
+	for p.pos < len(p.buf) && p.buf[p.pos] >= '0' && p.buf[p.pos] <= '9' {
+		p.pos++
+	}
+
+	if p.pos == start || p.buf[start] == '0' {
+		return errors.New("invalid version")
+	}
+
+	if !p.consume(' ') {
+		return errors.New("missing space after version")
+	}
+	return nil
+}
+
+func (p *parser) timestamp() (time.Time, error) {
+	tok, err := p.token()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if tok == nilValue {
+		return time.Now(), nil
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, tok)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp: %s", err)
+	}
+	return ts, nil
+}
+
+// field reads a header field that may be the nil value.
+func (p *parser) field() (string, error) {
+	tok, err := p.token()
+	if err != nil {
+		return "", err
+	}
+
+	if tok == nilValue {
+		return "", nil
+	}
+	return tok, nil
+}
+
+// token reads up to the next space and consumes it.
+func (p *parser) token() (string, error) {
+	end := bytes.IndexByte(p.buf[p.pos:], ' ')
+	if end < 1 {
+		return "", errors.New("unexpected end of header")
+	}
+
+	tok := string(p.buf[p.pos : p.pos+end])
+	p.pos += end + 1
+	return tok, nil
+}
+
+func (p *parser) structuredData() (map[string]*v2.Value, error) {
+	tags := make(map[string]*v2.Value)
+
+	if p.consumeString(nilValue) {
+		return tags, nil
+	}
+
+	if p.peek() != '[' {
+		return nil, errors.New("invalid structured data")
+	}
+
+	for p.consume('[') {
+		if err := p.sdElement(tags); err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
+
+func (p *parser) sdElement(tags map[string]*v2.Value) error {
+	if _, err := p.sdName(); err != nil {
+		return err
+	}
+
+	for {
+		if p.consume(']') {
+			return nil
+		}
+
+		if !p.consume(' ') {
+			return errors.New("invalid structured data element")
+		}
+
+		name, err := p.sdName()
+		if err != nil {
+			return err
+		}
+
+		if !p.consume('=') || !p.consume('"') {
+			return errors.New("invalid structured data param")
+		}
+
+		value, err := p.sdValue()
+		if err != nil {
+			return err
+		}
+
+		tags[name] = &v2.Value{
+			Data: &v2.Value_Text{
+				Text: value,
+			},
+		}
+	}
+}
+
+func (p *parser) sdName() (string, error) {
+	start := p.pos
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		if c == ' ' || c == '=' || c == ']' || c == '"' {
+			break
+		}
+		p.pos++
+	}
+
+	if p.pos == start || p.pos-start > 32 {
+		return "", errors.New("invalid structured data name")
+	}
+	return string(p.buf[start:p.pos]), nil
+}
+
+// sdValue reads a param value up to the closing quote, unescaping '"', '\'
+// and ']'.
+func (p *parser) sdValue() (string, error) {
+	var value []byte
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		p.pos++
+
+		switch c {
+		case '"':
+			return string(value), nil
+		case '\\':
+			if p.pos < len(p.buf) {
+				next := p.buf[p.pos]
+				if next == '"' || next == '\\' || next == ']' {
+					c = next
+					p.pos++
+				}
+			}
+		}
+		value = append(value, c)
+	}
+
+	return "", errors.New("unterminated structured data param value")
+}
+
+func (p *parser) message() ([]byte, error) {
+	if p.pos == len(p.buf) {
+		return []byte{}, nil
+	}
+
+	if !p.consume(' ') {
+		return nil, errors.New("missing space before message")
+	}
+
+	msg := bytes.TrimPrefix(p.buf[p.pos:], utf8BOM)
+	msg = bytes.TrimRight(msg, "\r\n")
+
+	payload := make([]byte, len(msg))
+	copy(payload, msg)
+	return payload, nil
+}
+
+func (p *parser) peek() byte {
+	if p.pos >= len(p.buf) {
+		return 0
+	}
+	return p.buf[p.pos]
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *parser) consumeString(s string) bool {
+	if !bytes.HasPrefix(p.buf[p.pos:], []byte(s)) {
+		return false
+	}
+	p.pos += len(s)
+	return true
+}
@@ -0,0 +1,86 @@
+package syslog_test
+
+import (
+	"metron/ingress/syslog"
+	v2 "plumbing/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("converts a message into a log envelope", func() {
+		msg := `<14>1 2017-03-28T18:14:42.123456Z some-host some-app 1234 some-msgid - some message`
+
+		e, err := syslog.Parse([]byte(msg))
+		Expect(err).ToNot(HaveOccurred())
+
+		ts, _ := time.Parse(time.RFC3339Nano, "2017-03-28T18:14:42.123456Z")
+		Expect(e.Timestamp).To(Equal(ts.UnixNano()))
+		Expect(e.SourceId).To(Equal("some-app"))
+		Expect(e.Tags).To(BeEmpty())
+		Expect(e.GetLog().Payload).To(Equal([]byte("some message")))
+		Expect(e.GetLog().Type).To(Equal(v2.Log_OUT))
+	})
+
+	DescribeTable("maps the severity to the log type", func(pri string, t v2.Log_Type) {
+		e, err := syslog.Parse([]byte(pri + `1 - - some-app - - - msg`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.GetLog().Type).To(Equal(t))
+	},
+		Entry("emergency", "<8>", v2.Log_ERR),
+		Entry("alert", "<9>", v2.Log_ERR),
+		Entry("critical", "<10>", v2.Log_ERR),
+		Entry("error", "<11>", v2.Log_ERR),
+		Entry("warning", "<12>", v2.Log_OUT),
+		Entry("notice", "<13>", v2.Log_OUT),
+		Entry("info", "<14>", v2.Log_OUT),
+		Entry("debug", "<15>", v2.Log_OUT),
+	)
+
+	It("converts structured data params into tags", func() {
+		msg := `<14>1 - - some-app - - [exampleSDID@32473 iut="3" eventSource="Application"][other@1 escaped="a\"b\\c\]d"] msg`
+
+		e, err := syslog.Parse([]byte(msg))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(e.Tags).To(HaveLen(3))
+		Expect(e.Tags["iut"].GetText()).To(Equal("3"))
+		Expect(e.Tags["eventSource"].GetText()).To(Equal("Application"))
+		Expect(e.Tags["escaped"].GetText()).To(Equal(`a"b\c]d`))
+		Expect(e.GetLog().Payload).To(Equal([]byte("msg")))
+	})
+
+	It("handles nil values and a missing message", func() {
+		before := time.Now().UnixNano()
+		e, err := syslog.Parse([]byte(`<14>1 - - - - - -`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(e.Timestamp).To(BeNumerically(">=", before))
+		Expect(e.SourceId).To(BeEmpty())
+		Expect(e.GetLog().Payload).To(BeEmpty())
+	})
+
+	It("strips the BOM and trailing newline from the message", func() {
+		e, err := syslog.Parse([]byte("<14>1 - - some-app - - - \xEF\xBB\xBFmsg\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(e.GetLog().Payload).To(Equal([]byte("msg")))
+	})
+
+	DescribeTable("rejects invalid messages", func(msg string) {
+		_, err := syslog.Parse([]byte(msg))
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("empty", ""),
+		Entry("missing priority", `1 - - some-app - - - msg`),
+		Entry("invalid priority", `<192>1 - - some-app - - - msg`),
+		Entry("missing version", `<14> - - some-app - - - msg`),
+		Entry("invalid timestamp", `<14>1 yesterday - some-app - - - msg`),
+		Entry("truncated header", `<14>1 - - some-app`),
+		Entry("invalid structured data", `<14>1 - - some-app - - nope msg`),
+		Entry("unterminated structured data", `<14>1 - - some-app - - [id a="b`),
+	)
+})
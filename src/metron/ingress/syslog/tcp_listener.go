@@ -0,0 +1,153 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+)
+
+const maxMessageSize = 64 * 1024
+
+// TCPListener reads syslog messages from TCP connections. Each message may be
+// framed with octet-counting or non-transparent (newline delimited) framing
+// as described in RFC 6587.
+type TCPListener struct {
+	lis    net.Listener
+	setter DataSetter
+}
+
+// NewTCPListener binds to the given address.
+func NewTCPListener(addr string, s DataSetter) (*TCPListener, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Listening for syslog (TCP) on %s", lis.Addr())
+
+	return &TCPListener{
+		lis:    lis,
+		setter: s,
+	}, nil
+}
+
+// Addr returns the address the listener is bound to.
+func (l *TCPListener) Addr() net.Addr {
+	return l.lis.Addr()
+}
+
+// Start accepts connections until the listener is stopped.
+func (l *TCPListener) Start() {
+	for {
+		conn, err := l.lis.Accept()
+		if err != nil {
+			log.Printf("Error while accepting syslog (TCP) connection: %s", err)
+			return
+		}
+
+		go l.handle(conn)
+	}
+}
+
+// Stop closes the listener. Open connections are left to be closed by the
+// client.
+func (l *TCPListener) Stop() {
+	l.lis.Close()
+}
+
+func (l *TCPListener) handle(conn net.Conn) {
+	defer conn.Close()
+
+	c := newIngressCounter("tcp")
+	r := bufio.NewReader(conn)
+	for {
+		frame, err := readFrame(r)
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Error while reading syslog (TCP) from %s: %s", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		e, err := Parse(frame)
+		if err != nil {
+			c.invalid(err)
+			continue
+		}
+
+		l.setter.Set(e)
+		c.add(1)
+	}
+}
+
+// readFrame reads a single message. Octet-counted frames start with the
+// length of the message while non-transparent frames start with the '<' of
+// the priority and end with a newline.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if b[0] >= '1' && b[0] <= '9' {
+		return readOctetCounted(r)
+	}
+	return readNonTransparent(r)
+}
+
+func readOctetCounted(r *bufio.Reader) ([]byte, error) {
+	var length []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if c == ' ' {
+			break
+		}
+
+		if c < '0' || c > '9' || len(length) > 5 {
+			return nil, errors.New("invalid octet count")
+		}
+		length = append(length, c)
+	}
+
+	n, err := strconv.Atoi(string(length))
+	if err != nil {
+		return nil, err
+	}
+
+	if n > maxMessageSize {
+		return nil, fmt.Errorf("message length %d exceeds %d", n, maxMessageSize)
+	}
+
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func readNonTransparent(r *bufio.Reader) ([]byte, error) {
+	var frame []byte
+	for {
+		line, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		frame = append(frame, line...)
+		if len(frame) > maxMessageSize {
+			return nil, fmt.Errorf("message exceeds %d bytes", maxMessageSize)
+		}
+
+		if !isPrefix {
+			return bytes.TrimRight(frame, "\r"), nil
+		}
+	}
+}
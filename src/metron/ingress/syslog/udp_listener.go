@@ -0,0 +1,64 @@
+package syslog
+
+import (
+	"log"
+	"net"
+
+	v2 "plumbing/v2"
+)
+
+type DataSetter interface {
+	Set(e *v2.Envelope)
+}
+
+// UDPListener reads a single syslog message from each datagram.
+type UDPListener struct {
+	conn   net.PacketConn
+	setter DataSetter
+}
+
+// NewUDPListener binds to the given address.
+func NewUDPListener(addr string, s DataSetter) (*UDPListener, error) {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Listening for syslog (UDP) on %s", conn.LocalAddr())
+
+	return &UDPListener{
+		conn:   conn,
+		setter: s,
+	}, nil
+}
+
+// Addr returns the address the listener is bound to.
+func (l *UDPListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// Start reads messages until the listener is stopped.
+func (l *UDPListener) Start() {
+	c := newIngressCounter("udp")
+	buf := make([]byte, 65535) //buffer with size = max theoretical UDP size
+	for {
+		n, _, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			log.Printf("Error while reading syslog (UDP): %s", err)
+			return
+		}
+
+		e, err := Parse(buf[:n])
+		if err != nil {
+			c.invalid(err)
+			continue
+		}
+
+		l.setter.Set(e)
+		c.add(1)
+	}
+}
+
+// Stop closes the listener.
+func (l *UDPListener) Stop() {
+	l.conn.Close()
+}
@@ -39,6 +39,11 @@ func New(address string, name string, writer ByteArrayWriter) (*NetworkReader, e
 	}, nil
 }
 
+// Buffer returns the diode that holds read messages until they are written.
+func (nr *NetworkReader) Buffer() *diodes.OneToOne {
+	return nr.buffer
+}
+
 func (nr *NetworkReader) StartReading() {
 	readBuffer := make([]byte, 65535) //buffer with size = max theoretical UDP size
 	for {
@@ -31,6 +31,29 @@ func (m *mockDataSetter) Set(e *v2.Envelope) {
 	m.SetInput.E <- e
 }
 
+type mockLimiter struct {
+	AllowCalled chan bool
+	AllowInput  struct {
+		SourceID chan string
+	}
+	AllowOutput struct {
+		Ret0 chan bool
+	}
+}
+
+func newMockLimiter() *mockLimiter {
+	m := &mockLimiter{}
+	m.AllowCalled = make(chan bool, 100)
+	m.AllowInput.SourceID = make(chan string, 100)
+	m.AllowOutput.Ret0 = make(chan bool, 100)
+	return m
+}
+func (m *mockLimiter) Allow(sourceID string) bool {
+	m.AllowCalled <- true
+	m.AllowInput.SourceID <- sourceID
+	return <-m.AllowOutput.Ret0
+}
+
 type mockSender struct {
 	SendAndCloseCalled chan bool
 	SendAndCloseInput  struct {
@@ -130,3 +153,103 @@ func (m *mockSender) RecvMsg(m_ interface{}) error {
 	m.RecvMsgInput.M <- m_
 	return <-m.RecvMsgOutput.Ret0
 }
+
+type mockBatchSender struct {
+	SendAndCloseCalled chan bool
+	SendAndCloseInput  struct {
+		Arg0 chan *v2.BatchSenderResponse
+	}
+	SendAndCloseOutput struct {
+		Ret0 chan error
+	}
+	RecvCalled chan bool
+	RecvOutput struct {
+		Ret0 chan *v2.EnvelopeBatch
+		Ret1 chan error
+	}
+	SendHeaderCalled chan bool
+	SendHeaderInput  struct {
+		Arg0 chan metadata.MD
+	}
+	SendHeaderOutput struct {
+		Ret0 chan error
+	}
+	SetTrailerCalled chan bool
+	SetTrailerInput  struct {
+		Arg0 chan metadata.MD
+	}
+	ContextCalled chan bool
+	ContextOutput struct {
+		Ret0 chan context.Context
+	}
+	SendMsgCalled chan bool
+	SendMsgInput  struct {
+		M chan interface{}
+	}
+	SendMsgOutput struct {
+		Ret0 chan error
+	}
+	RecvMsgCalled chan bool
+	RecvMsgInput  struct {
+		M chan interface{}
+	}
+	RecvMsgOutput struct {
+		Ret0 chan error
+	}
+}
+
+func newMockBatchSender() *mockBatchSender {
+	m := &mockBatchSender{}
+	m.SendAndCloseCalled = make(chan bool, 100)
+	m.SendAndCloseInput.Arg0 = make(chan *v2.BatchSenderResponse, 100)
+	m.SendAndCloseOutput.Ret0 = make(chan error, 100)
+	m.RecvCalled = make(chan bool, 100)
+	m.RecvOutput.Ret0 = make(chan *v2.EnvelopeBatch, 100)
+	m.RecvOutput.Ret1 = make(chan error, 100)
+	m.SendHeaderCalled = make(chan bool, 100)
+	m.SendHeaderInput.Arg0 = make(chan metadata.MD, 100)
+	m.SendHeaderOutput.Ret0 = make(chan error, 100)
+	m.SetTrailerCalled = make(chan bool, 100)
+	m.SetTrailerInput.Arg0 = make(chan metadata.MD, 100)
+	m.ContextCalled = make(chan bool, 100)
+	m.ContextOutput.Ret0 = make(chan context.Context, 100)
+	m.SendMsgCalled = make(chan bool, 100)
+	m.SendMsgInput.M = make(chan interface{}, 100)
+	m.SendMsgOutput.Ret0 = make(chan error, 100)
+	m.RecvMsgCalled = make(chan bool, 100)
+	m.RecvMsgInput.M = make(chan interface{}, 100)
+	m.RecvMsgOutput.Ret0 = make(chan error, 100)
+	return m
+}
+func (m *mockBatchSender) SendAndClose(arg0 *v2.BatchSenderResponse) error {
+	m.SendAndCloseCalled <- true
+	m.SendAndCloseInput.Arg0 <- arg0
+	return <-m.SendAndCloseOutput.Ret0
+}
+func (m *mockBatchSender) Recv() (*v2.EnvelopeBatch, error) {
+	m.RecvCalled <- true
+	return <-m.RecvOutput.Ret0, <-m.RecvOutput.Ret1
+}
+func (m *mockBatchSender) SendHeader(arg0 metadata.MD) error {
+	m.SendHeaderCalled <- true
+	m.SendHeaderInput.Arg0 <- arg0
+	return <-m.SendHeaderOutput.Ret0
+}
+func (m *mockBatchSender) SetTrailer(arg0 metadata.MD) {
+	m.SetTrailerCalled <- true
+	m.SetTrailerInput.Arg0 <- arg0
+}
+func (m *mockBatchSender) Context() context.Context {
+	m.ContextCalled <- true
+	return <-m.ContextOutput.Ret0
+}
+func (m *mockBatchSender) SendMsg(m_ interface{}) error {
+	m.SendMsgCalled <- true
+	m.SendMsgInput.M <- m_
+	return <-m.SendMsgOutput.Ret0
+}
+func (m *mockBatchSender) RecvMsg(m_ interface{}) error {
+	m.RecvMsgCalled <- true
+	m.RecvMsgInput.M <- m_
+	return <-m.RecvMsgOutput.Ret0
+}
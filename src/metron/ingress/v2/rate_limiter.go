@@ -0,0 +1,163 @@
+package v2
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"metric"
+	"sync"
+	"time"
+
+	v2 "plumbing/v2"
+)
+
+// RateLimit is a token bucket limit. Rate is the number of envelopes per
+// second and Burst is the size of the bucket. A Rate of zero is unlimited. A
+// Burst below 1 allows one second worth of envelopes at the Rate.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+func (r RateLimit) withDefaultBurst() RateLimit {
+	if r.Burst < 1 {
+		r.Burst = int(math.Max(1, math.Ceil(r.Rate)))
+	}
+	return r
+}
+
+type bucket struct {
+	limit   RateLimit
+	tokens  float64
+	last    time.Time
+	dropped uint64
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if b.tokens > float64(b.limit.Burst) {
+		b.tokens = float64(b.limit.Burst)
+	}
+	b.last = now
+}
+
+// RateLimiter limits envelopes per source ID. Dropped envelopes are reported
+// once per interval as a dropped metric and as a log envelope written to the
+// source.
+type RateLimiter struct {
+	dataSetter   DataSetter
+	defaultLimit RateLimit
+	limits       map[string]RateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewRateLimiter returns a RateLimiter that uses the limit for the source ID
+// if there is one and otherwise the default limit. Reports are written to
+// the DataSetter.
+func NewRateLimiter(s DataSetter, defaultLimit RateLimit, limits map[string]RateLimit) *RateLimiter {
+	l := make(map[string]RateLimit, len(limits))
+	for sourceID, limit := range limits {
+		l[sourceID] = limit.withDefaultBurst()
+	}
+
+	return &RateLimiter{
+		dataSetter:   s,
+		defaultLimit: defaultLimit.withDefaultBurst(),
+		limits:       l,
+		buckets:      make(map[string]*bucket),
+	}
+}
+
+// Allow reports whether an envelope from the source ID may be written.
+func (l *RateLimiter) Allow(sourceID string) bool {
+	limit, ok := l.limits[sourceID]
+	if !ok {
+		limit = l.defaultLimit
+	}
+
+	if limit.Rate <= 0 {
+		return true
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[sourceID]
+	if !ok {
+		b = &bucket{
+			limit:  limit,
+			tokens: float64(limit.Burst),
+			last:   now,
+		}
+		l.buckets[sourceID] = b
+	}
+
+	b.refill(now)
+	if b.tokens < 1 {
+		b.dropped++
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// Start reports dropped envelopes on the interval. It does not return.
+func (l *RateLimiter) Start(interval time.Duration) {
+	for range time.Tick(interval) {
+		l.Report()
+	}
+}
+
+// Report emits the dropped metric and writes a log envelope for each source
+// that had envelopes dropped since the last report. Buckets that have
+// refilled are forgotten.
+func (l *RateLimiter) Report() {
+	now := time.Now()
+	dropped := make(map[string]*bucket)
+
+	l.mu.Lock()
+	for sourceID, b := range l.buckets {
+		if b.dropped > 0 {
+			dropped[sourceID] = &bucket{limit: b.limit, dropped: b.dropped}
+			b.dropped = 0
+			continue
+		}
+
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, sourceID)
+		}
+	}
+	l.mu.Unlock()
+
+	for sourceID, b := range dropped {
+		metric.IncCounter("dropped",
+			metric.WithIncrement(b.dropped),
+			metric.WithVersion(2, 0),
+			metric.WithTag("direction", "ingress"),
+			metric.WithTag("source_id", sourceID),
+		)
+
+		msg := fmt.Sprintf(
+			"Dropped %d envelopes from source %s: rate limit of %g envelopes/s (burst %d) exceeded",
+			b.dropped, sourceID, b.limit.Rate, b.limit.Burst,
+		)
+		log.Print(msg)
+
+		l.dataSetter.Set(&v2.Envelope{
+			Timestamp: now.UnixNano(),
+			SourceId:  sourceID,
+			Message: &v2.Envelope_Log{
+				Log: &v2.Log{
+					Payload: []byte(msg),
+					Type:    v2.Log_ERR,
+				},
+			},
+		})
+	}
+}
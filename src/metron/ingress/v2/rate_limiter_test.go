@@ -0,0 +1,109 @@
+package v2_test
+
+import (
+	ingress "metron/ingress/v2"
+	v2 "plumbing/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimiter", func() {
+	var (
+		mockDataSetter *mockDataSetter
+		limiter        *ingress.RateLimiter
+	)
+
+	BeforeEach(func() {
+		mockDataSetter = newMockDataSetter()
+		limiter = ingress.NewRateLimiter(
+			mockDataSetter,
+			ingress.RateLimit{Rate: 1, Burst: 2},
+			map[string]ingress.RateLimit{
+				"unlimited": {},
+				"fast":      {Rate: 1000, Burst: 5},
+			},
+		)
+	})
+
+	It("allows a burst and then limits by the default rate", func() {
+		Expect(limiter.Allow("some-id")).To(BeTrue())
+		Expect(limiter.Allow("some-id")).To(BeTrue())
+		Expect(limiter.Allow("some-id")).To(BeFalse())
+	})
+
+	It("limits each source separately", func() {
+		Expect(limiter.Allow("some-id")).To(BeTrue())
+		Expect(limiter.Allow("some-id")).To(BeTrue())
+		Expect(limiter.Allow("some-id")).To(BeFalse())
+
+		Expect(limiter.Allow("other-id")).To(BeTrue())
+	})
+
+	It("uses the limit configured for the source", func() {
+		for i := 0; i < 5; i++ {
+			Expect(limiter.Allow("fast")).To(BeTrue())
+		}
+		Expect(limiter.Allow("fast")).To(BeFalse())
+
+		Eventually(func() bool {
+			return limiter.Allow("fast")
+		}).Should(BeTrue())
+	})
+
+	It("does not limit sources with a rate of zero", func() {
+		for i := 0; i < 100; i++ {
+			Expect(limiter.Allow("unlimited")).To(BeTrue())
+		}
+	})
+
+	It("allows one second of envelopes when the burst is not set", func() {
+		limiter = ingress.NewRateLimiter(
+			mockDataSetter,
+			ingress.RateLimit{Rate: 2.5},
+			map[string]ingress.RateLimit{
+				"slow": {Rate: 0.5},
+			},
+		)
+
+		for i := 0; i < 3; i++ {
+			Expect(limiter.Allow("some-id")).To(BeTrue())
+		}
+		Expect(limiter.Allow("some-id")).To(BeFalse())
+
+		Expect(limiter.Allow("slow")).To(BeTrue())
+		Expect(limiter.Allow("slow")).To(BeFalse())
+	})
+
+	Describe("Report()", func() {
+		It("writes a log envelope to each source with drops", func() {
+			for i := 0; i < 5; i++ {
+				limiter.Allow("some-id")
+			}
+			limiter.Allow("other-id")
+
+			before := time.Now().UnixNano()
+			limiter.Report()
+
+			var e *v2.Envelope
+			Expect(mockDataSetter.SetInput.E).To(Receive(&e))
+			Expect(e.SourceId).To(Equal("some-id"))
+			Expect(e.Timestamp).To(BeNumerically(">=", before))
+			Expect(e.GetLog().Type).To(Equal(v2.Log_ERR))
+			Expect(string(e.GetLog().Payload)).To(ContainSubstring("Dropped 3 envelopes"))
+			Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+		})
+
+		It("reports drops once", func() {
+			for i := 0; i < 3; i++ {
+				limiter.Allow("some-id")
+			}
+			limiter.Report()
+			Expect(mockDataSetter.SetInput.E).To(Receive())
+
+			limiter.Report()
+			Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+		})
+	})
+})
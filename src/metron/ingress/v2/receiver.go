@@ -11,19 +11,38 @@ type DataSetter interface {
 	Set(e *v2.Envelope)
 }
 
+type Limiter interface {
+	Allow(sourceID string) bool
+}
+
 type Receiver struct {
 	dataSetter DataSetter
+	limiter    Limiter
 }
 
-func NewReceiver(dataSetter DataSetter) *Receiver {
-	return &Receiver{
+type ReceiverOption func(*Receiver)
+
+// WithLimiter sets the Limiter used to drop envelopes from sources that
+// exceed their rate limit. By default no envelopes are dropped.
+func WithLimiter(l Limiter) ReceiverOption {
+	return func(r *Receiver) {
+		r.limiter = l
+	}
+}
+
+func NewReceiver(dataSetter DataSetter, opts ...ReceiverOption) *Receiver {
+	r := &Receiver{
 		dataSetter: dataSetter,
+		limiter:    noLimit{},
+	}
+	for _, o := range opts {
+		o(r)
 	}
+	return r
 }
 
 func (s *Receiver) Sender(sender v2.Ingress_SenderServer) error {
-	var count uint64
-	lastEmitted := time.Now()
+	c := &ingressCounter{lastEmitted: time.Now()}
 	for {
 		e, err := sender.Recv()
 		if err != nil {
@@ -31,19 +50,61 @@ func (s *Receiver) Sender(sender v2.Ingress_SenderServer) error {
 			return err
 		}
 
+		if !s.limiter.Allow(e.SourceId) {
+			continue
+		}
+
 		s.dataSetter.Set(e)
+		c.add(1)
+	}
+
+	return nil
+}
+
+func (s *Receiver) BatchSender(sender v2.BatchIngress_BatchSenderServer) error {
+	c := &ingressCounter{lastEmitted: time.Now()}
+	for {
+		envelopes, err := sender.Recv()
+		if err != nil {
+			log.Printf("Failed to receive data: %s", err)
+			return err
+		}
+
+		var n uint64
+		for _, e := range envelopes.Batch {
+			if !s.limiter.Allow(e.SourceId) {
+				continue
+			}
 
-		count++
-		if count >= 1000 || time.Since(lastEmitted) > 5*time.Second {
-			metric.IncCounter("ingress",
-				metric.WithIncrement(count),
-				metric.WithVersion(2, 0),
-			)
-			lastEmitted = time.Now()
-			log.Printf("Ingressed (v2) %d envelopes", count)
-			count = 0
+			s.dataSetter.Set(e)
+			n++
 		}
+		c.add(n)
 	}
 
 	return nil
 }
+
+type noLimit struct{}
+
+func (noLimit) Allow(string) bool {
+	return true
+}
+
+type ingressCounter struct {
+	count       uint64
+	lastEmitted time.Time
+}
+
+func (c *ingressCounter) add(n uint64) {
+	c.count += n
+	if c.count >= 1000 || time.Since(c.lastEmitted) > 5*time.Second {
+		metric.IncCounter("ingress",
+			metric.WithIncrement(c.count),
+			metric.WithVersion(2, 0),
+		)
+		c.lastEmitted = time.Now()
+		log.Printf("Ingressed (v2) %d envelopes", c.count)
+		c.count = 0
+	}
+}
@@ -14,12 +14,14 @@ var _ = Describe("Receiver", func() {
 	var (
 		rx *ingress.Receiver
 
-		mockDataSetter *mockDataSetter
-		mockSender     *mockSender
+		mockDataSetter  *mockDataSetter
+		mockSender      *mockSender
+		mockBatchSender *mockBatchSender
 	)
 
 	BeforeEach(func() {
 		mockSender = newMockSender()
+		mockBatchSender = newMockBatchSender()
 		mockDataSetter = newMockDataSetter()
 
 		rx = ingress.NewReceiver(mockDataSetter)
@@ -50,4 +52,80 @@ var _ = Describe("Receiver", func() {
 
 		Expect(err).To(HaveOccurred())
 	})
+
+	Describe("BatchSender", func() {
+		It("calls set on the data setter with each envelope in the batch", func() {
+			e1 := &v2.Envelope{SourceId: "some-id"}
+			e2 := &v2.Envelope{SourceId: "some-other-id"}
+			mockBatchSender.RecvOutput.Ret0 <- &v2.EnvelopeBatch{
+				Batch: []*v2.Envelope{e1, e2},
+			}
+			mockBatchSender.RecvOutput.Ret1 <- nil
+			mockBatchSender.RecvOutput.Ret0 <- nil
+			mockBatchSender.RecvOutput.Ret1 <- io.EOF
+
+			rx.BatchSender(mockBatchSender)
+
+			Eventually(mockDataSetter.SetInput.E).Should(Receive(Equal(e1)))
+			Eventually(mockDataSetter.SetInput.E).Should(Receive(Equal(e2)))
+		})
+
+		It("returns an error when receive fails", func() {
+			close(mockBatchSender.RecvOutput.Ret0)
+			mockBatchSender.RecvOutput.Ret1 <- errors.New("error occurred")
+
+			err := rx.BatchSender(mockBatchSender)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("rate limiting", func() {
+		var (
+			mockLimiter *mockLimiter
+		)
+
+		BeforeEach(func() {
+			mockLimiter = newMockLimiter()
+			rx = ingress.NewReceiver(mockDataSetter, ingress.WithLimiter(mockLimiter))
+		})
+
+		It("drops envelopes that the limiter does not allow", func() {
+			e1 := &v2.Envelope{SourceId: "noisy"}
+			e2 := &v2.Envelope{SourceId: "quiet"}
+			mockSender.RecvOutput.Ret0 <- e1
+			mockSender.RecvOutput.Ret1 <- nil
+			mockSender.RecvOutput.Ret0 <- e2
+			mockSender.RecvOutput.Ret1 <- nil
+			mockSender.RecvOutput.Ret0 <- nil
+			mockSender.RecvOutput.Ret1 <- io.EOF
+			mockLimiter.AllowOutput.Ret0 <- false
+			mockLimiter.AllowOutput.Ret0 <- true
+
+			rx.Sender(mockSender)
+
+			Expect(mockLimiter.AllowInput.SourceID).To(Receive(Equal("noisy")))
+			Expect(mockLimiter.AllowInput.SourceID).To(Receive(Equal("quiet")))
+			Expect(mockDataSetter.SetInput.E).To(Receive(Equal(e2)))
+			Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+		})
+
+		It("drops envelopes in a batch that the limiter does not allow", func() {
+			e1 := &v2.Envelope{SourceId: "noisy"}
+			e2 := &v2.Envelope{SourceId: "quiet"}
+			mockBatchSender.RecvOutput.Ret0 <- &v2.EnvelopeBatch{
+				Batch: []*v2.Envelope{e1, e2},
+			}
+			mockBatchSender.RecvOutput.Ret1 <- nil
+			mockBatchSender.RecvOutput.Ret0 <- nil
+			mockBatchSender.RecvOutput.Ret1 <- io.EOF
+			mockLimiter.AllowOutput.Ret0 <- false
+			mockLimiter.AllowOutput.Ret0 <- true
+
+			rx.BatchSender(mockBatchSender)
+
+			Expect(mockDataSetter.SetInput.E).To(Receive(Equal(e2)))
+			Expect(mockDataSetter.SetInput.E).ToNot(Receive())
+		})
+	})
 })
@@ -11,8 +11,10 @@ import (
 	"google.golang.org/grpc"
 
 	"metron/api"
+	"metron/health"
 	"plumbing"
 	"profiler"
+	"signalmanager"
 )
 
 func main() {
@@ -49,12 +51,24 @@ func main() {
 		log.Fatalf("Could not use GRPC creds for server: %s", err)
 	}
 
-	appV1 := api.NewV1App(config, clientCreds)
+	registry := health.NewRegistry()
+
+	engine := api.StartFilterEngine(config)
+
+	appV1 := api.NewV1App(config, engine, clientCreds, registry)
 	go appV1.Start()
 
-	appV2 := api.NewV2App(config, clientCreds, serverCreds)
+	appV2 := api.NewV2App(config, engine, clientCreds, serverCreds, registry)
 	go appV2.Start()
 
+	if config.HealthPort != 0 {
+		healthServer, err := health.NewServer(fmt.Sprintf("%s:%d", config.HealthAddr, config.HealthPort), registry)
+		if err != nil {
+			log.Fatalf("Failed to start health server: %s", err)
+		}
+		go healthServer.Start()
+	}
+
 	batchInterval := time.Duration(config.MetricBatchIntervalMilliseconds) * time.Millisecond
 	metric.Setup(
 		metric.WithGrpcDialOpts(grpc.WithTransportCredentials(serverCreds)),
@@ -64,7 +78,40 @@ func main() {
 		metric.WithDeploymentMeta(config.Deployment, config.Job, config.Index),
 	)
 
+	reloadChan := signalmanager.RegisterReloadSignalChannel()
+
 	// We start the profiler last so that we can definitively say that we're
 	// all connected and ready for data by the time the profiler starts up.
-	profiler.New(config.PPROFPort).Start()
+	go profiler.New(config.PPROFPort).Start()
+
+	for range reloadChan {
+		reload(*configFilePath, appV1, appV2)
+	}
+}
+
+// reload reconnects to doppler with the doppler addresses, zone and TLS
+// files from the config file. Other changes require a restart.
+func reload(configFilePath string, appV1 *api.AppV1, appV2 *api.AppV2) {
+	log.Printf("Reloading config from %s", configFilePath)
+
+	config, err := api.ParseConfig(configFilePath)
+	if err != nil {
+		log.Printf("Unable to reload config: %s", err)
+		return
+	}
+
+	clientCreds, err := plumbing.NewCredentials(
+		config.GRPC.CertFile,
+		config.GRPC.KeyFile,
+		config.GRPC.CAFile,
+		"doppler",
+	)
+	if err != nil {
+		log.Printf("Unable to reload GRPC creds for client: %s", err)
+		return
+	}
+
+	appV1.Reload(config, clientCreds)
+	appV2.Reload(config, clientCreds)
+	log.Printf("Reconnected to doppler %s", config.DopplerAddr)
 }
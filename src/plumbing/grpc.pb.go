@@ -18,6 +18,7 @@ It has these top-level messages:
 	ContainerMetricsResponse
 	RecentLogsRequest
 	RecentLogsResponse
+	Selector
 */
 package plumbing
 
@@ -35,6 +36,36 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+type Selector_Type int32
+
+const (
+	Selector_ANY     Selector_Type = 0
+	Selector_LOG     Selector_Type = 1
+	Selector_COUNTER Selector_Type = 2
+	Selector_GAUGE   Selector_Type = 3
+	Selector_TIMER   Selector_Type = 4
+)
+
+var Selector_Type_name = map[int32]string{
+	0: "ANY",
+	1: "LOG",
+	2: "COUNTER",
+	3: "GAUGE",
+	4: "TIMER",
+}
+var Selector_Type_value = map[string]int32{
+	"ANY":     0,
+	"LOG":     1,
+	"COUNTER": 2,
+	"GAUGE":   3,
+	"TIMER":   4,
+}
+
+func (x Selector_Type) String() string {
+	return proto.EnumName(Selector_Type_name, int32(x))
+}
+func (Selector_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{9, 0} }
+
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the proto package it is being compiled against.
 // A compilation error at this line likely means your copy of the
@@ -76,7 +107,8 @@ func (m *SubscriptionRequest) GetFilter() *Filter {
 }
 
 type Filter struct {
-	AppID string `protobuf:"bytes,1,opt,name=appID" json:"appID,omitempty"`
+	AppID     string      `protobuf:"bytes,1,opt,name=appID" json:"appID,omitempty"`
+	Selectors []*Selector `protobuf:"bytes,2,rep,name=selectors" json:"selectors,omitempty"`
 }
 
 func (m *Filter) Reset()                    { *m = Filter{} }
@@ -84,6 +116,13 @@ func (m *Filter) String() string            { return proto.CompactTextString(m)
 func (*Filter) ProtoMessage()               {}
 func (*Filter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
+func (m *Filter) GetSelectors() []*Selector {
+	if m != nil {
+		return m.Selectors
+	}
+	return nil
+}
+
 // Note: Ideally this would be EnvelopeData but for the time being we do not
 // want to pay the cost of planning an upgrade path for this to be renamed.
 type Response struct {
@@ -131,6 +170,27 @@ func (m *RecentLogsResponse) String() string            { return proto.CompactTe
 func (*RecentLogsResponse) ProtoMessage()               {}
 func (*RecentLogsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
+// Selector matches envelopes by type, counter or gauge name, and tags. An
+// envelope passes a Filter if it matches any of its selectors. A Filter
+// without selectors matches every envelope.
+type Selector struct {
+	Type Selector_Type     `protobuf:"varint,1,opt,name=type,enum=plumbing.Selector_Type" json:"type,omitempty"`
+	Name string            `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Tags map[string]string `protobuf:"bytes,3,rep,name=tags" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *Selector) Reset()                    { *m = Selector{} }
+func (m *Selector) String() string            { return proto.CompactTextString(m) }
+func (*Selector) ProtoMessage()               {}
+func (*Selector) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *Selector) GetTags() map[string]string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*EnvelopeData)(nil), "plumbing.EnvelopeData")
 	proto.RegisterType((*PushResponse)(nil), "plumbing.PushResponse")
@@ -141,6 +201,8 @@ func init() {
 	proto.RegisterType((*ContainerMetricsResponse)(nil), "plumbing.ContainerMetricsResponse")
 	proto.RegisterType((*RecentLogsRequest)(nil), "plumbing.RecentLogsRequest")
 	proto.RegisterType((*RecentLogsResponse)(nil), "plumbing.RecentLogsResponse")
+	proto.RegisterType((*Selector)(nil), "plumbing.Selector")
+	proto.RegisterEnum("plumbing.Selector_Type", Selector_Type_name, Selector_Type_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -410,27 +472,37 @@ var _DopplerIngestor_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("grpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 343 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x52, 0x4d, 0x4f, 0x83, 0x40,
-	0x10, 0x2d, 0x1a, 0x69, 0x3b, 0x36, 0x5a, 0x47, 0xa3, 0x04, 0x3f, 0x52, 0x89, 0x07, 0xbc, 0xa0,
-	0xa9, 0x1e, 0x3d, 0x69, 0x35, 0x69, 0xa2, 0xd1, 0xe0, 0xc9, 0x78, 0x02, 0x3a, 0x52, 0x12, 0xdc,
-	0x5d, 0x77, 0x17, 0x13, 0x7f, 0xb8, 0x77, 0xd3, 0x0f, 0x0a, 0x56, 0x6c, 0x8f, 0xf3, 0xf5, 0xe6,
-	0xcd, 0x9b, 0x07, 0x10, 0x4b, 0x11, 0x79, 0x42, 0x72, 0xcd, 0xb1, 0x21, 0xd2, 0xec, 0x3d, 0x4c,
-	0x58, 0xec, 0xb8, 0xd0, 0xba, 0x65, 0x9f, 0x94, 0x72, 0x41, 0xbd, 0x40, 0x07, 0x68, 0x41, 0x5d,
-	0x04, 0x5f, 0x29, 0x0f, 0x06, 0x96, 0xd1, 0x31, 0xdc, 0x96, 0x9f, 0x87, 0xce, 0x06, 0xb4, 0x9e,
-	0x32, 0x35, 0xf4, 0x49, 0x09, 0xce, 0x14, 0x39, 0x2f, 0xb0, 0xfd, 0x9c, 0x85, 0x2a, 0x92, 0x89,
-	0xd0, 0x09, 0x67, 0x3e, 0x7d, 0x64, 0xa4, 0xf4, 0x08, 0x40, 0x0d, 0x03, 0x39, 0xe8, 0xf7, 0xc6,
-	0x00, 0x4d, 0x3f, 0x0f, 0xd1, 0x05, 0xf3, 0x2d, 0x49, 0x35, 0x49, 0x6b, 0xa5, 0x63, 0xb8, 0xeb,
-	0xdd, 0xb6, 0x97, 0xb3, 0xf0, 0xee, 0xc6, 0x79, 0x7f, 0x5a, 0x77, 0x8e, 0xc0, 0x9c, 0x64, 0x70,
-	0x07, 0xd6, 0x02, 0x21, 0x66, 0x58, 0x93, 0xc0, 0x39, 0x81, 0x46, 0x4e, 0x63, 0x01, 0xe1, 0x33,
-	0xd8, 0xbb, 0xe1, 0x4c, 0x07, 0x09, 0x23, 0xf9, 0x40, 0x5a, 0x26, 0x91, 0xca, 0x49, 0x56, 0xc3,
-	0x5e, 0x82, 0xf5, 0x77, 0xa0, 0x6a, 0xcd, 0x6a, 0x79, 0xcd, 0x29, 0x6c, 0xf9, 0x14, 0x11, 0xd3,
-	0xf7, 0x3c, 0x5e, 0xb2, 0xc0, 0x03, 0x2c, 0xb7, 0x2e, 0x83, 0xee, 0x7e, 0x1b, 0x50, 0xef, 0x71,
-	0x21, 0x52, 0x92, 0x78, 0x0d, 0xcd, 0xa9, 0xdc, 0x21, 0xe1, 0x61, 0x21, 0x5d, 0xc5, 0x0f, 0x6c,
-	0x2c, 0xca, 0xb3, 0x77, 0xd5, 0xce, 0x0d, 0x7c, 0x85, 0xf6, 0xfc, 0x81, 0x78, 0x5c, 0xf4, 0xfe,
-	0xa3, 0x96, 0xed, 0x2c, 0x6a, 0xc9, 0xe1, 0xb1, 0x0f, 0x50, 0x1c, 0x87, 0xfb, 0x65, 0x0a, 0x73,
-	0xea, 0xd8, 0x07, 0xd5, 0xc5, 0x1c, 0xaa, 0xfb, 0x08, 0x9b, 0xd3, 0xb3, 0xfb, 0x2c, 0x26, 0xa5,
-	0xb9, 0xc4, 0x2b, 0x30, 0x47, 0xee, 0x23, 0x89, 0xbb, 0xc5, 0x70, 0xd9, 0xb9, 0x76, 0x29, 0xff,
-	0xcb, 0xa7, 0x35, 0xd7, 0x08, 0xcd, 0xb1, 0xed, 0x2f, 0x7e, 0x02, 0x00, 0x00, 0xff, 0xff, 0x81,
-	0xea, 0x3c, 0x01, 0x04, 0x03, 0x00, 0x00,
+	// 506 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x54, 0x51, 0x6f, 0x12, 0x41,
+	0x10, 0xee, 0x71, 0x14, 0xb8, 0x81, 0xd4, 0x73, 0x34, 0xf6, 0x82, 0x35, 0xc1, 0x8b, 0x0f, 0x67,
+	0x4c, 0x90, 0xa0, 0x89, 0x46, 0x7d, 0xa9, 0x05, 0x09, 0x49, 0x5b, 0x9a, 0x2d, 0x7d, 0x68, 0x7c,
+	0x5a, 0xe8, 0x78, 0xbd, 0x78, 0xbd, 0x5d, 0x77, 0x97, 0x26, 0xfc, 0x6e, 0xe3, 0xbb, 0xb9, 0x83,
+	0x83, 0xb3, 0x60, 0xfb, 0x36, 0x33, 0x3b, 0xf7, 0xcd, 0xf7, 0xed, 0x7c, 0x7b, 0x00, 0xa1, 0x92,
+	0xd3, 0xb6, 0x54, 0xc2, 0x08, 0xac, 0xc9, 0x78, 0x76, 0x33, 0x89, 0x92, 0xd0, 0x0f, 0xa0, 0xd1,
+	0x4f, 0x6e, 0x29, 0x16, 0x92, 0x7a, 0xdc, 0x70, 0xf4, 0xa0, 0x2a, 0xf9, 0x3c, 0x16, 0xfc, 0xca,
+	0xb3, 0x5a, 0x56, 0xd0, 0x60, 0x79, 0xea, 0xef, 0x41, 0xe3, 0x6c, 0xa6, 0xaf, 0x19, 0x69, 0x29,
+	0x12, 0x4d, 0xfe, 0x25, 0x3c, 0x39, 0x9f, 0x4d, 0xf4, 0x54, 0x45, 0xd2, 0x44, 0x22, 0x61, 0xf4,
+	0x6b, 0x46, 0xda, 0xa4, 0x00, 0xfa, 0x9a, 0xab, 0xab, 0x61, 0x2f, 0x03, 0x70, 0x58, 0x9e, 0x62,
+	0x00, 0x95, 0x1f, 0x51, 0x6c, 0x48, 0x79, 0xa5, 0x96, 0x15, 0xd4, 0xbb, 0x6e, 0x3b, 0x67, 0xd1,
+	0xfe, 0x96, 0xd5, 0xd9, 0xf2, 0xdc, 0x3f, 0x83, 0xca, 0xa2, 0x82, 0x4f, 0x61, 0x97, 0x4b, 0xb9,
+	0xc2, 0x5a, 0x24, 0xd8, 0x01, 0x47, 0x53, 0x4c, 0x53, 0x23, 0x94, 0xf6, 0x4a, 0x2d, 0x3b, 0xa8,
+	0x77, 0x71, 0x0d, 0x76, 0xbe, 0x3c, 0x62, 0xeb, 0x26, 0xff, 0x15, 0xd4, 0x72, 0xe2, 0xf7, 0x48,
+	0x7c, 0x0b, 0xfb, 0x47, 0x22, 0x31, 0x3c, 0x4a, 0x48, 0x9d, 0x90, 0x51, 0xd1, 0x54, 0xe7, 0xb2,
+	0xb6, 0x12, 0xf1, 0xdf, 0x83, 0xb7, 0xf9, 0xc1, 0xb6, 0x31, 0x76, 0x71, 0xcc, 0x6b, 0x78, 0xcc,
+	0x68, 0x4a, 0x89, 0x39, 0x16, 0xe1, 0x03, 0x03, 0xda, 0x80, 0xc5, 0xd6, 0x07, 0xa1, 0x7f, 0x5b,
+	0x50, 0xcb, 0xf5, 0xe3, 0x1b, 0x28, 0x9b, 0xb9, 0xa4, 0x0c, 0x71, 0xaf, 0xbb, 0xbf, 0x79, 0x43,
+	0xed, 0xf1, 0x5c, 0x12, 0xcb, 0x9a, 0x10, 0xa1, 0x9c, 0xf0, 0x1b, 0xca, 0x76, 0xe3, 0xb0, 0x2c,
+	0xc6, 0x0e, 0x94, 0x0d, 0x0f, 0xb5, 0x67, 0x67, 0x57, 0x7c, 0xb0, 0x0d, 0x80, 0x87, 0xba, 0x9f,
+	0x18, 0x35, 0x67, 0x59, 0x67, 0xf3, 0x03, 0x38, 0xab, 0x12, 0xba, 0x60, 0xff, 0xa4, 0xf9, 0x52,
+	0x50, 0x1a, 0xa6, 0x22, 0x6f, 0x79, 0x3c, 0xcb, 0xa7, 0x2c, 0x92, 0x4f, 0xa5, 0x8f, 0x96, 0xff,
+	0x19, 0xca, 0x29, 0x19, 0xac, 0x82, 0x7d, 0x78, 0x7a, 0xe9, 0xee, 0xa4, 0xc1, 0xf1, 0x68, 0xe0,
+	0x5a, 0x58, 0x87, 0xea, 0xd1, 0xe8, 0xe2, 0x74, 0xdc, 0x67, 0x6e, 0x09, 0x1d, 0xd8, 0x1d, 0x1c,
+	0x5e, 0x0c, 0xfa, 0xae, 0x9d, 0x86, 0xe3, 0xe1, 0x49, 0x9f, 0xb9, 0xe5, 0xee, 0x1f, 0x0b, 0xaa,
+	0x3d, 0x21, 0x65, 0x4c, 0x0a, 0xbf, 0x82, 0xb3, 0xb4, 0xe5, 0x84, 0xf0, 0x45, 0x81, 0xf2, 0xa6,
+	0x57, 0x9b, 0x05, 0xd3, 0xac, 0x6c, 0xbd, 0xd3, 0xb1, 0xf0, 0x3b, 0xb8, 0x77, 0xd7, 0x8a, 0x2f,
+	0xd7, 0xbd, 0xff, 0xf1, 0x48, 0xd3, 0xbf, 0xaf, 0x25, 0x87, 0xc7, 0x21, 0xc0, 0x7a, 0xa5, 0xf8,
+	0xbc, 0x48, 0xe1, 0x8e, 0x27, 0x9a, 0x07, 0xdb, 0x0f, 0x73, 0xa8, 0xee, 0x08, 0x1e, 0x2d, 0x65,
+	0x0f, 0x93, 0x90, 0x74, 0xba, 0xf3, 0x2f, 0x50, 0x49, 0x5f, 0x29, 0x29, 0x7c, 0xb6, 0xfe, 0xb8,
+	0xf8, 0xc2, 0x9b, 0x85, 0xfa, 0x3f, 0xef, 0x79, 0x27, 0xb0, 0x26, 0x95, 0xec, 0xf7, 0xf0, 0xee,
+	0xef, 0x00, 0x8f, 0xfe, 0x5c, 0x15, 0x2c, 0x04, 0x00, 0x00,
 }
@@ -0,0 +1,208 @@
+// Code generated by protoc-gen-go.
+// source: batch.proto
+// DO NOT EDIT!
+
+/*
+Package loggregator_v2 is a generated protocol buffer package.
+
+It is generated from these files:
+	batch.proto
+	doppler.proto
+	egress.proto
+	egress_query.proto
+	envelope.proto
+	ingress.proto
+	selective_egress.proto
+
+It has these top-level messages:
+	EnvelopeBatch
+	BatchSenderResponse
+	SenderResponse
+	EgressRequest
+	Filter
+	RecentLogsRequest
+	ContainerMetricRequest
+	QueryResponse
+	Envelope
+	Value
+	Log
+	Counter
+	Gauge
+	GaugeValue
+	Timer
+	IngressResponse
+	SelectiveEgressRequest
+	Selector
+*/
+package loggregator_v2
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type EnvelopeBatch struct {
+	Batch []*Envelope `protobuf:"bytes,1,rep,name=batch" json:"batch,omitempty"`
+}
+
+func (m *EnvelopeBatch) Reset()                    { *m = EnvelopeBatch{} }
+func (m *EnvelopeBatch) String() string            { return proto.CompactTextString(m) }
+func (*EnvelopeBatch) ProtoMessage()               {}
+func (*EnvelopeBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *EnvelopeBatch) GetBatch() []*Envelope {
+	if m != nil {
+		return m.Batch
+	}
+	return nil
+}
+
+type BatchSenderResponse struct {
+}
+
+func (m *BatchSenderResponse) Reset()                    { *m = BatchSenderResponse{} }
+func (m *BatchSenderResponse) String() string            { return proto.CompactTextString(m) }
+func (*BatchSenderResponse) ProtoMessage()               {}
+func (*BatchSenderResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func init() {
+	proto.RegisterType((*EnvelopeBatch)(nil), "loggregator.v2.EnvelopeBatch")
+	proto.RegisterType((*BatchSenderResponse)(nil), "loggregator.v2.BatchSenderResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for BatchIngress service
+
+type BatchIngressClient interface {
+	BatchSender(ctx context.Context, opts ...grpc.CallOption) (BatchIngress_BatchSenderClient, error)
+}
+
+type batchIngressClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBatchIngressClient(cc *grpc.ClientConn) BatchIngressClient {
+	return &batchIngressClient{cc}
+}
+
+func (c *batchIngressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (BatchIngress_BatchSenderClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_BatchIngress_serviceDesc.Streams[0], c.cc, "/loggregator.v2.BatchIngress/BatchSender", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &batchIngressBatchSenderClient{stream}
+	return x, nil
+}
+
+type BatchIngress_BatchSenderClient interface {
+	Send(*EnvelopeBatch) error
+	CloseAndRecv() (*BatchSenderResponse, error)
+	grpc.ClientStream
+}
+
+type batchIngressBatchSenderClient struct {
+	grpc.ClientStream
+}
+
+func (x *batchIngressBatchSenderClient) Send(m *EnvelopeBatch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *batchIngressBatchSenderClient) CloseAndRecv() (*BatchSenderResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BatchSenderResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for BatchIngress service
+
+type BatchIngressServer interface {
+	BatchSender(BatchIngress_BatchSenderServer) error
+}
+
+func RegisterBatchIngressServer(s *grpc.Server, srv BatchIngressServer) {
+	s.RegisterService(&_BatchIngress_serviceDesc, srv)
+}
+
+func _BatchIngress_BatchSender_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BatchIngressServer).BatchSender(&batchIngressBatchSenderServer{stream})
+}
+
+type BatchIngress_BatchSenderServer interface {
+	SendAndClose(*BatchSenderResponse) error
+	Recv() (*EnvelopeBatch, error)
+	grpc.ServerStream
+}
+
+type batchIngressBatchSenderServer struct {
+	grpc.ServerStream
+}
+
+func (x *batchIngressBatchSenderServer) SendAndClose(m *BatchSenderResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *batchIngressBatchSenderServer) Recv() (*EnvelopeBatch, error) {
+	m := new(EnvelopeBatch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _BatchIngress_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "loggregator.v2.BatchIngress",
+	HandlerType: (*BatchIngressServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchSender",
+			Handler:       _BatchIngress_BatchSender_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: fileDescriptor0,
+}
+
+func init() { proto.RegisterFile("batch.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 156 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0x4e, 0x4a, 0x2c, 0x49,
+	0xce, 0xd0, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0xcb, 0xc9, 0x4f, 0x4f, 0x2f, 0x4a, 0x4d,
+	0x4f, 0x2c, 0xc9, 0x2f, 0xd2, 0x2b, 0x33, 0x92, 0xe2, 0x4b, 0xcd, 0x2b, 0x4b, 0xcd, 0xc9, 0x2f,
+	0x48, 0x85, 0xc8, 0x2b, 0xd9, 0x73, 0xf1, 0xba, 0x42, 0x45, 0x9c, 0x40, 0xda, 0x84, 0xf4, 0xb8,
+	0x58, 0xc1, 0xfa, 0x25, 0x18, 0x15, 0x98, 0x35, 0xb8, 0x8d, 0x24, 0xf4, 0x50, 0x0d, 0xd0, 0x83,
+	0xa9, 0x0e, 0x82, 0x28, 0x53, 0x12, 0xe5, 0x12, 0x06, 0x6b, 0x0c, 0x4e, 0xcd, 0x4b, 0x49, 0x2d,
+	0x0a, 0x4a, 0x2d, 0x2e, 0xc8, 0xcf, 0x2b, 0x4e, 0x35, 0x4a, 0xe5, 0xe2, 0x01, 0x0b, 0x7b, 0xe6,
+	0xa5, 0x17, 0xa5, 0x16, 0x17, 0x0b, 0x85, 0x72, 0x71, 0x23, 0x29, 0x13, 0x92, 0xc5, 0x65, 0x2c,
+	0x58, 0x91, 0x94, 0x32, 0xba, 0x34, 0x16, 0x2b, 0x94, 0x18, 0x34, 0x18, 0x93, 0xd8, 0xc0, 0xbe,
+	0x30, 0x06, 0x0c, 0x00, 0x3b, 0xfa, 0x72, 0xfa, 0xf4, 0x00, 0x00, 0x00,
+}
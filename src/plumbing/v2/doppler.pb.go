@@ -2,28 +2,6 @@
 // source: doppler.proto
 // DO NOT EDIT!
 
-/*
-Package loggregator_v2 is a generated protocol buffer package.
-
-It is generated from these files:
-	doppler.proto
-	egress.proto
-	envelope.proto
-	ingress.proto
-
-It has these top-level messages:
-	SenderResponse
-	EgressRequest
-	Filter
-	Envelope
-	Value
-	Log
-	Counter
-	Gauge
-	GaugeValue
-	Timer
-	IngressResponse
-*/
 package loggregator_v2
 
 import proto "github.com/golang/protobuf/proto"
@@ -40,19 +18,13 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the proto package it is being compiled against.
-// A compilation error at this line likely means your copy of the
-// proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
-
 type SenderResponse struct {
 }
 
 func (m *SenderResponse) Reset()                    { *m = SenderResponse{} }
 func (m *SenderResponse) String() string            { return proto.CompactTextString(m) }
 func (*SenderResponse) ProtoMessage()               {}
-func (*SenderResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+func (*SenderResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
 
 func init() {
 	proto.RegisterType((*SenderResponse)(nil), "loggregator.v2.SenderResponse")
@@ -70,6 +42,7 @@ const _ = grpc.SupportPackageIsVersion3
 
 type DopplerIngressClient interface {
 	Sender(ctx context.Context, opts ...grpc.CallOption) (DopplerIngress_SenderClient, error)
+	BatchSender(ctx context.Context, opts ...grpc.CallOption) (DopplerIngress_BatchSenderClient, error)
 }
 
 type dopplerIngressClient struct {
@@ -114,10 +87,45 @@ func (x *dopplerIngressSenderClient) CloseAndRecv() (*SenderResponse, error) {
 	return m, nil
 }
 
+func (c *dopplerIngressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (DopplerIngress_BatchSenderClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_DopplerIngress_serviceDesc.Streams[1], c.cc, "/loggregator.v2.DopplerIngress/BatchSender", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dopplerIngressBatchSenderClient{stream}
+	return x, nil
+}
+
+type DopplerIngress_BatchSenderClient interface {
+	Send(*EnvelopeBatch) error
+	CloseAndRecv() (*SenderResponse, error)
+	grpc.ClientStream
+}
+
+type dopplerIngressBatchSenderClient struct {
+	grpc.ClientStream
+}
+
+func (x *dopplerIngressBatchSenderClient) Send(m *EnvelopeBatch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dopplerIngressBatchSenderClient) CloseAndRecv() (*SenderResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(SenderResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for DopplerIngress service
 
 type DopplerIngressServer interface {
 	Sender(DopplerIngress_SenderServer) error
+	BatchSender(DopplerIngress_BatchSenderServer) error
 }
 
 func RegisterDopplerIngressServer(s *grpc.Server, srv DopplerIngressServer) {
@@ -150,6 +158,32 @@ func (x *dopplerIngressSenderServer) Recv() (*Envelope, error) {
 	return m, nil
 }
 
+func _DopplerIngress_BatchSender_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DopplerIngressServer).BatchSender(&dopplerIngressBatchSenderServer{stream})
+}
+
+type DopplerIngress_BatchSenderServer interface {
+	SendAndClose(*SenderResponse) error
+	Recv() (*EnvelopeBatch, error)
+	grpc.ServerStream
+}
+
+type dopplerIngressBatchSenderServer struct {
+	grpc.ServerStream
+}
+
+func (x *dopplerIngressBatchSenderServer) SendAndClose(m *SenderResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dopplerIngressBatchSenderServer) Recv() (*EnvelopeBatch, error) {
+	m := new(EnvelopeBatch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _DopplerIngress_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "loggregator.v2.DopplerIngress",
 	HandlerType: (*DopplerIngressServer)(nil),
@@ -160,21 +194,27 @@ var _DopplerIngress_serviceDesc = grpc.ServiceDesc{
 			Handler:       _DopplerIngress_Sender_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "BatchSender",
+			Handler:       _DopplerIngress_BatchSender_Handler,
+			ClientStreams: true,
+		},
 	},
-	Metadata: fileDescriptor0,
+	Metadata: fileDescriptor1,
 }
 
-func init() { proto.RegisterFile("doppler.proto", fileDescriptor0) }
+func init() { proto.RegisterFile("doppler.proto", fileDescriptor1) }
 
-var fileDescriptor0 = []byte{
-	// 130 bytes of a gzipped FileDescriptorProto
+var fileDescriptor1 = []byte{
+	// 154 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0x4d, 0xc9, 0x2f, 0x28,
 	0xc8, 0x49, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0xcb, 0xc9, 0x4f, 0x4f, 0x2f,
 	0x4a, 0x4d, 0x4f, 0x2c, 0xc9, 0x2f, 0xd2, 0x2b, 0x33, 0x92, 0xe2, 0x4b, 0xcd, 0x2b, 0x4b, 0xcd,
-	0xc9, 0x2f, 0x48, 0x85, 0xc8, 0x2b, 0x09, 0x70, 0xf1, 0x05, 0xa7, 0xe6, 0xa5, 0xa4, 0x16, 0x05,
-	0xa5, 0x16, 0x17, 0xe4, 0xe7, 0x15, 0xa7, 0x1a, 0x45, 0x70, 0xf1, 0xb9, 0x40, 0x8c, 0xf0, 0xcc,
-	0x4b, 0x2f, 0x4a, 0x2d, 0x2e, 0x16, 0x72, 0xe3, 0x62, 0x83, 0xa8, 0x11, 0x92, 0xd0, 0x43, 0x35,
-	0x4e, 0xcf, 0x15, 0x6a, 0x9a, 0x94, 0x1c, 0xba, 0x0c, 0xaa, 0xa9, 0x4a, 0x0c, 0x1a, 0x8c, 0x49,
-	0x6c, 0x60, 0x2b, 0x8d, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0xdf, 0xbc, 0x62, 0x52, 0xa3, 0x00,
-	0x00, 0x00,
+	0xc9, 0x2f, 0x48, 0x85, 0xc8, 0x4b, 0x71, 0x27, 0x25, 0x96, 0x24, 0x67, 0x40, 0x38, 0x4a, 0x02,
+	0x5c, 0x7c, 0xc1, 0xa9, 0x79, 0x29, 0xa9, 0x45, 0x41, 0xa9, 0xc5, 0x05, 0xf9, 0x79, 0xc5, 0xa9,
+	0x46, 0xab, 0x18, 0xb9, 0xf8, 0x5c, 0x20, 0x06, 0x7a, 0xe6, 0xa5, 0x17, 0xa5, 0x16, 0x17, 0x0b,
+	0xb9, 0x71, 0xb1, 0x41, 0x14, 0x09, 0x49, 0xe8, 0xa1, 0x1a, 0xae, 0xe7, 0x0a, 0x35, 0x5b, 0x4a,
+	0x0e, 0x5d, 0x06, 0xd5, 0x58, 0x25, 0x06, 0x0d, 0x46, 0xa1, 0x00, 0x2e, 0x6e, 0x27, 0x90, 0xdd,
+	0x50, 0xc3, 0x64, 0x71, 0x19, 0x06, 0x56, 0x44, 0x8c, 0x89, 0x49, 0x6c, 0x60, 0x5f, 0x18, 0x03,
+	0x06, 0x00, 0xcb, 0xe5, 0xaf, 0x3e, 0x03, 0x01, 0x00, 0x00,
 }
@@ -26,7 +26,7 @@ type EgressRequest struct {
 func (m *EgressRequest) Reset()                    { *m = EgressRequest{} }
 func (m *EgressRequest) String() string            { return proto.CompactTextString(m) }
 func (*EgressRequest) ProtoMessage()               {}
-func (*EgressRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+func (*EgressRequest) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
 
 func (m *EgressRequest) GetFilter() *Filter {
 	if m != nil {
@@ -42,7 +42,7 @@ type Filter struct {
 func (m *Filter) Reset()                    { *m = Filter{} }
 func (m *Filter) String() string            { return proto.CompactTextString(m) }
 func (*Filter) ProtoMessage()               {}
-func (*Filter) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+func (*Filter) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
 
 func init() {
 	proto.RegisterType((*EgressRequest)(nil), "loggregator.v2.EgressRequest")
@@ -145,12 +145,12 @@ var _Egress_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 		},
 	},
-	Metadata: fileDescriptor1,
+	Metadata: fileDescriptor2,
 }
 
-func init() { proto.RegisterFile("egress.proto", fileDescriptor1) }
+func init() { proto.RegisterFile("egress.proto", fileDescriptor2) }
 
-var fileDescriptor1 = []byte{
+var fileDescriptor2 = []byte{
 	// 195 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0x49, 0x4d, 0x2f, 0x4a,
 	0x2d, 0x2e, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0xcb, 0xc9, 0x4f, 0x4f, 0x2f, 0x4a,
@@ -0,0 +1,185 @@
+// Code generated by protoc-gen-go.
+// source: egress_query.proto
+// DO NOT EDIT!
+
+package loggregator_v2
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type RecentLogsRequest struct {
+	SourceId string `protobuf:"bytes,1,opt,name=source_id,json=sourceId" json:"source_id,omitempty"`
+}
+
+func (m *RecentLogsRequest) Reset()                    { *m = RecentLogsRequest{} }
+func (m *RecentLogsRequest) String() string            { return proto.CompactTextString(m) }
+func (*RecentLogsRequest) ProtoMessage()               {}
+func (*RecentLogsRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
+
+type ContainerMetricRequest struct {
+	SourceId string `protobuf:"bytes,1,opt,name=source_id,json=sourceId" json:"source_id,omitempty"`
+}
+
+func (m *ContainerMetricRequest) Reset()                    { *m = ContainerMetricRequest{} }
+func (m *ContainerMetricRequest) String() string            { return proto.CompactTextString(m) }
+func (*ContainerMetricRequest) ProtoMessage()               {}
+func (*ContainerMetricRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
+
+type QueryResponse struct {
+	Envelopes []*Envelope `protobuf:"bytes,1,rep,name=envelopes" json:"envelopes,omitempty"`
+}
+
+func (m *QueryResponse) Reset()                    { *m = QueryResponse{} }
+func (m *QueryResponse) String() string            { return proto.CompactTextString(m) }
+func (*QueryResponse) ProtoMessage()               {}
+func (*QueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
+
+func (m *QueryResponse) GetEnvelopes() []*Envelope {
+	if m != nil {
+		return m.Envelopes
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*RecentLogsRequest)(nil), "loggregator.v2.RecentLogsRequest")
+	proto.RegisterType((*ContainerMetricRequest)(nil), "loggregator.v2.ContainerMetricRequest")
+	proto.RegisterType((*QueryResponse)(nil), "loggregator.v2.QueryResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for EgressQuery service
+
+type EgressQueryClient interface {
+	RecentLogs(ctx context.Context, in *RecentLogsRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	ContainerMetrics(ctx context.Context, in *ContainerMetricRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+}
+
+type egressQueryClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewEgressQueryClient(cc *grpc.ClientConn) EgressQueryClient {
+	return &egressQueryClient{cc}
+}
+
+func (c *egressQueryClient) RecentLogs(ctx context.Context, in *RecentLogsRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := grpc.Invoke(ctx, "/loggregator.v2.EgressQuery/RecentLogs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *egressQueryClient) ContainerMetrics(ctx context.Context, in *ContainerMetricRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := grpc.Invoke(ctx, "/loggregator.v2.EgressQuery/ContainerMetrics", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for EgressQuery service
+
+type EgressQueryServer interface {
+	RecentLogs(context.Context, *RecentLogsRequest) (*QueryResponse, error)
+	ContainerMetrics(context.Context, *ContainerMetricRequest) (*QueryResponse, error)
+}
+
+func RegisterEgressQueryServer(s *grpc.Server, srv EgressQueryServer) {
+	s.RegisterService(&_EgressQuery_serviceDesc, srv)
+}
+
+func _EgressQuery_RecentLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecentLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EgressQueryServer).RecentLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/loggregator.v2.EgressQuery/RecentLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EgressQueryServer).RecentLogs(ctx, req.(*RecentLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EgressQuery_ContainerMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EgressQueryServer).ContainerMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/loggregator.v2.EgressQuery/ContainerMetrics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EgressQueryServer).ContainerMetrics(ctx, req.(*ContainerMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _EgressQuery_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "loggregator.v2.EgressQuery",
+	HandlerType: (*EgressQueryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RecentLogs",
+			Handler:    _EgressQuery_RecentLogs_Handler,
+		},
+		{
+			MethodName: "ContainerMetrics",
+			Handler:    _EgressQuery_ContainerMetrics_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: fileDescriptor3,
+}
+
+func init() { proto.RegisterFile("egress_query.proto", fileDescriptor3) }
+
+var fileDescriptor3 = []byte{
+	// 230 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x4a, 0x4d, 0x2f, 0x4a,
+	0x2d, 0x2e, 0x8e, 0x2f, 0x2c, 0x4d, 0x2d, 0xaa, 0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2,
+	0xcb, 0xc9, 0x4f, 0x4f, 0x2f, 0x4a, 0x4d, 0x4f, 0x2c, 0xc9, 0x2f, 0xd2, 0x2b, 0x33, 0x92, 0xe2,
+	0x4b, 0xcd, 0x2b, 0x4b, 0xcd, 0xc9, 0x2f, 0x48, 0x85, 0xc8, 0x2b, 0x19, 0x70, 0x09, 0x06, 0xa5,
+	0x26, 0xa7, 0xe6, 0x95, 0xf8, 0xe4, 0xa7, 0x17, 0x07, 0xa5, 0x16, 0x96, 0xa6, 0x16, 0x97, 0x08,
+	0x49, 0x73, 0x71, 0x16, 0xe7, 0x97, 0x16, 0x25, 0xa7, 0xc6, 0x67, 0xa6, 0x48, 0x30, 0x2a, 0x30,
+	0x6a, 0x70, 0x06, 0x71, 0x40, 0x04, 0x3c, 0x53, 0x94, 0x4c, 0xb9, 0xc4, 0x9c, 0xf3, 0xf3, 0x4a,
+	0x12, 0x33, 0xf3, 0x52, 0x8b, 0x7c, 0x53, 0x4b, 0x8a, 0x32, 0x93, 0x89, 0xd2, 0xe6, 0xce, 0xc5,
+	0x1b, 0x08, 0x72, 0x57, 0x50, 0x6a, 0x71, 0x41, 0x7e, 0x5e, 0x71, 0xaa, 0x90, 0x19, 0x17, 0x27,
+	0xcc, 0x2d, 0xc5, 0x12, 0x8c, 0x0a, 0xcc, 0x1a, 0xdc, 0x46, 0x12, 0x7a, 0xa8, 0xae, 0xd5, 0x73,
+	0x85, 0x2a, 0x08, 0x42, 0x28, 0x35, 0xda, 0xc3, 0xc8, 0xc5, 0xed, 0x0a, 0xf6, 0x28, 0xd8, 0x3c,
+	0xa1, 0x00, 0x2e, 0x2e, 0x84, 0x0f, 0x84, 0x14, 0xd1, 0x8d, 0xc0, 0xf0, 0x9d, 0x94, 0x2c, 0xba,
+	0x12, 0x14, 0x77, 0x29, 0x31, 0x08, 0x45, 0x73, 0x09, 0xa0, 0xf9, 0xb0, 0x58, 0x48, 0x0d, 0x5d,
+	0x13, 0xf6, 0x30, 0x20, 0x68, 0x78, 0x12, 0x1b, 0x38, 0xdc, 0x8d, 0x01, 0x03, 0x00, 0xd7, 0xa3,
+	0x60, 0xaa, 0xad, 0x01, 0x00, 0x00,
+}
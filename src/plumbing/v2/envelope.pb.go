@@ -32,7 +32,7 @@ var Log_Type_value = map[string]int32{
 func (x Log_Type) String() string {
 	return proto.EnumName(Log_Type_name, int32(x))
 }
-func (Log_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor2, []int{2, 0} }
+func (Log_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor4, []int{2, 0} }
 
 type Envelope struct {
 	Timestamp int64             `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
@@ -49,7 +49,7 @@ type Envelope struct {
 func (m *Envelope) Reset()                    { *m = Envelope{} }
 func (m *Envelope) String() string            { return proto.CompactTextString(m) }
 func (*Envelope) ProtoMessage()               {}
-func (*Envelope) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
+func (*Envelope) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{0} }
 
 type isEnvelope_Message interface {
 	isEnvelope_Message()
@@ -238,7 +238,7 @@ type Value struct {
 func (m *Value) Reset()                    { *m = Value{} }
 func (m *Value) String() string            { return proto.CompactTextString(m) }
 func (*Value) ProtoMessage()               {}
-func (*Value) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
+func (*Value) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{1} }
 
 type isValue_Data interface {
 	isValue_Data()
@@ -373,7 +373,7 @@ type Log struct {
 func (m *Log) Reset()                    { *m = Log{} }
 func (m *Log) String() string            { return proto.CompactTextString(m) }
 func (*Log) ProtoMessage()               {}
-func (*Log) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+func (*Log) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{2} }
 
 type Counter struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -386,7 +386,7 @@ type Counter struct {
 func (m *Counter) Reset()                    { *m = Counter{} }
 func (m *Counter) String() string            { return proto.CompactTextString(m) }
 func (*Counter) ProtoMessage()               {}
-func (*Counter) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+func (*Counter) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{3} }
 
 type isCounter_Value interface {
 	isCounter_Value()
@@ -494,7 +494,7 @@ type Gauge struct {
 func (m *Gauge) Reset()                    { *m = Gauge{} }
 func (m *Gauge) String() string            { return proto.CompactTextString(m) }
 func (*Gauge) ProtoMessage()               {}
-func (*Gauge) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+func (*Gauge) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{4} }
 
 func (m *Gauge) GetMetrics() map[string]*GaugeValue {
 	if m != nil {
@@ -511,7 +511,7 @@ type GaugeValue struct {
 func (m *GaugeValue) Reset()                    { *m = GaugeValue{} }
 func (m *GaugeValue) String() string            { return proto.CompactTextString(m) }
 func (*GaugeValue) ProtoMessage()               {}
-func (*GaugeValue) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{5} }
+func (*GaugeValue) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{5} }
 
 type Timer struct {
 	Name  string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -522,7 +522,7 @@ type Timer struct {
 func (m *Timer) Reset()                    { *m = Timer{} }
 func (m *Timer) String() string            { return proto.CompactTextString(m) }
 func (*Timer) ProtoMessage()               {}
-func (*Timer) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{6} }
+func (*Timer) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{6} }
 
 func init() {
 	proto.RegisterType((*Envelope)(nil), "loggregator.v2.Envelope")
@@ -535,9 +535,9 @@ func init() {
 	proto.RegisterEnum("loggregator.v2.Log_Type", Log_Type_name, Log_Type_value)
 }
 
-func init() { proto.RegisterFile("envelope.proto", fileDescriptor2) }
+func init() { proto.RegisterFile("envelope.proto", fileDescriptor4) }
 
-var fileDescriptor2 = []byte{
+var fileDescriptor4 = []byte{
 	// 523 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x6c, 0x93, 0x51, 0x8b, 0xd3, 0x40,
 	0x10, 0xc7, 0xbb, 0xb7, 0xc9, 0xe5, 0x32, 0x77, 0x94, 0xb2, 0x56, 0x5d, 0xaa, 0x0f, 0x25, 0x2f,
@@ -19,6 +19,16 @@ tmp_dir=$(mktemp -d)
 mkdir -p $tmp_dir/loggregator
 
 cp $GOPATH/src/github.com/cloudfoundry/loggregator-api/v2/*proto $tmp_dir/loggregator
+
+# The local protos only add to loggregator-api. They must not replace any of
+# its files.
+for proto in *.proto; do
+    if [ -e $tmp_dir/loggregator/$proto ]; then
+        echo "$proto would replace the loggregator-api $proto" >&2
+        rm -r $tmp_dir
+        exit 1
+    fi
+done
 cp *.proto $tmp_dir/loggregator
 
 protoc $tmp_dir/loggregator/*.proto --go_out=plugins=grpc:. --proto_path=$tmp_dir/loggregator
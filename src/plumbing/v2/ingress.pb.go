@@ -24,7 +24,7 @@ type IngressResponse struct {
 func (m *IngressResponse) Reset()                    { *m = IngressResponse{} }
 func (m *IngressResponse) String() string            { return proto.CompactTextString(m) }
 func (*IngressResponse) ProtoMessage()               {}
-func (*IngressResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
+func (*IngressResponse) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{0} }
 
 func init() {
 	proto.RegisterType((*IngressResponse)(nil), "loggregator.v2.IngressResponse")
@@ -133,12 +133,12 @@ var _Ingress_serviceDesc = grpc.ServiceDesc{
 			ClientStreams: true,
 		},
 	},
-	Metadata: fileDescriptor3,
+	Metadata: fileDescriptor5,
 }
 
-func init() { proto.RegisterFile("ingress.proto", fileDescriptor3) }
+func init() { proto.RegisterFile("ingress.proto", fileDescriptor5) }
 
-var fileDescriptor3 = []byte{
+var fileDescriptor5 = []byte{
 	// 123 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0xcd, 0xcc, 0x4b, 0x2f,
 	0x4a, 0x2d, 0x2e, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0xcb, 0xc9, 0x4f, 0x4f, 0x2f,
@@ -0,0 +1,226 @@
+// Code generated by protoc-gen-go.
+// source: selective_egress.proto
+// DO NOT EDIT!
+
+package loggregator_v2
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type Selector_Type int32
+
+const (
+	Selector_ANY     Selector_Type = 0
+	Selector_LOG     Selector_Type = 1
+	Selector_COUNTER Selector_Type = 2
+	Selector_GAUGE   Selector_Type = 3
+	Selector_TIMER   Selector_Type = 4
+)
+
+var Selector_Type_name = map[int32]string{
+	0: "ANY",
+	1: "LOG",
+	2: "COUNTER",
+	3: "GAUGE",
+	4: "TIMER",
+}
+var Selector_Type_value = map[string]int32{
+	"ANY":     0,
+	"LOG":     1,
+	"COUNTER": 2,
+	"GAUGE":   3,
+	"TIMER":   4,
+}
+
+func (x Selector_Type) String() string {
+	return proto.EnumName(Selector_Type_name, int32(x))
+}
+func (Selector_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor6, []int{1, 0} }
+
+// SelectiveEgressRequest without selectors matches every envelope of the
+// EgressRequest.
+type SelectiveEgressRequest struct {
+	Request   *EgressRequest `protobuf:"bytes,1,opt,name=request" json:"request,omitempty"`
+	Selectors []*Selector    `protobuf:"bytes,2,rep,name=selectors" json:"selectors,omitempty"`
+}
+
+func (m *SelectiveEgressRequest) Reset()                    { *m = SelectiveEgressRequest{} }
+func (m *SelectiveEgressRequest) String() string            { return proto.CompactTextString(m) }
+func (*SelectiveEgressRequest) ProtoMessage()               {}
+func (*SelectiveEgressRequest) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{0} }
+
+func (m *SelectiveEgressRequest) GetRequest() *EgressRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *SelectiveEgressRequest) GetSelectors() []*Selector {
+	if m != nil {
+		return m.Selectors
+	}
+	return nil
+}
+
+// Selector matches envelopes by type, counter or gauge name, and tags.
+type Selector struct {
+	Type Selector_Type     `protobuf:"varint,1,opt,name=type,enum=loggregator.v2.Selector_Type" json:"type,omitempty"`
+	Name string            `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Tags map[string]string `protobuf:"bytes,3,rep,name=tags" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *Selector) Reset()                    { *m = Selector{} }
+func (m *Selector) String() string            { return proto.CompactTextString(m) }
+func (*Selector) ProtoMessage()               {}
+func (*Selector) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{1} }
+
+func (m *Selector) GetTags() map[string]string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SelectiveEgressRequest)(nil), "loggregator.v2.SelectiveEgressRequest")
+	proto.RegisterType((*Selector)(nil), "loggregator.v2.Selector")
+	proto.RegisterEnum("loggregator.v2.Selector_Type", Selector_Type_name, Selector_Type_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for SelectiveEgress service
+
+type SelectiveEgressClient interface {
+	SelectiveReceiver(ctx context.Context, in *SelectiveEgressRequest, opts ...grpc.CallOption) (SelectiveEgress_SelectiveReceiverClient, error)
+}
+
+type selectiveEgressClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSelectiveEgressClient(cc *grpc.ClientConn) SelectiveEgressClient {
+	return &selectiveEgressClient{cc}
+}
+
+func (c *selectiveEgressClient) SelectiveReceiver(ctx context.Context, in *SelectiveEgressRequest, opts ...grpc.CallOption) (SelectiveEgress_SelectiveReceiverClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_SelectiveEgress_serviceDesc.Streams[0], c.cc, "/loggregator.v2.SelectiveEgress/SelectiveReceiver", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &selectiveEgressSelectiveReceiverClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SelectiveEgress_SelectiveReceiverClient interface {
+	Recv() (*Envelope, error)
+	grpc.ClientStream
+}
+
+type selectiveEgressSelectiveReceiverClient struct {
+	grpc.ClientStream
+}
+
+func (x *selectiveEgressSelectiveReceiverClient) Recv() (*Envelope, error) {
+	m := new(Envelope)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for SelectiveEgress service
+
+type SelectiveEgressServer interface {
+	SelectiveReceiver(*SelectiveEgressRequest, SelectiveEgress_SelectiveReceiverServer) error
+}
+
+func RegisterSelectiveEgressServer(s *grpc.Server, srv SelectiveEgressServer) {
+	s.RegisterService(&_SelectiveEgress_serviceDesc, srv)
+}
+
+func _SelectiveEgress_SelectiveReceiver_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SelectiveEgressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SelectiveEgressServer).SelectiveReceiver(m, &selectiveEgressSelectiveReceiverServer{stream})
+}
+
+type SelectiveEgress_SelectiveReceiverServer interface {
+	Send(*Envelope) error
+	grpc.ServerStream
+}
+
+type selectiveEgressSelectiveReceiverServer struct {
+	grpc.ServerStream
+}
+
+func (x *selectiveEgressSelectiveReceiverServer) Send(m *Envelope) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _SelectiveEgress_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "loggregator.v2.SelectiveEgress",
+	HandlerType: (*SelectiveEgressServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SelectiveReceiver",
+			Handler:       _SelectiveEgress_SelectiveReceiver_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: fileDescriptor6,
+}
+
+func init() { proto.RegisterFile("selective_egress.proto", fileDescriptor6) }
+
+var fileDescriptor6 = []byte{
+	// 336 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x92, 0xc1, 0x4a, 0xc3, 0x40,
+	0x10, 0x86, 0xbb, 0x49, 0x6a, 0xcd, 0x54, 0x6a, 0x1c, 0xa4, 0x84, 0x80, 0x50, 0x72, 0x90, 0x9e,
+	0x82, 0x46, 0x68, 0x45, 0x4f, 0x45, 0x42, 0x11, 0xb4, 0x85, 0x6d, 0x7b, 0xe8, 0x49, 0x62, 0x19,
+	0x42, 0x31, 0x36, 0x71, 0x93, 0x06, 0xf2, 0x08, 0x3e, 0xb5, 0x92, 0x4d, 0x53, 0x6d, 0xab, 0xb7,
+	0x7f, 0x67, 0xbe, 0x9d, 0xf9, 0x77, 0x67, 0xa0, 0x9d, 0x50, 0x48, 0x8b, 0x74, 0x99, 0xd1, 0x0b,
+	0x05, 0x82, 0x92, 0xc4, 0x89, 0x45, 0x94, 0x46, 0xd8, 0x0a, 0xa3, 0x20, 0x10, 0x14, 0xf8, 0x69,
+	0x24, 0x9c, 0xcc, 0xb5, 0x5a, 0xb4, 0xca, 0x28, 0x8c, 0x62, 0x2a, 0xf3, 0xd6, 0xc9, 0x6f, 0xda,
+	0xfe, 0x64, 0xd0, 0x9e, 0x54, 0x85, 0x3c, 0x99, 0xe1, 0xf4, 0xb1, 0xa6, 0x24, 0xc5, 0x3e, 0x34,
+	0x44, 0x29, 0x4d, 0xd6, 0x61, 0xdd, 0xa6, 0x7b, 0xe1, 0xec, 0x96, 0x76, 0x76, 0x78, 0x5e, 0xd1,
+	0xd8, 0x03, 0xbd, 0xf4, 0x16, 0x89, 0xc4, 0x54, 0x3a, 0x6a, 0xb7, 0xe9, 0x9a, 0xfb, 0x57, 0x27,
+	0x1b, 0x80, 0xff, 0xa0, 0xf6, 0x17, 0x83, 0xe3, 0x2a, 0x8e, 0xd7, 0xa0, 0xa5, 0x79, 0x4c, 0xb2,
+	0x75, 0xeb, 0xb0, 0x75, 0xc5, 0x39, 0xd3, 0x3c, 0x26, 0x2e, 0x51, 0x44, 0xd0, 0x56, 0xfe, 0x3b,
+	0x99, 0x4a, 0x87, 0x75, 0x75, 0x2e, 0x35, 0xf6, 0x40, 0x4b, 0xfd, 0x20, 0x31, 0x55, 0x69, 0xc3,
+	0xfe, 0xbf, 0x8c, 0x1f, 0x24, 0xde, 0x2a, 0x15, 0x39, 0x97, 0xbc, 0xd5, 0x07, 0x7d, 0x1b, 0x42,
+	0x03, 0xd4, 0x37, 0xca, 0xa5, 0x15, 0x9d, 0x17, 0x12, 0xcf, 0xa1, 0x9e, 0xf9, 0xe1, 0xba, 0xea,
+	0x55, 0x1e, 0xee, 0x94, 0x5b, 0x66, 0xdf, 0x83, 0x56, 0x58, 0xc2, 0x06, 0xa8, 0x83, 0xd1, 0xdc,
+	0xa8, 0x15, 0xe2, 0x69, 0x3c, 0x34, 0x18, 0x36, 0xa1, 0xf1, 0x30, 0x9e, 0x8d, 0xa6, 0x1e, 0x37,
+	0x14, 0xd4, 0xa1, 0x3e, 0x1c, 0xcc, 0x86, 0x9e, 0xa1, 0x16, 0x72, 0xfa, 0xf8, 0xec, 0x71, 0x43,
+	0x73, 0x43, 0x38, 0xdd, 0x1b, 0x06, 0xce, 0xe1, 0x6c, 0x1b, 0xe2, 0xb4, 0xa0, 0x65, 0x46, 0x02,
+	0x2f, 0xff, 0x7e, 0xc7, 0xfe, 0x08, 0xad, 0x83, 0x6f, 0xf7, 0x36, 0xbb, 0x60, 0xd7, 0xae, 0xd8,
+	0xeb, 0x91, 0x5c, 0x81, 0x9b, 0xef, 0x01, 0x00, 0x8c, 0xcf, 0xef, 0x36, 0x4a, 0x02, 0x00, 0x00,
+}
@@ -6,6 +6,8 @@ import (
 	"net"
 	"rlp/internal/egress"
 	"rlp/internal/ingress"
+	"sync"
+	"time"
 	"trafficcontroller/grpcconnector"
 
 	v2 "plumbing/v2"
@@ -16,25 +18,41 @@ import (
 // RLP represents the reverse log proxy component. It connects to various gRPC
 // servers to ingress data and opens a gRPC server to egress data.
 type RLP struct {
-	egressPort       int
-	egressServerOpts []grpc.ServerOption
+	egressPort        int
+	egressServerOpts  []grpc.ServerOption
+	egressPermissions map[string]egress.Permission
+	egressAllowAll    bool
 
-	ingressAddrs    []string
-	ingressDialOpts []grpc.DialOption
+	ingressAddrs           []string
+	ingressDialOpts        []grpc.DialOption
+	ingressResolveInterval time.Duration
+
+	metricBatchInterval time.Duration
 
 	receiver *ingress.Receiver
+	querier  *ingress.Querier
 
 	egressAddr     net.Addr
 	egressListener net.Listener
 	egressServer   *grpc.Server
+	egressHandler  *egress.Server
+
+	stopTimeout time.Duration
+
+	mu      sync.Mutex
+	stopped chan struct{}
 }
 
 // NewRLP returns a new unstarted RLP.
 func NewRLP(opts ...RLPOption) *RLP {
 	rlp := &RLP{
-		ingressAddrs:     []string{"doppler.service.cf.internal"},
-		ingressDialOpts:  []grpc.DialOption{grpc.WithInsecure()},
-		egressServerOpts: []grpc.ServerOption{},
+		ingressAddrs:           []string{"doppler.service.cf.internal:8082"},
+		ingressDialOpts:        []grpc.DialOption{grpc.WithInsecure()},
+		ingressResolveInterval: 15 * time.Second,
+		metricBatchInterval:    5 * time.Second,
+		egressServerOpts:       []grpc.ServerOption{},
+		stopTimeout:            10 * time.Second,
+		stopped:                make(chan struct{}),
 	}
 	for _, o := range opts {
 		o(rlp)
@@ -60,13 +78,40 @@ func WithEgressServerOptions(opts ...grpc.ServerOption) RLPOption {
 	}
 }
 
-// WithIngressAddrs specifies the addresses used to connect to ingress data.
+// WithEgressPermissions specifies the permissions of each client certificate
+// name (CN or DNS SAN) when egressing data. Clients without permissions are
+// denied.
+func WithEgressPermissions(perms map[string]egress.Permission) RLPOption {
+	return func(r *RLP) {
+		r.egressPermissions = perms
+	}
+}
+
+// WithEgressAllowAll authorizes every client with a valid certificate to
+// egress any data, including the firehose. Egress permissions are ignored.
+func WithEgressAllowAll() RLPOption {
+	return func(r *RLP) {
+		r.egressAllowAll = true
+	}
+}
+
+// WithIngressAddrs specifies the host:port addresses used to connect to
+// ingress data. Hosts are re-resolved so that Dopplers that are added or
+// removed are noticed.
 func WithIngressAddrs(addrs []string) RLPOption {
 	return func(r *RLP) {
 		r.ingressAddrs = addrs
 	}
 }
 
+// WithIngressResolveInterval specifies how often the ingress addresses are
+// re-resolved.
+func WithIngressResolveInterval(d time.Duration) RLPOption {
+	return func(r *RLP) {
+		r.ingressResolveInterval = d
+	}
+}
+
 // WithIngressDialOptions specifies the dial options used when connecting to
 // the gRPC server to ingress data.
 func WithIngressDialOptions(opts ...grpc.DialOption) RLPOption {
@@ -75,21 +120,50 @@ func WithIngressDialOptions(opts ...grpc.DialOption) RLPOption {
 	}
 }
 
+// WithMetricBatchInterval specifies how often the ingress metrics are
+// flushed to the metric package.
+func WithMetricBatchInterval(d time.Duration) RLPOption {
+	return func(r *RLP) {
+		r.metricBatchInterval = d
+	}
+}
+
+// WithStopTimeout specifies how long Stop waits for in-flight requests to
+// finish before closing every connection.
+func WithStopTimeout(d time.Duration) RLPOption {
+	return func(r *RLP) {
+		r.stopTimeout = d
+	}
+}
+
 // Start starts a remote log proxy. This connects to various gRPC servers and
 // listens for gRPC connections for egressing data.
 func (r *RLP) Start() {
+	r.mu.Lock()
+	select {
+	case <-r.stopped:
+		r.mu.Unlock()
+		return
+	default:
+	}
 	r.setupIngress()
 	r.setupEgress()
+	r.mu.Unlock()
+
 	r.serveEgress()
 }
 
 func (r *RLP) setupIngress() {
-	finder := ingress.NewFinder(r.ingressAddrs)
+	finder := ingress.NewFinder(
+		r.ingressAddrs,
+		ingress.WithResolveInterval(r.ingressResolveInterval),
+	)
 	pool := grpcconnector.NewPool(20, r.ingressDialOpts...)
-	batcher := &ingress.NullMetricBatcher{} // TODO: Add real metrics
-	connector := grpcconnector.New(1000, pool, finder, batcher)
+	batcher := ingress.NewMetricBatcher(r.metricBatchInterval)
+	connector := grpcconnector.New(1000, pool, finder, batcher, grpcconnector.WithDopplerTag())
 	converter := ingress.NewConverter()
 	r.receiver = ingress.NewReceiver(converter, connector)
+	r.querier = ingress.NewQuerier(converter, connector)
 }
 
 func (r *RLP) setupEgress() {
@@ -100,11 +174,64 @@ func (r *RLP) setupEgress() {
 	}
 	r.egressAddr = r.egressListener.Addr()
 	r.egressServer = grpc.NewServer(r.egressServerOpts...)
-	v2.RegisterEgressServer(r.egressServer, egress.NewServer(r.receiver))
+	r.egressHandler = egress.NewServer(r.receiver, r.querier, r.authorizer())
+	v2.RegisterEgressServer(r.egressServer, r.egressHandler)
+	v2.RegisterSelectiveEgressServer(r.egressServer, r.egressHandler)
+	v2.RegisterEgressQueryServer(r.egressServer, r.egressHandler)
+}
+
+func (r *RLP) authorizer() egress.Authorizer {
+	if r.egressAllowAll {
+		log.Print("WARNING: egress is allowed for all clients, every client with a valid certificate can read the firehose")
+		return egress.OpenAuthorizer{}
+	}
+
+	if len(r.egressPermissions) == 0 {
+		log.Print("WARNING: no egress permissions configured, all egress requests will be denied")
+	}
+	return egress.NewCertAuthorizer(r.egressPermissions)
 }
 
 func (r *RLP) serveEgress() {
-	if err := r.egressServer.Serve(r.egressListener); err != nil {
-		log.Fatal("failed to serve: ", err)
+	err := r.egressServer.Serve(r.egressListener)
+	select {
+	case <-r.stopped:
+	default:
+		if err != nil {
+			log.Fatal("failed to serve: ", err)
+		}
+	}
+}
+
+// Stop stops the egress server. In-flight subscriptions are ended cleanly
+// and Stop waits up to the stop timeout for them to finish before closing
+// every connection. Stop is safe to call while Start is still setting up.
+func (r *RLP) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.stopped:
+		return
+	default:
+	}
+	close(r.stopped)
+
+	if r.egressServer == nil {
+		return
+	}
+	r.egressHandler.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		r.egressServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(r.stopTimeout):
+		log.Print("Timed out waiting for egress connections to close")
+		r.egressServer.Stop()
 	}
 }
@@ -2,6 +2,7 @@ package app_test
 
 import (
 	"context"
+	"io"
 	"log"
 	"net"
 	"plumbing"
@@ -23,7 +24,7 @@ var _ = Describe("Start", func() {
 		doppler, dopplerLis := setupDoppler()
 		defer dopplerLis.Close()
 
-		egressLis := setupRLP(dopplerLis)
+		_, egressLis := setupRLP(dopplerLis)
 		egressStream, cleanup := setupRLPClient(egressLis)
 		defer cleanup()
 
@@ -47,6 +48,81 @@ var _ = Describe("Start", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(envelope.GetTags()["origin"].GetText()).To(Equal("some-origin"))
 	})
+
+	It("returns recent logs via egress query client", func() {
+		doppler, dopplerLis := setupDoppler()
+		defer dopplerLis.Close()
+
+		for i := 0; i < 100; i++ {
+			doppler.RecentLogsOutput.Resp <- &plumbing.RecentLogsResponse{
+				Payload: [][]byte{buildLogMessage()},
+			}
+			doppler.RecentLogsOutput.Err <- nil
+		}
+
+		_, egressLis := setupRLP(dopplerLis)
+		queryClient, cleanup := setupRLPQueryClient(egressLis)
+		defer cleanup()
+
+		f := func() []*v2.Envelope {
+			resp, err := queryClient.RecentLogs(
+				context.Background(),
+				&v2.RecentLogsRequest{SourceId: "test-app"},
+			)
+			if err != nil {
+				return nil
+			}
+			return resp.Envelopes
+		}
+		Eventually(f, 5).Should(HaveLen(1))
+
+		var req *plumbing.RecentLogsRequest
+		Expect(doppler.RecentLogsInput.Req).To(Receive(&req))
+		Expect(req.AppID).To(Equal("test-app"))
+	})
+})
+
+var _ = Describe("Stop", func() {
+	It("ends in-flight streams with an EOF", func() {
+		doppler, dopplerLis := setupDoppler()
+		defer dopplerLis.Close()
+
+		rlp, egressLis := setupRLP(dopplerLis)
+		egressStream, cleanup := setupRLPClient(egressLis)
+		defer cleanup()
+
+		Eventually(doppler.SubscribeInput.Stream, 5).Should(Receive())
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			rlp.Stop()
+		}()
+
+		_, err := egressStream.Recv()
+		Expect(err).To(Equal(io.EOF))
+		Eventually(done, 5).Should(BeClosed())
+	})
+
+	It("does not panic when stopped before it has started", func() {
+		rlp := app.NewRLP(app.WithEgressPort(0))
+		Expect(rlp.Stop).ToNot(Panic())
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			rlp.Start()
+		}()
+		Eventually(done, 5).Should(BeClosed())
+	})
+
+	It("can be stopped while it is starting", func() {
+		_, dopplerLis := setupDoppler()
+		defer dopplerLis.Close()
+
+		rlp, _ := setupRLP(dopplerLis)
+		Expect(rlp.Stop).ToNot(Panic())
+	})
 })
 
 func buildLogMessage() []byte {
@@ -84,7 +160,7 @@ func setupDoppler() (*mockDopplerServer, net.Listener) {
 	return doppler, lis
 }
 
-func setupRLP(dopplerLis net.Listener) net.Listener {
+func setupRLP(dopplerLis net.Listener) (*app.RLP, net.Listener) {
 	egressLis, err := net.Listen("tcp", "localhost:0")
 	egressLis.Close()
 	Expect(err).ToNot(HaveOccurred())
@@ -111,12 +187,36 @@ func setupRLP(dopplerLis net.Listener) net.Listener {
 		app.WithIngressAddrs([]string{dopplerLis.Addr().String()}),
 		app.WithIngressDialOptions(grpc.WithTransportCredentials(ingressTLSCredentials)),
 		app.WithEgressServerOptions(grpc.Creds(egressTLSCredentials)),
+		app.WithEgressAllowAll(),
 	)
 	go rlp.Start()
-	return egressLis
+	return rlp, egressLis
 }
 
 func setupRLPClient(egressLis net.Listener) (v2.Egress_ReceiverClient, func()) {
+	conn := dialRLP(egressLis)
+	egressClient := v2.NewEgressClient(conn)
+
+	var egressStream v2.Egress_ReceiverClient
+	Eventually(func() error {
+		var err error
+		egressStream, err = egressClient.Receiver(context.Background(), &v2.EgressRequest{})
+		return err
+	}, 5).ShouldNot(HaveOccurred())
+
+	return egressStream, func() {
+		conn.Close()
+	}
+}
+
+func setupRLPQueryClient(egressLis net.Listener) (v2.EgressQueryClient, func()) {
+	conn := dialRLP(egressLis)
+	return v2.NewEgressQueryClient(conn), func() {
+		conn.Close()
+	}
+}
+
+func dialRLP(egressLis net.Listener) *grpc.ClientConn {
 	ingressTLSCredentials, err := plumbing.NewCredentials(
 		testservers.Cert("reverselogproxy.crt"),
 		testservers.Cert("reverselogproxy.key"),
@@ -131,15 +231,5 @@ func setupRLPClient(egressLis net.Listener) (v2.Egress_ReceiverClient, func()) {
 	)
 	Expect(err).ToNot(HaveOccurred())
 
-	egressClient := v2.NewEgressClient(conn)
-
-	var egressStream v2.Egress_ReceiverClient
-	Eventually(func() error {
-		egressStream, err = egressClient.Receiver(context.Background(), &v2.EgressRequest{})
-		return err
-	}, 5).ShouldNot(HaveOccurred())
-
-	return egressStream, func() {
-		conn.Close()
-	}
+	return conn
 }
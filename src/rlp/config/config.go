@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"rlp/internal/egress"
+)
+
+type GRPC struct {
+	Port     uint16
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+type Config struct {
+	DeploymentName string
+	JobName        string
+	Index          string
+
+	GRPC              GRPC
+	EgressPermissions map[string]egress.Permission
+	EgressAllowAll    bool
+
+	DopplerAddrs                  []string
+	DopplerResolveIntervalSeconds uint
+
+	MetronAddr                      string
+	MetricBatchIntervalMilliseconds uint
+
+	StopTimeoutSeconds uint
+	PPROFPort          uint32
+}
+
+func ParseConfig(configFile string) (*Config, error) {
+	file, err := os.Open(configFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+func Parse(r io.Reader) (*Config, error) {
+	config := &Config{}
+
+	err := json.NewDecoder(r).Decode(config)
+	if err != nil {
+		return nil, err
+	}
+
+	config.setDefaults()
+
+	err = config.validate()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *Config) setDefaults() {
+	if c.JobName == "" {
+		c.JobName = "reverse_log_proxy"
+	}
+
+	if c.GRPC.Port == 0 {
+		c.GRPC.Port = 8082
+	}
+
+	if c.DopplerResolveIntervalSeconds == 0 {
+		c.DopplerResolveIntervalSeconds = 15
+	}
+
+	if c.MetronAddr == "" {
+		c.MetronAddr = "localhost:3458"
+	}
+
+	if c.MetricBatchIntervalMilliseconds == 0 {
+		c.MetricBatchIntervalMilliseconds = 5000
+	}
+
+	if c.StopTimeoutSeconds == 0 {
+		c.StopTimeoutSeconds = 10
+	}
+}
+
+func (c *Config) validate() error {
+	if len(c.DopplerAddrs) == 0 {
+		return errors.New("invalid rlp config, no DopplerAddrs provided")
+	}
+
+	if c.EgressAllowAll && len(c.EgressPermissions) > 0 {
+		return errors.New("invalid rlp config, EgressAllowAll and EgressPermissions are mutually exclusive")
+	}
+
+	if len(c.GRPC.CAFile) == 0 {
+		return errors.New("invalid rlp config, no GRPC.CAFile provided")
+	}
+
+	if len(c.GRPC.CertFile) == 0 {
+		return errors.New("invalid rlp config, no GRPC.CertFile provided")
+	}
+
+	if len(c.GRPC.KeyFile) == 0 {
+		return errors.New("invalid rlp config, no GRPC.KeyFile provided")
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package egress
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Permission describes what a client identity is allowed to read.
+type Permission struct {
+	Firehose  bool     `json:"firehose"`
+	SourceIDs []string `json:"source_ids"`
+}
+
+func (p Permission) allows(sourceID string) bool {
+	if p.Firehose {
+		return true
+	}
+
+	if sourceID == "" {
+		return false
+	}
+
+	for _, id := range p.SourceIDs {
+		if id == sourceID {
+			return true
+		}
+	}
+	return false
+}
+
+// CertAuthorizer authorizes requests based on the CN and DNS SANs of the
+// client certificate found in the gRPC peer info.
+type CertAuthorizer struct {
+	perms map[string]Permission
+}
+
+// NewCertAuthorizer returns a CertAuthorizer. The permissions are keyed by
+// certificate name (CN or DNS SAN).
+func NewCertAuthorizer(perms map[string]Permission) *CertAuthorizer {
+	return &CertAuthorizer{
+		perms: perms,
+	}
+}
+
+// Authorize returns an error if the peer is not allowed to read data for the
+// given source ID. An empty source ID refers to the firehose.
+func (a *CertAuthorizer) Authorize(ctx context.Context, sourceID string) error {
+	cert, err := peerCert(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range certNames(cert) {
+		p, ok := a.perms[name]
+		if ok && p.allows(sourceID) {
+			return nil
+		}
+	}
+
+	if sourceID == "" {
+		return errors.New("not authorized for the firehose")
+	}
+	return errors.New("not authorized for source ID")
+}
+
+func peerCert(ctx context.Context) (*x509.Certificate, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("no peer info")
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, errors.New("peer did not use TLS")
+	}
+
+	certs := tlsInfo.State.PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("peer did not provide a certificate")
+	}
+	return certs[0], nil
+}
+
+func certNames(cert *x509.Certificate) []string {
+	var names []string
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return append(names, cert.DNSNames...)
+}
+
+// OpenAuthorizer authorizes every request.
+type OpenAuthorizer struct{}
+
+// Authorize always returns nil.
+func (OpenAuthorizer) Authorize(context.Context, string) error {
+	return nil
+}
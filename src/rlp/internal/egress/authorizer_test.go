@@ -0,0 +1,93 @@
+package egress_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"rlp/internal/egress"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertAuthorizer", func() {
+	var (
+		authorizer *egress.CertAuthorizer
+	)
+
+	BeforeEach(func() {
+		authorizer = egress.NewCertAuthorizer(map[string]egress.Permission{
+			"team-a":               {SourceIDs: []string{"app-a", "app-b"}},
+			"firehose.example.com": {Firehose: true},
+		})
+	})
+
+	It("allows source IDs in the allow-list for the CN", func() {
+		ctx := peerContext("team-a")
+
+		Expect(authorizer.Authorize(ctx, "app-a")).To(Succeed())
+		Expect(authorizer.Authorize(ctx, "app-b")).To(Succeed())
+	})
+
+	It("rejects source IDs not in the allow-list", func() {
+		ctx := peerContext("team-a")
+
+		Expect(authorizer.Authorize(ctx, "app-c")).ToNot(Succeed())
+	})
+
+	It("rejects the firehose without the firehose permission", func() {
+		ctx := peerContext("team-a")
+
+		Expect(authorizer.Authorize(ctx, "")).ToNot(Succeed())
+	})
+
+	It("allows anything with the firehose permission from a DNS SAN", func() {
+		ctx := peerContext("some-cn", "firehose.example.com")
+
+		Expect(authorizer.Authorize(ctx, "")).To(Succeed())
+		Expect(authorizer.Authorize(ctx, "app-c")).To(Succeed())
+	})
+
+	It("rejects unknown names", func() {
+		ctx := peerContext("team-b")
+
+		Expect(authorizer.Authorize(ctx, "app-a")).ToNot(Succeed())
+	})
+
+	It("rejects every request when no permissions are configured", func() {
+		authorizer = egress.NewCertAuthorizer(nil)
+		ctx := peerContext("team-a")
+
+		Expect(authorizer.Authorize(ctx, "app-a")).ToNot(Succeed())
+		Expect(authorizer.Authorize(ctx, "")).ToNot(Succeed())
+	})
+
+	It("rejects peers without TLS info", func() {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{})
+
+		Expect(authorizer.Authorize(ctx, "app-a")).ToNot(Succeed())
+	})
+
+	It("rejects contexts without peer info", func() {
+		Expect(authorizer.Authorize(context.Background(), "app-a")).ToNot(Succeed())
+	})
+})
+
+func peerContext(cn string, sans ...string) context.Context {
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: cn},
+		DNSNames: sans,
+	}
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+			},
+		},
+	})
+}
@@ -18,7 +18,7 @@ type mockSubscriber struct {
 	SubscribeCalled chan bool
 	SubscribeInput  struct {
 		Ctx     chan context.Context
-		Request chan *v2.EgressRequest
+		Request chan *v2.SelectiveEgressRequest
 	}
 	SubscribeOutput struct {
 		Rx  chan func() (*v2.Envelope, error)
@@ -30,18 +30,88 @@ func newMockSubscriber() *mockSubscriber {
 	m := &mockSubscriber{}
 	m.SubscribeCalled = make(chan bool, 100)
 	m.SubscribeInput.Ctx = make(chan context.Context, 100)
-	m.SubscribeInput.Request = make(chan *v2.EgressRequest, 100)
+	m.SubscribeInput.Request = make(chan *v2.SelectiveEgressRequest, 100)
 	m.SubscribeOutput.Rx = make(chan func() (*v2.Envelope, error), 100)
 	m.SubscribeOutput.Err = make(chan error, 100)
 	return m
 }
-func (m *mockSubscriber) Subscribe(ctx context.Context, filter *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error) {
+func (m *mockSubscriber) Subscribe(ctx context.Context, filter *v2.SelectiveEgressRequest) (rx func() (*v2.Envelope, error), err error) {
 	m.SubscribeCalled <- true
 	m.SubscribeInput.Ctx <- ctx
 	m.SubscribeInput.Request <- filter
 	return <-m.SubscribeOutput.Rx, <-m.SubscribeOutput.Err
 }
 
+type mockQuerier struct {
+	RecentLogsCalled chan bool
+	RecentLogsInput  struct {
+		Ctx      chan context.Context
+		SourceID chan string
+	}
+	RecentLogsOutput struct {
+		Ret0 chan []*v2.Envelope
+	}
+	ContainerMetricsCalled chan bool
+	ContainerMetricsInput  struct {
+		Ctx      chan context.Context
+		SourceID chan string
+	}
+	ContainerMetricsOutput struct {
+		Ret0 chan []*v2.Envelope
+	}
+}
+
+func newMockQuerier() *mockQuerier {
+	m := &mockQuerier{}
+	m.RecentLogsCalled = make(chan bool, 100)
+	m.RecentLogsInput.Ctx = make(chan context.Context, 100)
+	m.RecentLogsInput.SourceID = make(chan string, 100)
+	m.RecentLogsOutput.Ret0 = make(chan []*v2.Envelope, 100)
+	m.ContainerMetricsCalled = make(chan bool, 100)
+	m.ContainerMetricsInput.Ctx = make(chan context.Context, 100)
+	m.ContainerMetricsInput.SourceID = make(chan string, 100)
+	m.ContainerMetricsOutput.Ret0 = make(chan []*v2.Envelope, 100)
+	return m
+}
+func (m *mockQuerier) RecentLogs(ctx context.Context, sourceID string) []*v2.Envelope {
+	m.RecentLogsCalled <- true
+	m.RecentLogsInput.Ctx <- ctx
+	m.RecentLogsInput.SourceID <- sourceID
+	return <-m.RecentLogsOutput.Ret0
+}
+func (m *mockQuerier) ContainerMetrics(ctx context.Context, sourceID string) []*v2.Envelope {
+	m.ContainerMetricsCalled <- true
+	m.ContainerMetricsInput.Ctx <- ctx
+	m.ContainerMetricsInput.SourceID <- sourceID
+	return <-m.ContainerMetricsOutput.Ret0
+}
+
+type mockAuthorizer struct {
+	AuthorizeCalled chan bool
+	AuthorizeInput  struct {
+		Ctx      chan context.Context
+		SourceID chan string
+	}
+	AuthorizeOutput struct {
+		Ret0 chan error
+	}
+}
+
+func newMockAuthorizer() *mockAuthorizer {
+	m := &mockAuthorizer{}
+	m.AuthorizeCalled = make(chan bool, 100)
+	m.AuthorizeInput.Ctx = make(chan context.Context, 100)
+	m.AuthorizeInput.SourceID = make(chan string, 100)
+	m.AuthorizeOutput.Ret0 = make(chan error, 100)
+	return m
+}
+func (m *mockAuthorizer) Authorize(ctx context.Context, sourceID string) error {
+	m.AuthorizeCalled <- true
+	m.AuthorizeInput.Ctx <- ctx
+	m.AuthorizeInput.SourceID <- sourceID
+	return <-m.AuthorizeOutput.Ret0
+}
+
 type mockReceiverServer struct {
 	SendCalled chan bool
 	SendInput  struct {
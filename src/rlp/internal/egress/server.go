@@ -4,27 +4,111 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"metric"
 	v2 "plumbing/v2"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 type Subscriber interface {
-	Subscribe(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error)
+	Subscribe(ctx context.Context, req *v2.SelectiveEgressRequest) (rx func() (*v2.Envelope, error), err error)
+}
+
+type Querier interface {
+	RecentLogs(ctx context.Context, sourceID string) []*v2.Envelope
+	ContainerMetrics(ctx context.Context, sourceID string) []*v2.Envelope
+}
+
+type Authorizer interface {
+	Authorize(ctx context.Context, sourceID string) error
 }
 
 type Server struct {
+	// subscriptions is accessed atomically and so is kept first to ensure
+	// 64-bit alignment.
+	subscriptions int64
+
 	subscriber Subscriber
+	querier    Querier
+	authorizer Authorizer
+
+	stopOnce sync.Once
+	stop     chan struct{}
 }
 
-func NewServer(s Subscriber) *Server {
+func NewServer(s Subscriber, q Querier, a Authorizer) *Server {
 	return &Server{
 		subscriber: s,
+		querier:    q,
+		authorizer: a,
+		stop:       make(chan struct{}),
 	}
 }
 
+// Stop ends every in-flight subscription. The streams are closed without an
+// error so that clients see a clean EOF.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
 func (s *Server) Receiver(r *v2.EgressRequest, srv v2.Egress_ReceiverServer) error {
-	rx, err := s.subscriber.Subscribe(srv.Context(), r)
+	return s.receive(&v2.SelectiveEgressRequest{Request: r}, srv)
+}
+
+// SelectiveReceiver streams the envelopes of the request that match any of
+// its selectors.
+func (s *Server) SelectiveReceiver(r *v2.SelectiveEgressRequest, srv v2.SelectiveEgress_SelectiveReceiverServer) error {
+	return s.receive(r, srv)
+}
+
+// envelopeSender is the part of the Egress and SelectiveEgress streams used
+// to send envelopes.
+type envelopeSender interface {
+	Send(*v2.Envelope) error
+	Context() context.Context
+}
+
+func (s *Server) receive(r *v2.SelectiveEgressRequest, srv envelopeSender) error {
+	ctx, cancel := context.WithCancel(srv.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var sourceID, shardID string
+	if req := r.GetRequest(); req != nil {
+		shardID = req.ShardId
+		if req.GetFilter() != nil {
+			sourceID = req.GetFilter().SourceId
+		}
+	}
+	if err := s.authorize(ctx, sourceID); err != nil {
+		return err
+	}
+
+	emitSubscriptions(atomic.AddInt64(&s.subscriptions, 1))
+	defer func() {
+		emitSubscriptions(atomic.AddInt64(&s.subscriptions, -1))
+	}()
+
+	egress := &egressCounter{
+		shardID:     shardID,
+		lastEmitted: time.Now(),
+	}
+	defer egress.emit()
+
+	rx, err := s.subscriber.Subscribe(ctx, r)
 	if err != nil {
 		log.Printf("Unable to setup subscription: %s", err)
 		return fmt.Errorf("unable to setup subscription")
@@ -37,6 +121,10 @@ func (s *Server) Receiver(r *v2.EgressRequest, srv v2.Egress_ReceiverServer) err
 		}
 
 		if err != nil {
+			if s.stopped() {
+				return nil
+			}
+
 			log.Printf("Subscribe error: %s", err)
 			return io.ErrUnexpectedEOF
 		}
@@ -45,5 +133,78 @@ func (s *Server) Receiver(r *v2.EgressRequest, srv v2.Egress_ReceiverServer) err
 			log.Printf("Send error: %s", err)
 			return io.ErrUnexpectedEOF
 		}
+		egress.inc()
 	}
 }
+
+func (s *Server) RecentLogs(ctx context.Context, r *v2.RecentLogsRequest) (*v2.QueryResponse, error) {
+	if err := s.authorize(ctx, r.SourceId); err != nil {
+		return nil, err
+	}
+
+	return &v2.QueryResponse{
+		Envelopes: s.querier.RecentLogs(ctx, r.SourceId),
+	}, nil
+}
+
+func (s *Server) ContainerMetrics(ctx context.Context, r *v2.ContainerMetricRequest) (*v2.QueryResponse, error) {
+	if err := s.authorize(ctx, r.SourceId); err != nil {
+		return nil, err
+	}
+
+	return &v2.QueryResponse{
+		Envelopes: s.querier.ContainerMetrics(ctx, r.SourceId),
+	}, nil
+}
+
+func (s *Server) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Server) authorize(ctx context.Context, sourceID string) error {
+	if err := s.authorizer.Authorize(ctx, sourceID); err != nil {
+		log.Printf("Rejected request for source ID %q: %s", sourceID, err)
+		return grpc.Errorf(codes.PermissionDenied, "%s", err)
+	}
+	return nil
+}
+
+func emitSubscriptions(n int64) {
+	metric.SetGauge("subscriptions", float64(n), "subscriptions",
+		metric.WithVersion(2, 0),
+	)
+}
+
+// egressCounter counts the envelopes sent to a single subscription and
+// emits them every 1000 envelopes or 5 seconds.
+type egressCounter struct {
+	shardID     string
+	count       uint64
+	lastEmitted time.Time
+}
+
+func (c *egressCounter) inc() {
+	c.count++
+	if c.count >= 1000 || time.Since(c.lastEmitted) > 5*time.Second {
+		c.emit()
+	}
+}
+
+func (c *egressCounter) emit() {
+	if c.count == 0 {
+		return
+	}
+
+	metric.IncCounter("egress",
+		metric.WithIncrement(c.count),
+		metric.WithVersion(2, 0),
+		metric.WithTag("shard_id", c.shardID),
+	)
+	c.count = 0
+	c.lastEmitted = time.Now()
+}
@@ -1,11 +1,14 @@
 package egress_test
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"rlp/internal/egress"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	v2 "plumbing/v2"
 
@@ -16,21 +19,47 @@ import (
 var _ = Describe("Server", func() {
 	var (
 		mockSubscriber     *mockSubscriber
+		mockQuerier        *mockQuerier
+		mockAuthorizer     *mockAuthorizer
 		mockReceiverServer *mockReceiverServer
 		server             *egress.Server
 		ctx                context.Context
 	)
 
 	BeforeEach(func() {
-		ctx = context.Background()
+		ctx = context.WithValue(context.Background(), "some-key", "some-value")
 		mockReceiverServer = newMockReceiverServer()
 		mockSubscriber = newMockSubscriber()
-		server = egress.NewServer(mockSubscriber)
+		mockQuerier = newMockQuerier()
+		mockAuthorizer = newMockAuthorizer()
+		server = egress.NewServer(mockSubscriber, mockQuerier, mockAuthorizer)
 
 		mockReceiverServer.ContextOutput.Ret0 <- ctx
 	})
 
 	Describe("Receiver()", func() {
+		Context("when the peer is not authorized", func() {
+			BeforeEach(func() {
+				mockAuthorizer.AuthorizeOutput.Ret0 <- errors.New("not authorized")
+			})
+
+			It("rejects the subscription with PermissionDenied", func() {
+				req := &v2.EgressRequest{Filter: &v2.Filter{SourceId: "some-id"}}
+				err := server.Receiver(req, mockReceiverServer)
+				Expect(grpc.Code(err)).To(Equal(codes.PermissionDenied))
+
+				Expect(mockAuthorizer.AuthorizeInput.SourceID).To(Receive(Equal("some-id")))
+				Expect(mockSubscriber.SubscribeCalled).ToNot(Receive())
+			})
+
+			It("checks the firehose when there is no filter", func() {
+				err := server.Receiver(&v2.EgressRequest{}, mockReceiverServer)
+				Expect(grpc.Code(err)).To(Equal(codes.PermissionDenied))
+
+				Expect(mockAuthorizer.AuthorizeInput.SourceID).To(Receive(Equal("")))
+			})
+		})
+
 		Context("when subscriber does not return an error", func() {
 			var (
 				rx      func() (*v2.Envelope, error)
@@ -39,6 +68,7 @@ var _ = Describe("Server", func() {
 			)
 
 			BeforeEach(func() {
+				close(mockAuthorizer.AuthorizeOutput.Ret0)
 				dataOut = make(chan *v2.Envelope, 100)
 				errOut = make(chan error, 100)
 				rx = func() (*v2.Envelope, error) {
@@ -57,7 +87,26 @@ var _ = Describe("Server", func() {
 				err := server.Receiver(req, mockReceiverServer)
 				Expect(err).To(Equal(io.EOF))
 
-				Expect(mockSubscriber.SubscribeInput.Ctx).To(Receive(Equal(ctx)))
+				var subCtx context.Context
+				Expect(mockSubscriber.SubscribeInput.Ctx).To(Receive(&subCtx))
+				Expect(subCtx.Value("some-key")).To(Equal("some-value"))
+				Expect(mockSubscriber.SubscribeInput.Request).To(Receive(Equal(
+					&v2.SelectiveEgressRequest{Request: req},
+				)))
+			})
+
+			It("uses the selectors of a selective request", func() {
+				dataOut <- nil
+				errOut <- io.EOF
+
+				req := &v2.SelectiveEgressRequest{
+					Request:   &v2.EgressRequest{Filter: &v2.Filter{SourceId: "some-id"}},
+					Selectors: []*v2.Selector{{Type: v2.Selector_COUNTER}},
+				}
+				err := server.SelectiveReceiver(req, mockReceiverServer)
+				Expect(err).To(Equal(io.EOF))
+
+				Expect(mockAuthorizer.AuthorizeInput.SourceID).To(Receive(Equal("some-id")))
 				Expect(mockSubscriber.SubscribeInput.Request).To(Receive(Equal(req)))
 			})
 
@@ -91,8 +140,35 @@ var _ = Describe("Server", func() {
 			})
 		})
 
+		Context("when the server is stopped", func() {
+			BeforeEach(func() {
+				close(mockAuthorizer.AuthorizeOutput.Ret0)
+				close(mockSubscriber.SubscribeOutput.Err)
+			})
+
+			It("cancels the subscription and returns without an error", func() {
+				rx := func() (*v2.Envelope, error) {
+					var subCtx context.Context
+					Eventually(mockSubscriber.SubscribeInput.Ctx).Should(Receive(&subCtx))
+					<-subCtx.Done()
+					return nil, subCtx.Err()
+				}
+				mockSubscriber.SubscribeOutput.Rx <- rx
+
+				errs := make(chan error, 1)
+				go func() {
+					errs <- server.Receiver(&v2.EgressRequest{}, mockReceiverServer)
+				}()
+				Eventually(mockSubscriber.SubscribeCalled).Should(Receive())
+
+				server.Stop()
+				Eventually(errs).Should(Receive(BeNil()))
+			})
+		})
+
 		Context("when subscriber returns an error", func() {
 			BeforeEach(func() {
+				close(mockAuthorizer.AuthorizeOutput.Ret0)
 				close(mockSubscriber.SubscribeOutput.Rx)
 				mockSubscriber.SubscribeOutput.Err <- fmt.Errorf("some-error")
 			})
@@ -103,4 +179,54 @@ var _ = Describe("Server", func() {
 			})
 		})
 	})
+
+	Describe("RecentLogs()", func() {
+		It("returns the envelopes from the querier", func() {
+			close(mockAuthorizer.AuthorizeOutput.Ret0)
+			envs := []*v2.Envelope{{Timestamp: 1}, {Timestamp: 2}}
+			mockQuerier.RecentLogsOutput.Ret0 <- envs
+
+			resp, err := server.RecentLogs(ctx, &v2.RecentLogsRequest{SourceId: "some-id"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Envelopes).To(Equal(envs))
+
+			Expect(mockQuerier.RecentLogsInput.Ctx).To(Receive(Equal(ctx)))
+			Expect(mockQuerier.RecentLogsInput.SourceID).To(Receive(Equal("some-id")))
+		})
+
+		It("rejects unauthorized peers with PermissionDenied", func() {
+			mockAuthorizer.AuthorizeOutput.Ret0 <- errors.New("not authorized")
+
+			_, err := server.RecentLogs(ctx, &v2.RecentLogsRequest{SourceId: "some-id"})
+			Expect(grpc.Code(err)).To(Equal(codes.PermissionDenied))
+
+			Expect(mockAuthorizer.AuthorizeInput.SourceID).To(Receive(Equal("some-id")))
+			Expect(mockQuerier.RecentLogsCalled).ToNot(Receive())
+		})
+	})
+
+	Describe("ContainerMetrics()", func() {
+		It("returns the envelopes from the querier", func() {
+			close(mockAuthorizer.AuthorizeOutput.Ret0)
+			envs := []*v2.Envelope{{Timestamp: 1}, {Timestamp: 2}}
+			mockQuerier.ContainerMetricsOutput.Ret0 <- envs
+
+			resp, err := server.ContainerMetrics(ctx, &v2.ContainerMetricRequest{SourceId: "some-id"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Envelopes).To(Equal(envs))
+
+			Expect(mockQuerier.ContainerMetricsInput.Ctx).To(Receive(Equal(ctx)))
+			Expect(mockQuerier.ContainerMetricsInput.SourceID).To(Receive(Equal("some-id")))
+		})
+
+		It("rejects unauthorized peers with PermissionDenied", func() {
+			mockAuthorizer.AuthorizeOutput.Ret0 <- errors.New("not authorized")
+
+			_, err := server.ContainerMetrics(ctx, &v2.ContainerMetricRequest{SourceId: "some-id"})
+			Expect(grpc.Code(err)).To(Equal(codes.PermissionDenied))
+
+			Expect(mockAuthorizer.AuthorizeInput.SourceID).To(Receive(Equal("some-id")))
+			Expect(mockQuerier.ContainerMetricsCalled).ToNot(Receive())
+		})
+	})
 })
@@ -1,21 +1,105 @@
 package ingress
 
-import "doppler/dopplerservice"
+import (
+	"doppler/dopplerservice"
+	"log"
+	"net"
+	"sort"
+	"time"
+)
 
+// Finder resolves a set of host:port addresses on an interval and reports
+// the resulting Doppler addresses whenever they change.
 type Finder struct {
-	event chan dopplerservice.Event
+	addrs    []string
+	interval time.Duration
+	lookup   func(host string) ([]string, error)
+
+	last []string
 }
 
-func NewFinder(addrs []string) *Finder {
-	event := make(chan dopplerservice.Event, 1)
-	event <- dopplerservice.Event{
-		GRPCDopplers: addrs,
+// FinderOption configures a Finder.
+type FinderOption func(*Finder)
+
+// WithResolveInterval sets how often the addresses are re-resolved. It
+// defaults to 15 seconds.
+func WithResolveInterval(d time.Duration) FinderOption {
+	return func(f *Finder) {
+		f.interval = d
 	}
-	return &Finder{
-		event: event,
+}
+
+// WithLookup sets the function used to resolve a host to its IPs. It
+// defaults to net.LookupHost.
+func WithLookup(lookup func(host string) ([]string, error)) FinderOption {
+	return func(f *Finder) {
+		f.lookup = lookup
 	}
 }
 
+func NewFinder(addrs []string, opts ...FinderOption) *Finder {
+	f := &Finder{
+		addrs:    addrs,
+		interval: 15 * time.Second,
+		lookup:   net.LookupHost,
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	return f
+}
+
+// Next blocks until the resolved addresses differ from the ones previously
+// returned. The first call returns as soon as the addresses resolve.
 func (f *Finder) Next() dopplerservice.Event {
-	return <-f.event
+	for {
+		addrs, err := f.resolve()
+		if err != nil {
+			log.Printf("failed to resolve doppler addresses: %s", err)
+		}
+
+		if err == nil && !equal(addrs, f.last) {
+			f.last = addrs
+			return dopplerservice.Event{
+				GRPCDopplers: addrs,
+			}
+		}
+
+		time.Sleep(f.interval)
+	}
+}
+
+func (f *Finder) resolve() ([]string, error) {
+	var resolved []string
+	for _, addr := range f.addrs {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		ips, err := f.lookup(host)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ip := range ips {
+			resolved = append(resolved, net.JoinHostPort(ip, port))
+		}
+	}
+	sort.Strings(resolved)
+
+	return resolved, nil
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
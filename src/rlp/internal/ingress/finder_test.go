@@ -1,22 +1,65 @@
 package ingress_test
 
 import (
+	"errors"
 	"rlp/internal/ingress"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("Finder", func() {
-	It("returns a doppler service event of all the dopplers", func() {
-		finder := ingress.NewFinder([]string{"1.1.1.1", "2.2.2.2"})
+	var (
+		lookups chan map[string][]string
+		lookup  func(host string) ([]string, error)
+	)
+
+	BeforeEach(func() {
+		updates := make(chan map[string][]string, 100)
+		lookups = updates
+		var current map[string][]string
+		lookup = func(host string) ([]string, error) {
+			select {
+			case current = <-updates:
+			default:
+			}
+
+			ips, ok := current[host]
+			if !ok {
+				return nil, errors.New("no such host")
+			}
+			return ips, nil
+		}
+	})
+
+	It("returns a doppler service event of all the resolved dopplers", func() {
+		lookups <- map[string][]string{
+			"doppler.example.com": {"2.2.2.2", "1.1.1.1"},
+			"3.3.3.3":             {"3.3.3.3"},
+		}
+		finder := ingress.NewFinder(
+			[]string{"doppler.example.com:8082", "3.3.3.3:8083"},
+			ingress.WithLookup(lookup),
+		)
 		event := finder.Next()
 
-		Expect(event.GRPCDopplers).To(Equal([]string{"1.1.1.1", "2.2.2.2"}))
+		Expect(event.GRPCDopplers).To(Equal([]string{
+			"1.1.1.1:8082",
+			"2.2.2.2:8082",
+			"3.3.3.3:8083",
+		}))
 	})
 
-	It("blocks after the first call", func() {
-		finder := ingress.NewFinder([]string{"1.1.1.1", "2.2.2.2"})
+	It("blocks while the resolved addresses do not change", func() {
+		lookups <- map[string][]string{
+			"doppler.example.com": {"1.1.1.1"},
+		}
+		finder := ingress.NewFinder(
+			[]string{"doppler.example.com:8082"},
+			ingress.WithLookup(lookup),
+			ingress.WithResolveInterval(time.Millisecond),
+		)
 		finder.Next()
 
 		done := make(chan struct{})
@@ -25,6 +68,50 @@ var _ = Describe("Finder", func() {
 			finder.Next()
 		}()
 
-		Consistently(done).Should(Not(BeClosed()))
+		Consistently(done).ShouldNot(BeClosed())
+	})
+
+	It("returns the new addresses when they change", func() {
+		lookups <- map[string][]string{
+			"doppler.example.com": {"1.1.1.1"},
+		}
+		finder := ingress.NewFinder(
+			[]string{"doppler.example.com:8082"},
+			ingress.WithLookup(lookup),
+			ingress.WithResolveInterval(time.Millisecond),
+		)
+		finder.Next()
+
+		lookups <- map[string][]string{
+			"doppler.example.com": {"1.1.1.1", "2.2.2.2"},
+		}
+		event := finder.Next()
+
+		Expect(event.GRPCDopplers).To(Equal([]string{
+			"1.1.1.1:8082",
+			"2.2.2.2:8082",
+		}))
+	})
+
+	It("keeps the previous addresses when resolving fails", func() {
+		lookups <- map[string][]string{
+			"doppler.example.com": {"1.1.1.1"},
+		}
+		finder := ingress.NewFinder(
+			[]string{"doppler.example.com:8082"},
+			ingress.WithLookup(lookup),
+			ingress.WithResolveInterval(time.Millisecond),
+		)
+		finder.Next()
+
+		lookups <- map[string][]string{}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			finder.Next()
+		}()
+
+		Consistently(done).ShouldNot(BeClosed())
 	})
 })
@@ -66,6 +66,50 @@ func (m *mockConverter) Convert(data []byte) (envelope *v2.Envelope, err error)
 	return <-m.ConvertOutput.Envelope, <-m.ConvertOutput.Err
 }
 
+type mockFetcher struct {
+	ContainerMetricsCalled chan bool
+	ContainerMetricsInput  struct {
+		Ctx   chan context.Context
+		AppID chan string
+	}
+	ContainerMetricsOutput struct {
+		Ret0 chan [][]byte
+	}
+	RecentLogsCalled chan bool
+	RecentLogsInput  struct {
+		Ctx   chan context.Context
+		AppID chan string
+	}
+	RecentLogsOutput struct {
+		Ret0 chan [][]byte
+	}
+}
+
+func newMockFetcher() *mockFetcher {
+	m := &mockFetcher{}
+	m.ContainerMetricsCalled = make(chan bool, 100)
+	m.ContainerMetricsInput.Ctx = make(chan context.Context, 100)
+	m.ContainerMetricsInput.AppID = make(chan string, 100)
+	m.ContainerMetricsOutput.Ret0 = make(chan [][]byte, 100)
+	m.RecentLogsCalled = make(chan bool, 100)
+	m.RecentLogsInput.Ctx = make(chan context.Context, 100)
+	m.RecentLogsInput.AppID = make(chan string, 100)
+	m.RecentLogsOutput.Ret0 = make(chan [][]byte, 100)
+	return m
+}
+func (m *mockFetcher) ContainerMetrics(ctx context.Context, appID string) [][]byte {
+	m.ContainerMetricsCalled <- true
+	m.ContainerMetricsInput.Ctx <- ctx
+	m.ContainerMetricsInput.AppID <- appID
+	return <-m.ContainerMetricsOutput.Ret0
+}
+func (m *mockFetcher) RecentLogs(ctx context.Context, appID string) [][]byte {
+	m.RecentLogsCalled <- true
+	m.RecentLogsInput.Ctx <- ctx
+	m.RecentLogsInput.AppID <- appID
+	return <-m.RecentLogsOutput.Ret0
+}
+
 type mockContext struct {
 	DeadlineCalled chan bool
 	DeadlineOutput struct {
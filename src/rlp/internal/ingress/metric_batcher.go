@@ -0,0 +1,128 @@
+package ingress
+
+import (
+	"metric"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/dropsonde/metricbatcher"
+)
+
+// v2Names maps the names of the counters emitted by the GRPCConnector onto
+// the names the RLP reports them as.
+var v2Names = map[string]string{
+	"listeners.receivedEnvelopes": "ingress",
+	"grpcConnector.slowConsumers": "slow_consumer",
+}
+
+// MetricBatcher satisfies the GRPCConnector's batcher by summing counters
+// locally and periodically forwarding them to the metric package.
+type MetricBatcher struct {
+	mu       sync.Mutex
+	counters map[string]*batchedCounter
+}
+
+type batchedCounter struct {
+	name  string
+	tags  map[string]string
+	delta uint64
+}
+
+// NewMetricBatcher returns a MetricBatcher that flushes every interval.
+func NewMetricBatcher(interval time.Duration) *MetricBatcher {
+	b := &MetricBatcher{
+		counters: make(map[string]*batchedCounter),
+	}
+	go b.run(interval)
+	return b
+}
+
+func (b *MetricBatcher) BatchCounter(name string) metricbatcher.BatchCounterChainer {
+	return &counterChainer{
+		batcher: b,
+		name:    name,
+		tags:    make(map[string]string),
+	}
+}
+
+func (b *MetricBatcher) BatchAddCounter(name string, delta uint64) {
+	b.add(name, nil, delta)
+}
+
+func (b *MetricBatcher) add(name string, tags map[string]string, delta uint64) {
+	key := counterKey(name, tags)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.counters[key]
+	if !ok {
+		c = &batchedCounter{
+			name: name,
+			tags: tags,
+		}
+		b.counters[key] = c
+	}
+	c.delta += delta
+}
+
+func (b *MetricBatcher) run(interval time.Duration) {
+	for range time.Tick(interval) {
+		b.flush()
+	}
+}
+
+func (b *MetricBatcher) flush() {
+	b.mu.Lock()
+	counters := b.counters
+	b.counters = make(map[string]*batchedCounter)
+	b.mu.Unlock()
+
+	for _, c := range counters {
+		name, ok := v2Names[c.name]
+		if !ok {
+			name = c.name
+		}
+
+		opts := []metric.IncrementOpt{
+			metric.WithIncrement(c.delta),
+			metric.WithVersion(2, 0),
+		}
+		for k, v := range c.tags {
+			opts = append(opts, metric.WithTag(k, v))
+		}
+
+		metric.IncCounter(name, opts...)
+	}
+}
+
+type counterChainer struct {
+	batcher *MetricBatcher
+	name    string
+	tags    map[string]string
+}
+
+func (c *counterChainer) SetTag(key, value string) metricbatcher.BatchCounterChainer {
+	c.tags[key] = value
+	return c
+}
+
+func (c *counterChainer) Increment() {
+	c.Add(1)
+}
+
+func (c *counterChainer) Add(delta uint64) {
+	c.batcher.add(c.name, c.tags, delta)
+}
+
+func counterKey(name string, tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for k, v := range tags {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+
+	return name + "," + strings.Join(parts, ",")
+}
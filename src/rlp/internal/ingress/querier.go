@@ -0,0 +1,83 @@
+package ingress
+
+import (
+	"log"
+	"metric"
+	v2 "plumbing/v2"
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+type Fetcher interface {
+	ContainerMetrics(ctx context.Context, appID string) [][]byte
+	RecentLogs(ctx context.Context, appID string) [][]byte
+}
+
+// Querier fetches recent logs and container metrics from every doppler and
+// converts them to v2 envelopes.
+type Querier struct {
+	converter Converter
+	fetcher   Fetcher
+}
+
+func NewQuerier(c Converter, f Fetcher) *Querier {
+	return &Querier{
+		converter: c,
+		fetcher:   f,
+	}
+}
+
+// RecentLogs returns the recent logs for the given source ID ordered by
+// timestamp.
+func (q *Querier) RecentLogs(ctx context.Context, sourceID string) []*v2.Envelope {
+	envs := q.convert(q.fetcher.RecentLogs(ctx, sourceID))
+	sort.Sort(byTimestamp(envs))
+	return envs
+}
+
+// ContainerMetrics returns the latest container metric for each instance of
+// the given source ID ordered by timestamp.
+func (q *Querier) ContainerMetrics(ctx context.Context, sourceID string) []*v2.Envelope {
+	latest := make(map[float64]*v2.Envelope)
+	for _, e := range q.convert(q.fetcher.ContainerMetrics(ctx, sourceID)) {
+		index, ok := e.GetGauge().GetMetrics()["instance_index"]
+		if !ok {
+			continue
+		}
+
+		prev, ok := latest[index.Value]
+		if !ok || prev.Timestamp < e.Timestamp {
+			latest[index.Value] = e
+		}
+	}
+
+	envs := make([]*v2.Envelope, 0, len(latest))
+	for _, e := range latest {
+		envs = append(envs, e)
+	}
+	sort.Sort(byTimestamp(envs))
+	return envs
+}
+
+func (q *Querier) convert(payloads [][]byte) []*v2.Envelope {
+	envs := make([]*v2.Envelope, 0, len(payloads))
+	for _, data := range payloads {
+		e, err := q.converter.Convert(data)
+		if err != nil {
+			log.Printf("V1->V2 convert failed: %s", err)
+			metric.IncCounter("conversion_errors",
+				metric.WithVersion(2, 0),
+			)
+			continue
+		}
+		envs = append(envs, e)
+	}
+	return envs
+}
+
+type byTimestamp []*v2.Envelope
+
+func (b byTimestamp) Len() int           { return len(b) }
+func (b byTimestamp) Less(i, j int) bool { return b[i].Timestamp < b[j].Timestamp }
+func (b byTimestamp) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -0,0 +1,114 @@
+package ingress_test
+
+import (
+	"rlp/internal/ingress"
+
+	"golang.org/x/net/context"
+
+	"github.com/cloudfoundry/sonde-go/events"
+	"github.com/gogo/protobuf/proto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Querier", func() {
+	var (
+		mockFetcher *mockFetcher
+		querier     *ingress.Querier
+	)
+
+	BeforeEach(func() {
+		mockFetcher = newMockFetcher()
+		querier = ingress.NewQuerier(ingress.NewConverter(), mockFetcher)
+	})
+
+	Describe("RecentLogs()", func() {
+		It("fetches logs for the source ID", func() {
+			mockFetcher.RecentLogsOutput.Ret0 <- nil
+			ctx := context.Background()
+
+			querier.RecentLogs(ctx, "some-id")
+
+			Expect(mockFetcher.RecentLogsInput.Ctx).To(Receive(Equal(ctx)))
+			Expect(mockFetcher.RecentLogsInput.AppID).To(Receive(Equal("some-id")))
+		})
+
+		It("returns converted envelopes sorted by timestamp", func() {
+			mockFetcher.RecentLogsOutput.Ret0 <- [][]byte{
+				buildLog(3),
+				buildLog(1),
+				[]byte("invalid"),
+				buildLog(2),
+			}
+
+			envs := querier.RecentLogs(context.Background(), "some-id")
+
+			Expect(envs).To(HaveLen(3))
+			Expect(envs[0].Timestamp).To(Equal(int64(1)))
+			Expect(envs[1].Timestamp).To(Equal(int64(2)))
+			Expect(envs[2].Timestamp).To(Equal(int64(3)))
+			Expect(envs[0].GetLog().Payload).To(Equal([]byte("some-log")))
+		})
+	})
+
+	Describe("ContainerMetrics()", func() {
+		It("fetches metrics for the source ID", func() {
+			mockFetcher.ContainerMetricsOutput.Ret0 <- nil
+			ctx := context.Background()
+
+			querier.ContainerMetrics(ctx, "some-id")
+
+			Expect(mockFetcher.ContainerMetricsInput.Ctx).To(Receive(Equal(ctx)))
+			Expect(mockFetcher.ContainerMetricsInput.AppID).To(Receive(Equal("some-id")))
+		})
+
+		It("returns the latest metric per instance sorted by timestamp", func() {
+			mockFetcher.ContainerMetricsOutput.Ret0 <- [][]byte{
+				buildContainerMetric(5, 1, 10),
+				buildContainerMetric(2, 0, 20),
+				buildContainerMetric(3, 1, 30),
+				buildContainerMetric(4, 0, 40),
+				[]byte("invalid"),
+			}
+
+			envs := querier.ContainerMetrics(context.Background(), "some-id")
+
+			Expect(envs).To(HaveLen(2))
+			Expect(envs[0].Timestamp).To(Equal(int64(4)))
+			Expect(envs[0].GetGauge().GetMetrics()["cpu"].Value).To(Equal(40.0))
+			Expect(envs[1].Timestamp).To(Equal(int64(5)))
+			Expect(envs[1].GetGauge().GetMetrics()["cpu"].Value).To(Equal(10.0))
+		})
+	})
+})
+
+func buildLog(timestamp int64) []byte {
+	b, _ := (&events.Envelope{
+		Origin:    proto.String("some-origin"),
+		EventType: events.Envelope_LogMessage.Enum(),
+		Timestamp: proto.Int64(timestamp),
+		LogMessage: &events.LogMessage{
+			Message:     []byte("some-log"),
+			MessageType: events.LogMessage_OUT.Enum(),
+			Timestamp:   proto.Int64(timestamp),
+			AppId:       proto.String("some-id"),
+		},
+	}).Marshal()
+	return b
+}
+
+func buildContainerMetric(timestamp int64, index int32, cpu float64) []byte {
+	b, _ := (&events.Envelope{
+		Origin:    proto.String("some-origin"),
+		EventType: events.Envelope_ContainerMetric.Enum(),
+		Timestamp: proto.Int64(timestamp),
+		ContainerMetric: &events.ContainerMetric{
+			ApplicationId: proto.String("some-id"),
+			InstanceIndex: proto.Int32(index),
+			CpuPercentage: proto.Float64(cpu),
+			MemoryBytes:   proto.Uint64(1),
+			DiskBytes:     proto.Uint64(1),
+		},
+	}).Marshal()
+	return b
+}
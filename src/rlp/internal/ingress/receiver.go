@@ -2,6 +2,7 @@ package ingress
 
 import (
 	"log"
+	"metric"
 	"plumbing"
 	v2 "plumbing/v2"
 
@@ -28,7 +29,7 @@ func NewReceiver(c Converter, s Subscriber) *Receiver {
 	}
 }
 
-func (r *Receiver) Subscribe(ctx context.Context, req *v2.EgressRequest) (rx func() (*v2.Envelope, error), err error) {
+func (r *Receiver) Subscribe(ctx context.Context, req *v2.SelectiveEgressRequest) (rx func() (*v2.Envelope, error), err error) {
 	v1Rx, err := r.subscriber.Subscribe(ctx, convertReq(req))
 	if err != nil {
 		return nil, err
@@ -44,6 +45,9 @@ func (r *Receiver) Subscribe(ctx context.Context, req *v2.EgressRequest) (rx fun
 		v2e, err := r.converter.Convert(data)
 		if err != nil {
 			log.Printf("V1->V2 convert failed: %s", err)
+			metric.IncCounter("conversion_errors",
+				metric.WithVersion(2, 0),
+			)
 			return nil, err
 		}
 
@@ -51,18 +55,43 @@ func (r *Receiver) Subscribe(ctx context.Context, req *v2.EgressRequest) (rx fun
 	}, nil
 }
 
-func convertReq(v2req *v2.EgressRequest) *plumbing.SubscriptionRequest {
-	return &plumbing.SubscriptionRequest{
-		ShardID: v2req.ShardId,
-		Filter:  convertFilter(v2req.GetFilter()),
+func convertReq(v2req *v2.SelectiveEgressRequest) *plumbing.SubscriptionRequest {
+	req := &plumbing.SubscriptionRequest{
+		Filter: convertFilter(v2req.GetRequest().GetFilter(), v2req.GetSelectors()),
 	}
+	if v2req.GetRequest() != nil {
+		req.ShardID = v2req.GetRequest().ShardId
+	}
+	return req
 }
 
-func convertFilter(v2filter *v2.Filter) *plumbing.Filter {
-	if v2filter == nil {
+func convertFilter(v2filter *v2.Filter, v2selectors []*v2.Selector) *plumbing.Filter {
+	if v2filter == nil && len(v2selectors) == 0 {
 		return nil
 	}
+
+	var appID string
+	if v2filter != nil {
+		appID = v2filter.SourceId
+	}
 	return &plumbing.Filter{
-		AppID: v2filter.SourceId,
+		AppID:     appID,
+		Selectors: convertSelectors(v2selectors),
+	}
+}
+
+func convertSelectors(v2selectors []*v2.Selector) []*plumbing.Selector {
+	if len(v2selectors) == 0 {
+		return nil
+	}
+
+	selectors := make([]*plumbing.Selector, 0, len(v2selectors))
+	for _, s := range v2selectors {
+		selectors = append(selectors, &plumbing.Selector{
+			Type: plumbing.Selector_Type(s.Type),
+			Name: s.Name,
+			Tags: s.Tags,
+		})
 	}
+	return selectors
 }
@@ -33,14 +33,16 @@ var _ = Describe("Receiver", func() {
 		})
 
 		It("subscribes to data", func() {
-			req := &v2.EgressRequest{
-				ShardId: "some-id",
-				Filter: &v2.Filter{
-					SourceId: "some-source-id",
+			req := &v2.SelectiveEgressRequest{
+				Request: &v2.EgressRequest{
+					ShardId: "some-id",
+					Filter: &v2.Filter{
+						SourceId: "some-source-id",
+					},
 				},
 			}
 			expectedReq := &plumbing.SubscriptionRequest{
-				ShardID: req.ShardId,
+				ShardID: "some-id",
 				Filter:  &plumbing.Filter{AppID: "some-source-id"},
 			}
 			rx.Subscribe(context.Background(), req)
@@ -49,10 +51,34 @@ var _ = Describe("Receiver", func() {
 			Expect(mockSubscriber.SubscribeInput.Req).To(Receive(Equal(expectedReq)))
 		})
 
+		It("converts the selectors", func() {
+			req := &v2.SelectiveEgressRequest{
+				Request: &v2.EgressRequest{
+					Filter: &v2.Filter{SourceId: "some-source-id"},
+				},
+				Selectors: []*v2.Selector{
+					{Type: v2.Selector_COUNTER, Name: "some-counter"},
+					{Type: v2.Selector_LOG, Tags: map[string]string{"source_type": "APP"}},
+				},
+			}
+			expectedReq := &plumbing.SubscriptionRequest{
+				Filter: &plumbing.Filter{
+					AppID: "some-source-id",
+					Selectors: []*plumbing.Selector{
+						{Type: plumbing.Selector_COUNTER, Name: "some-counter"},
+						{Type: plumbing.Selector_LOG, Tags: map[string]string{"source_type": "APP"}},
+					},
+				},
+			}
+			rx.Subscribe(context.Background(), req)
+
+			Expect(mockSubscriber.SubscribeInput.Req).To(Receive(Equal(expectedReq)))
+		})
+
 		It("converts the data", func() {
 			close(mockConverter.ConvertOutput.Envelope)
 			close(mockConverter.ConvertOutput.Err)
-			req := &v2.EgressRequest{}
+			req := &v2.SelectiveEgressRequest{}
 			rx, err := rx.Subscribe(context.Background(), req)
 			Expect(err).ToNot(HaveOccurred())
 			rx()
@@ -65,7 +91,7 @@ var _ = Describe("Receiver", func() {
 		It("returns an error if the convert fails", func() {
 			close(mockConverter.ConvertOutput.Envelope)
 			mockConverter.ConvertOutput.Err <- fmt.Errorf("some-error")
-			req := &v2.EgressRequest{}
+			req := &v2.SelectiveEgressRequest{}
 			rx, err := rx.Subscribe(context.Background(), req)
 			Expect(err).ToNot(HaveOccurred())
 			_, err = rx()
@@ -80,7 +106,7 @@ var _ = Describe("Receiver", func() {
 			mockConverter.ConvertOutput.Envelope <- expectedEnv
 			close(mockConverter.ConvertOutput.Err)
 
-			req := &v2.EgressRequest{}
+			req := &v2.SelectiveEgressRequest{}
 			rx, err := rx.Subscribe(context.Background(), req)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -101,7 +127,7 @@ var _ = Describe("Receiver", func() {
 		})
 
 		It("returns an error via the receiver", func() {
-			req := &v2.EgressRequest{}
+			req := &v2.SelectiveEgressRequest{}
 			rx, err := rx.Subscribe(context.Background(), req)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -117,7 +143,7 @@ var _ = Describe("Receiver", func() {
 		})
 
 		It("returns an error", func() {
-			req := &v2.EgressRequest{}
+			req := &v2.SelectiveEgressRequest{}
 			_, err := rx.Subscribe(context.Background(), req)
 			Expect(err).To(HaveOccurred())
 		})
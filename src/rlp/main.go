@@ -3,48 +3,86 @@ package main
 import (
 	"flag"
 	"log"
-	"strings"
+	"time"
 
 	"google.golang.org/grpc"
 
+	"metric"
 	"plumbing"
 	"profiler"
 	"rlp/app"
+	"rlp/config"
+	"signalmanager"
 )
 
 func main() {
-	egressPort := flag.Int("egress-port", 0, "The port of the Egress server")
-	ingressAddrsList := flag.String("ingress-addrs", "", "The addresses of Dopplers")
-	pprofPort := flag.Int("pprof-port", 6061, "The port of pprof for health checks")
-
-	caFile := flag.String("ca", "", "The file path for the CA cert")
-	certFile := flag.String("cert", "", "The file path for the client cert")
-	keyFile := flag.String("key", "", "The file path for the client key")
+	configFile := flag.String("config", "config/reverse_log_proxy.json", "Location of the reverse log proxy config json file")
 
 	flag.Parse()
 
+	conf, err := config.ParseConfig(*configFile)
+	if err != nil {
+		log.Fatalf("Unable to parse config: %s", err)
+	}
+
 	tlsCredentials, err := plumbing.NewCredentials(
-		*certFile,
-		*keyFile,
-		*caFile,
+		conf.GRPC.CertFile,
+		conf.GRPC.KeyFile,
+		conf.GRPC.CAFile,
 		"doppler",
 	)
 	if err != nil {
 		log.Fatalf("Could not use TLS config: %s", err)
 	}
 
-	hostPorts := strings.Split(*ingressAddrsList, ",")
-	if len(hostPorts) == 0 {
-		log.Fatal("no Ingress Addrs were provided")
+	metronCredentials, err := plumbing.NewCredentials(
+		conf.GRPC.CertFile,
+		conf.GRPC.KeyFile,
+		conf.GRPC.CAFile,
+		"metron",
+	)
+	if err != nil {
+		log.Fatalf("Could not use TLS config: %s", err)
 	}
 
-	rlp := app.NewRLP(
-		app.WithEgressPort(*egressPort),
-		app.WithIngressAddrs(hostPorts),
+	metric.Setup(
+		metric.WithGrpcDialOpts(grpc.WithTransportCredentials(metronCredentials)),
+		metric.WithBatchInterval(time.Duration(conf.MetricBatchIntervalMilliseconds)*time.Millisecond),
+		metric.WithOrigin("loggregator.rlp"),
+		metric.WithAddr(conf.MetronAddr),
+		metric.WithDeploymentMeta(conf.DeploymentName, conf.JobName, conf.Index),
+	)
+
+	opts := []app.RLPOption{
+		app.WithEgressPort(int(conf.GRPC.Port)),
+		app.WithIngressAddrs(conf.DopplerAddrs),
+		app.WithIngressResolveInterval(time.Duration(conf.DopplerResolveIntervalSeconds) * time.Second),
 		app.WithIngressDialOptions(grpc.WithTransportCredentials(tlsCredentials)),
 		app.WithEgressServerOptions(grpc.Creds(tlsCredentials)),
-	)
+		app.WithEgressPermissions(conf.EgressPermissions),
+		app.WithStopTimeout(time.Duration(conf.StopTimeoutSeconds) * time.Second),
+		app.WithMetricBatchInterval(time.Duration(conf.MetricBatchIntervalMilliseconds) * time.Millisecond),
+	}
+	if conf.EgressAllowAll {
+		opts = append(opts, app.WithEgressAllowAll())
+	}
+
+	rlp := app.NewRLP(opts...)
 	go rlp.Start()
 
-	profiler.New(uint32(*pprofPort)).Start()
+	killChan := signalmanager.RegisterKillSignalChannel()
+	dumpChan := signalmanager.RegisterGoRoutineDumpSignalChannel()
+
+	go profiler.New(conf.PPROFPort).Start()
+
+	for {
+		select {
+		case <-dumpChan:
+			signalmanager.DumpGoRoutine()
+		case <-killChan:
+			log.Print("Shutting down")
+			rlp.Stop()
+			return
+		}
+	}
 }
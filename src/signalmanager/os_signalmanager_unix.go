@@ -23,6 +23,13 @@ func RegisterGoRoutineDumpSignalChannel() chan os.Signal {
 	return threadDumpChan
 }
 
+func RegisterReloadSignalChannel() chan os.Signal {
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+
+	return reloadChan
+}
+
 func DumpGoRoutine() {
 	goRoutineProfiles := pprof.Lookup("goroutine")
 	goRoutineProfiles.WriteTo(os.Stdout, 2)
@@ -19,6 +19,11 @@ func RegisterGoRoutineDumpSignalChannel() chan os.Signal {
 	return threadDumpChan
 }
 
+func RegisterReloadSignalChannel() chan os.Signal {
+	reloadChan := make(chan os.Signal, 1)
+	return reloadChan
+}
+
 func DumpGoRoutine() {
 	// no op
 }
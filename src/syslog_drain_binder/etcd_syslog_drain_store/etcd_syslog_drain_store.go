@@ -2,6 +2,7 @@ package etcd_syslog_drain_store
 
 import (
 	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -48,6 +49,13 @@ func (store *EtcdSyslogDrainStore) updateAppDrains(appId shared_types.AppID, dra
 
 		log.Printf("UpdateDrains: adding drain %s to app %s", drainURL, appId)
 		drainData := fmt.Sprintf(`{"hostname":"%s","drainURL":"%s"}`, drainBinding.Hostname, drainURL)
+		if drainTLS, ok := drainBinding.TLS[drainURL]; ok {
+			tlsData, err := json.Marshal(drainTLS)
+			if err != nil {
+				return err
+			}
+			drainData = fmt.Sprintf(`{"hostname":"%s","drainURL":"%s","tls":%s}`, drainBinding.Hostname, drainURL, tlsData)
+		}
 		node := storeadapter.StoreNode{
 			Key:   drainKey(appId, drainData),
 			Value: []byte(drainData),
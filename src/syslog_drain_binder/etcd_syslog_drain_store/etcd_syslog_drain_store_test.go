@@ -44,6 +44,30 @@ var _ = Describe("EtcdSyslogDrainStore", func() {
 			Expect(node.Value).To(MatchJSON(drainData))
 		})
 
+		It("writes the TLS configuration of a drain", func() {
+			appDrainUrlMap := shared_types.AllSyslogDrainBindings{
+				"app-id": shared_types.SyslogDrainBinding{
+					DrainURLs: []string{"url1", "url2"},
+					Hostname:  "org.space.app.1",
+					TLS: map[string]shared_types.DrainTLS{
+						"url1": {Cert: "some-cert", Key: "some-key", CA: "some-ca"},
+					},
+				},
+			}
+
+			err := syslogDrainStore.UpdateDrains(appDrainUrlMap)
+			Expect(err).ToNot(HaveOccurred())
+			drainData := `{"hostname":"org.space.app.1","drainURL":"url1","tls":{"cert":"some-cert","key":"some-key","ca":"some-ca"}}`
+			node, err := fakeStoreAdapter.Get(drainKey("app-id", drainData))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(node.Value).To(MatchJSON(drainData))
+
+			drainData = `{"hostname":"org.space.app.1","drainURL":"url2"}`
+			node, err = fakeStoreAdapter.Get(drainKey("app-id", drainData))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(node.Value).To(MatchJSON(drainData))
+		})
+
 		It("sets TTL on the app node if there are drain changes", func() {
 			appDrainUrlMap := shared_types.AllSyslogDrainBindings{
 				"app-id": shared_types.SyslogDrainBinding{DrainURLs: []string{"url1"}, Hostname: "org.space.app.1"},
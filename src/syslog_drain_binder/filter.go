@@ -9,6 +9,7 @@ func Filter(bindings shared_types.AllSyslogDrainBindings) shared_types.AllSyslog
 	newBindings := make(shared_types.AllSyslogDrainBindings)
 	for appId, b := range bindings {
 		drainUrls := []string{}
+		var drainTLS map[string]shared_types.DrainTLS
 		for _, d := range b.DrainURLs {
 			url, err := url.Parse(d)
 			if err != nil {
@@ -16,12 +17,20 @@ func Filter(bindings shared_types.AllSyslogDrainBindings) shared_types.AllSyslog
 			}
 			if url.Query().Get("drain-version") != "2.0" {
 				drainUrls = append(drainUrls, d)
+
+				if t, ok := b.TLS[d]; ok {
+					if drainTLS == nil {
+						drainTLS = make(map[string]shared_types.DrainTLS)
+					}
+					drainTLS[d] = t
+				}
 			}
 		}
 		if len(drainUrls) > 0 {
 			binding := shared_types.SyslogDrainBinding{
 				Hostname:  b.Hostname,
 				DrainURLs: drainUrls,
+				TLS:       drainTLS,
 			}
 			newBindings[appId] = binding
 		}
@@ -45,6 +45,32 @@ var _ = Describe("filter", func() {
 		Expect(syslog_drain_binder.Filter(input)).To(Equal(expected))
 	})
 
+	It("keeps the TLS configuration of the remaining drains", func() {
+		input := shared_types.AllSyslogDrainBindings{
+			"app1": shared_types.SyslogDrainBinding{
+				Hostname: "org.space.app1",
+				DrainURLs: []string{
+					"https://example.net?drain-version=2.0",
+					"https://example.com",
+				},
+				TLS: map[string]shared_types.DrainTLS{
+					"https://example.net?drain-version=2.0": {CA: "some-other-ca"},
+					"https://example.com":                   {Cert: "some-cert", Key: "some-key", CA: "some-ca"},
+				},
+			},
+		}
+		expected := shared_types.AllSyslogDrainBindings{
+			"app1": shared_types.SyslogDrainBinding{
+				Hostname:  "org.space.app1",
+				DrainURLs: []string{"https://example.com"},
+				TLS: map[string]shared_types.DrainTLS{
+					"https://example.com": {Cert: "some-cert", Key: "some-key", CA: "some-ca"},
+				},
+			},
+		}
+		Expect(syslog_drain_binder.Filter(input)).To(Equal(expected))
+	})
+
 	It("ignores malformed syslog drains", func() {
 		app1 := shared_types.SyslogDrainBinding{
 			Hostname:  "org.space.app1",
@@ -6,9 +6,19 @@ type AppID string
 // DrainURL represents a drain URL.
 type DrainURL string
 
+// DrainTLS holds the PEM encoded client certificate and key used to
+// authenticate with a drain and the CA bundle used to verify it.
+type DrainTLS struct {
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+	CA   string `json:"ca,omitempty"`
+}
+
 type SyslogDrainBinding struct {
 	Hostname  string   `json:"hostname"`
 	DrainURLs []string `json:"drains"`
+	// TLS holds the TLS configuration of drains keyed by drain URL.
+	TLS map[string]DrainTLS `json:"tls,omitempty"`
 }
 
 type AllSyslogDrainBindings map[AppID]SyslogDrainBinding
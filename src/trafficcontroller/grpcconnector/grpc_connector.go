@@ -51,10 +51,22 @@ type GRPCConnector struct {
 	consumerStates []unsafe.Pointer
 	bufferSize     int
 	batcher        MetaMetricBatcher
+	tagDopplers    bool
+}
+
+// ConnectorOption configures a GRPCConnector.
+type ConnectorOption func(*GRPCConnector)
+
+// WithDopplerTag tags the count of received envelopes with the doppler they
+// were received from.
+func WithDopplerTag() ConnectorOption {
+	return func(c *GRPCConnector) {
+		c.tagDopplers = true
+	}
 }
 
 // New creates a new GRPCConnector.
-func New(bufferSize int, pool DopplerPool, f Finder, batcher MetaMetricBatcher) *GRPCConnector {
+func New(bufferSize int, pool DopplerPool, f Finder, batcher MetaMetricBatcher, opts ...ConnectorOption) *GRPCConnector {
 	c := &GRPCConnector{
 		bufferSize:     bufferSize,
 		pool:           pool,
@@ -62,6 +74,9 @@ func New(bufferSize int, pool DopplerPool, f Finder, batcher MetaMetricBatcher)
 		batcher:        batcher,
 		consumerStates: make([]unsafe.Pointer, maxConnections),
 	}
+	for _, o := range opts {
+		o(c)
+	}
 	go c.readFinder()
 	return c
 }
@@ -260,7 +275,12 @@ func (c *GRPCConnector) consumeSubscription(cs *consumerState, dopplerClient *do
 
 		delay = time.Millisecond
 
-		if err := readStream(dopplerStream, cs, batcher); err != nil {
+		var doppler string
+		if c.tagDopplers {
+			doppler = dopplerClient.uri
+		}
+
+		if err := readStream(dopplerStream, doppler, cs, batcher); err != nil {
 			log.Printf("Error while reading from stream (%s): %s", dopplerClient.uri, err)
 			continue
 		}
@@ -271,7 +291,9 @@ type plumbingReceiver interface {
 	Recv() (*plumbing.Response, error)
 }
 
-func readStream(s plumbingReceiver, cs *consumerState, batcher MetaMetricBatcher) error {
+// readStream reads envelopes from the stream. The count of received
+// envelopes is tagged with the doppler when one is given.
+func readStream(s plumbingReceiver, doppler string, cs *consumerState, batcher MetaMetricBatcher) error {
 	timer := time.NewTimer(time.Second)
 	timer.Stop()
 	for {
@@ -280,9 +302,12 @@ func readStream(s plumbingReceiver, cs *consumerState, batcher MetaMetricBatcher
 			return err
 		}
 
-		batcher.BatchCounter("listeners.receivedEnvelopes").
-			SetTag("protocol", "grpc").
-			Increment()
+		counter := batcher.BatchCounter("listeners.receivedEnvelopes").
+			SetTag("protocol", "grpc")
+		if doppler != "" {
+			counter = counter.SetTag("doppler", doppler)
+		}
+		counter.Increment()
 
 		timer.Reset(time.Second)
 		select {
@@ -134,6 +134,20 @@ var _ = Describe("GRPCConnector", func() {
 					Eventually(mockChainer.IncrementCalled).Should(BeCalled())
 				})
 
+				It("does not tag the batch count with the doppler", func() {
+					senderA := captureSubscribeSender(mockDopplerServerA)
+
+					senderA.Send(&plumbing.Response{
+						Payload: []byte("some-data-a"),
+					})
+
+					Eventually(mockChainer.IncrementCalled).Should(BeCalled())
+					Expect(mockChainer.SetTagInput).To(BeCalled(
+						With("protocol", "grpc"),
+					))
+					Expect(mockChainer.SetTagInput.Key).ToNot(Receive())
+				})
+
 				It("does not close the doppler connection when a client exits", func() {
 					Eventually(mockDopplerServerA.SubscribeInput.Stream).Should(Receive())
 					cancelCtx()
@@ -490,6 +504,44 @@ var _ = Describe("GRPCConnector", func() {
 	})
 })
 
+var _ = Describe("GRPCConnector WithDopplerTag", func() {
+	It("tags the batch count with the doppler", func() {
+		mockDopplerServer := newMockDopplerServer()
+		mockFinder := newMockFinder()
+		mockBatcher := newMockMetaMetricBatcher()
+		mockChainer := newMockBatchCounterChainer()
+		testhelpers.AlwaysReturn(mockBatcher.BatchCounterOutput, mockChainer)
+		testhelpers.AlwaysReturn(mockChainer.SetTagOutput, mockChainer)
+
+		lis, server := startGRPCServer(mockDopplerServer, ":0")
+		defer lis.Close()
+		defer server.Stop()
+
+		pool := grpcconnector.NewPool(2, grpc.WithInsecure())
+		connector := grpcconnector.New(5, pool, mockFinder, mockBatcher, grpcconnector.WithDopplerTag())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req := &plumbing.SubscriptionRequest{ShardID: "test-sub-id"}
+		_, _, ready := readFromSubscription(ctx, req, connector)
+		Eventually(ready).Should(BeClosed())
+		mockFinder.NextOutput.Ret0 <- dopplerservice.Event{
+			GRPCDopplers: createGrpcURIs([]net.Listener{lis}),
+		}
+
+		sender := captureSubscribeSender(mockDopplerServer)
+		sender.Send(&plumbing.Response{
+			Payload: []byte("some-data"),
+		})
+
+		Eventually(mockChainer.IncrementCalled).Should(BeCalled())
+		Expect(mockChainer.SetTagInput).To(BeCalled(
+			With("protocol", "grpc"),
+			With("doppler", lis.Addr().String()),
+		))
+	})
+})
+
 func readFromSubscription(ctx context.Context, req *plumbing.SubscriptionRequest, connector *grpcconnector.GRPCConnector) (<-chan []byte, <-chan error, chan struct{}) {
 	data := make(chan []byte, 100)
 	errs := make(chan error, 100)
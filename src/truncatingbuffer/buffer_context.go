@@ -57,6 +57,30 @@ func (l *LogAllowedContext) EventAllowed(event events.Envelope_EventType) bool {
 	return event == events.Envelope_LogMessage
 }
 
+type EventTypeAllowedContext struct {
+	DefaultContext
+	allowed map[events.Envelope_EventType]bool
+}
+
+func NewEventTypeAllowedContext(origin string, destination string, eventTypes ...events.Envelope_EventType) *EventTypeAllowedContext {
+	allowed := make(map[events.Envelope_EventType]bool)
+	for _, t := range eventTypes {
+		allowed[t] = true
+	}
+
+	return &EventTypeAllowedContext{
+		DefaultContext: DefaultContext{
+			destination: destination,
+			origin:      origin,
+		},
+		allowed: allowed,
+	}
+}
+
+func (e *EventTypeAllowedContext) EventAllowed(event events.Envelope_EventType) bool {
+	return e.allowed[event]
+}
+
 type SystemContext struct {
 	DefaultContext
 }
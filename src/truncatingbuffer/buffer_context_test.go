@@ -59,6 +59,33 @@ var _ = Describe("BufferContext", func() {
 		})
 	})
 
+	Context("EventTypeAllowedContext", func() {
+		var eventTypeAllowedContext *EventTypeAllowedContext
+
+		BeforeEach(func() {
+			eventTypeAllowedContext = NewEventTypeAllowedContext(
+				"origin",
+				"testIdentifier",
+				events.Envelope_ValueMetric,
+				events.Envelope_ContainerMetric,
+			)
+		})
+
+		It("Should return a valid properties", func() {
+			Expect(eventTypeAllowedContext.Origin()).To(Equal("origin"))
+			Expect(eventTypeAllowedContext.Destination()).To(Equal("testIdentifier"))
+			for _, e := range events.Envelope_EventType_value {
+				event := events.Envelope_EventType(e)
+				allowed := eventTypeAllowedContext.EventAllowed(event)
+				if event == events.Envelope_ValueMetric || event == events.Envelope_ContainerMetric {
+					Expect(allowed).To(BeTrue())
+				} else {
+					Expect(allowed).To(BeFalse())
+				}
+			}
+		})
+	})
+
 	Context("SystemContext", func() {
 		var systemContext *SystemContext
 